@@ -0,0 +1,150 @@
+// Command payouts exchanges payout files with the bank:
+//
+//	payouts export [-out file] [-date YYYY-MM-DD] [-file id]
+//	payouts status report.xml
+//
+// export writes the pending payouts as a pain.001.001.09 file, or with
+// -file writes an earlier export again. status applies a pain.002 status
+// report, confirming accepted payouts and refunding rejected ones. Both
+// print a JSON summary and exit 1 on failure.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"os"
+	"os/signal"
+	"project/internal/config"
+	"project/internal/money"
+	"project/internal/payout"
+	"project/internal/service"
+	"project/internal/storage"
+	"project/internal/storage/postgres"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const usage = "usage: payouts export [-out file] [-date YYYY-MM-DD] [-file id] | payouts status report.xml"
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := config.Load()
+
+	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	txMngr := postgres.NewTxManager(pool)
+	pgRepo := postgres.NewPgRepository(txMngr)
+
+	wallets := service.NewWalletService(storage.NewStorageFacade(txMngr, pgRepo,
+		storage.WithMaxBalance(int64(cfg.MaxWalletBalance))))
+	wallets.Limits = money.Limits{
+		Withdraw:   money.Bounds{Min: int64(cfg.WithdrawMinAmount), Max: int64(cfg.WithdrawMaxAmount)},
+		MaxBalance: int64(cfg.MaxWalletBalance),
+	}
+	payouts := service.NewPayoutService(txMngr, pgRepo, wallets)
+
+	var result any
+	switch args[0] {
+	case "export":
+		result, err = export(ctx, cfg, payouts, args[1:])
+	case "status":
+		result, err = applyStatus(ctx, payouts, args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+	return 0
+}
+
+func export(ctx context.Context, cfg *config.Config, payouts *service.PayoutService, args []string) (payout.File, error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "", "file to write, named after the message ID by default")
+	date := flags.String("date", "", "requested execution date, today by default")
+	fileId := flags.String("file", "", "write the file of an earlier export again")
+	if err := flags.Parse(args); err != nil {
+		return payout.File{}, err
+	}
+
+	var (
+		file payout.File
+		err  error
+	)
+	if *fileId != "" {
+		id, err := uuid.Parse(*fileId)
+		if err != nil {
+			return payout.File{}, errors.New("invalid -file")
+		}
+		file, err = payouts.GetFile(ctx, id)
+		if err != nil {
+			return payout.File{}, err
+		}
+	} else {
+		execution := time.Now().UTC()
+		if *date != "" {
+			execution, err = time.Parse("2006-01-02", *date)
+			if err != nil {
+				return payout.File{}, errors.New("invalid -date")
+			}
+		}
+		debtor := payout.Debtor{
+			Name: cfg.PayoutDebtorName,
+			IBAN: payout.NormalizeIBAN(cfg.PayoutDebtorIBAN),
+			BIC:  strings.ToUpper(cfg.PayoutDebtorBIC),
+		}
+		file, err = payouts.Export(ctx, debtor, cfg.PayoutFileMaxPayouts, execution)
+		if err != nil {
+			return payout.File{}, err
+		}
+	}
+
+	path := *out
+	if path == "" {
+		path = file.MessageID + ".xml"
+	}
+	if err := os.WriteFile(path, file.Content, 0o600); err != nil {
+		return file, fmt.Errorf("write %s: %w; payouts are exported, write it again with -file %s", path, err, file.ID)
+	}
+	return file, nil
+}
+
+func applyStatus(ctx context.Context, payouts *service.PayoutService, args []string) (service.StatusSummary, error) {
+	if len(args) != 1 {
+		return service.StatusSummary{}, errors.New(usage)
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return service.StatusSummary{}, err
+	}
+	return payouts.ApplyStatusReport(ctx, content)
+}
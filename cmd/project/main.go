@@ -2,15 +2,24 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"project/internal/api"
 	"project/internal/config"
+	"project/internal/importer"
+	"project/internal/money"
+	"project/internal/outbox"
+	"project/internal/reconcile"
 	"project/internal/service"
 	"project/internal/storage"
 	"project/internal/storage/postgres"
+	"project/internal/stream"
+	"project/internal/webhook"
 	"syscall"
 	"time"
 )
@@ -35,9 +44,123 @@ func main() {
 	}
 	defer pool.Close()
 
-	WalletService := service.NewWalletService(InitStorage(pool))
+	txMngr := postgres.NewTxManager(pool).WithRetryPolicies(
+		postgres.RetryPolicy{
+			MaxAttempts:    cfg.TxSerializableMaxAttempts,
+			BaseDelay:      time.Duration(cfg.TxSerializableBaseDelayMs) * time.Millisecond,
+			MaxDelay:       time.Duration(cfg.TxSerializableMaxDelayMs) * time.Millisecond,
+			Jitter:         postgres.Jitter(cfg.TxRetryJitter),
+			RetryableCodes: cfg.TxRetryableSQLStates,
+		},
+		postgres.RetryPolicy{
+			MaxAttempts:    cfg.TxReadOnlyMaxAttempts,
+			BaseDelay:      time.Duration(cfg.TxReadOnlyBaseDelayMs) * time.Millisecond,
+			MaxDelay:       time.Duration(cfg.TxReadOnlyMaxDelayMs) * time.Millisecond,
+			Jitter:         postgres.Jitter(cfg.TxRetryJitter),
+			RetryableCodes: cfg.TxRetryableSQLStates,
+		})
+	txMngr.WithTimeouts(postgres.Timeouts{
+		Lock:              time.Duration(cfg.TxLockTimeoutMs) * time.Millisecond,
+		Statement:         time.Duration(cfg.TxStatementTimeoutMs) * time.Millisecond,
+		IdleInTransaction: time.Duration(cfg.TxIdleInTransactionTimeoutMs) * time.Millisecond,
+	})
+	if cfg.PostgresReplicaURL != "" {
+		replica, err := pgxpool.Connect(ctx, cfg.PostgresReplicaURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer replica.Close()
+		txMngr.WithReplica(replica, time.Duration(cfg.ReplicaMaxWaitMs)*time.Millisecond)
+	}
+	pgRepo := postgres.NewPgRepository(txMngr)
+
+	WalletService := service.NewWalletService(storage.NewStorageFacade(txMngr, pgRepo,
+		storage.WithGroupCommit(time.Duration(cfg.DepositGroupWindowUs)*time.Microsecond, cfg.DepositGroupMaxSize),
+		storage.WithShardCountCache(time.Duration(cfg.ShardCountCacheMs)*time.Millisecond),
+		storage.WithMaxBalance(int64(cfg.MaxWalletBalance))))
+	WalletService.Limits = money.Limits{
+		Deposit:    money.Bounds{Min: int64(cfg.DepositMinAmount), Max: int64(cfg.DepositMaxAmount)},
+		Withdraw:   money.Bounds{Min: int64(cfg.WithdrawMinAmount), Max: int64(cfg.WithdrawMaxAmount)},
+		MaxBalance: int64(cfg.MaxWalletBalance),
+	}
+	WalletService.SavingsRateBps = int64(cfg.SavingsInterestRateBps)
+	if cfg.FeeScheduleFile != "" {
+		WalletService.Fees, err = service.LoadFeeSchedule(cfg.FeeScheduleFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	publisher, err := InitPublisher(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	publisher = outbox.MultiPublisher{publisher, webhook.NewFanout(pgRepo)}
+	relay := outbox.NewRelay(txMngr, pgRepo, publisher,
+		time.Duration(cfg.OutboxPollIntervalMs)*time.Millisecond, cfg.OutboxBatchSize)
+	go relay.Run(ctx)
+
+	dispatcher := webhook.NewDispatcher(txMngr, pgRepo,
+		&http.Client{Timeout: time.Duration(cfg.WebhookTimeoutMs) * time.Millisecond},
+		time.Duration(cfg.WebhookPollIntervalMs)*time.Millisecond, cfg.OutboxBatchSize, cfg.WebhookMaxAttempts)
+	go dispatcher.Run(ctx)
+
+	broker := stream.NewBroker()
+	go stream.Listen(ctx, pool, broker)
+
+	compactor := storage.NewShardCompactor(txMngr, pgRepo,
+		time.Duration(cfg.ShardCompactIntervalMs)*time.Millisecond, cfg.OutboxBatchSize)
+	go compactor.Run(ctx)
 
-	router := api.SetupRouter(WalletService)
+	checkpointer := storage.NewBalanceCheckpointer(txMngr, pgRepo,
+		time.Duration(cfg.BalanceCheckpointIntervalMs)*time.Millisecond,
+		time.Duration(cfg.BalanceCheckpointLagMs)*time.Millisecond, cfg.OutboxBatchSize)
+	go checkpointer.Run(ctx)
+
+	snapshotter := storage.NewDailySnapshotter(txMngr, pgRepo,
+		time.Duration(cfg.DailySnapshotIntervalMs)*time.Millisecond,
+		time.Duration(cfg.BalanceCheckpointLagMs)*time.Millisecond, cfg.OutboxBatchSize)
+	go snapshotter.Run(ctx)
+
+	if cfg.InterestTreasuryWallet != "" {
+		treasury, err := uuid.Parse(cfg.InterestTreasuryWallet)
+		if err != nil {
+			log.Fatalf("invalid INTEREST_TREASURY_WALLET_ID: %v", err)
+		}
+		interestJob := storage.NewInterestJob(txMngr, pgRepo, treasury,
+			time.Duration(cfg.InterestIntervalMs)*time.Millisecond,
+			time.Duration(cfg.BalanceCheckpointLagMs)*time.Millisecond, cfg.OutboxBatchSize)
+		go interestJob.Run(ctx)
+	}
+
+	reconciler := reconcile.NewReconciler(txMngr, pgRepo,
+		time.Duration(cfg.ReconcileIntervalMs)*time.Millisecond, cfg.ReconcileMaxReported)
+	go reconciler.Run(postgres.WithTimeouts(ctx, postgres.Timeouts{
+		Statement: time.Duration(cfg.ReconcileStatementTimeoutMs) * time.Millisecond,
+	}))
+
+	importRunner := importer.NewRunner(txMngr, pgRepo, cfg.ImportChunkSize,
+		time.Duration(cfg.ImportPollIntervalMs)*time.Millisecond).
+		WithFees(WalletService.WithdrawFee).
+		WithLimits(WalletService.Limits)
+	go importRunner.Run(postgres.WithTimeouts(ctx, postgres.Timeouts{
+		Statement: time.Duration(cfg.ImportStatementTimeoutMs) * time.Millisecond,
+	}))
+
+	imports := service.NewImportService(pgRepo, importRunner)
+	imports.Limits = WalletService.Limits
+
+	router := api.SetupRouter(api.Services{
+		Wallet:   WalletService,
+		Webhooks: service.NewWebhookService(pgRepo),
+		Events:   broker,
+		Imports:  imports,
+		Payouts:  service.NewPayoutService(txMngr, pgRepo, WalletService),
+
+		BankStatements: service.NewBankStatementService(txMngr, pgRepo, WalletService),
+
+		AdminToken: cfg.AdminToken,
+	})
 
 	go func() {
 		err := router.Run(cfg.ApiAddress)
@@ -51,14 +174,23 @@ func main() {
 	ctxSvr, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := router.Stop(ctxSvr); err != nil {
-		log.Printf("Failed to stop server:", err)
+		log.Printf("Failed to stop server: %v", err)
 	}
 	time.Sleep(7 * time.Second)
 }
 
-func InitStorage(pool *pgxpool.Pool) storage.Facade {
-	txMngr := postgres.NewTxManager(pool)
-	pgRepo := postgres.NewPgRepository(txMngr)
-
-	return storage.NewStorageFacade(txMngr, pgRepo)
-}
\ No newline at end of file
+func InitPublisher(cfg *config.Config) (outbox.EventPublisher, error) {
+	switch cfg.OutboxPublisher {
+	case "stdout":
+		return outbox.NewStdoutPublisher(), nil
+	case "file":
+		return outbox.NewFilePublisher(cfg.OutboxFilePath)
+	case "webhook":
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for webhook publisher")
+		}
+		return outbox.NewWebhookPublisher(cfg.OutboxWebhookURL, 5*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
@@ -0,0 +1,54 @@
+// Command reconcile runs one ledger reconciliation, prints the report as
+// JSON and exits 1 if anything does not reconcile, 2 if the run failed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"os"
+	"os/signal"
+	"project/internal/config"
+	"project/internal/reconcile"
+	"project/internal/storage/postgres"
+	"syscall"
+	"time"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := config.Load()
+
+	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	txMngr := postgres.NewTxManager(pool)
+	txMngr.WithTimeouts(postgres.Timeouts{
+		Statement: time.Duration(cfg.ReconcileStatementTimeoutMs) * time.Millisecond,
+	})
+
+	reconciler := reconcile.NewReconciler(txMngr, postgres.NewPgRepository(txMngr), 0, cfg.ReconcileMaxReported)
+	report, err := reconciler.RunOnce(ctx)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	switch {
+	case err != nil:
+		return 2
+	case report.Status != reconcile.OK:
+		return 1
+	}
+	return 0
+}
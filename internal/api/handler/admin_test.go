@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/wallet"
+)
+
+func adminRouter(h *RestHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.With(RequireAdmin("secret")).Get("/api/v1/wallets", h.ListWallets)
+	return r
+}
+
+func adminReq(path string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	return req
+}
+
+func TestListWallets_RequiresAdmin(t *testing.T) {
+	r := adminRouter(newHandler(&fakeFacade{}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestListWallets_FiltersAndPages(t *testing.T) {
+	ff := &fakeFacade{wallets: []wallet.Wallet{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}}
+	r := adminRouter(newHandler(ff))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminReq("/api/v1/wallets?ownerId=cust-1&status=frozen&currency=eur&minBalance=5&createdFrom=2025-10-01T00:00:00Z&sort=-balance&limit=2"))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+	q := ff.lastQuery
+	require.Equal(t, "cust-1", q.Filter.OwnerID)
+	require.Equal(t, wallet.Frozen, q.Filter.Status)
+	require.Equal(t, "EUR", q.Filter.Currency)
+	require.Equal(t, int64(5), *q.Filter.MinBalance)
+	require.Nil(t, q.Filter.MaxBalance)
+	require.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), q.Filter.CreatedFrom)
+	require.Equal(t, wallet.SortBalance, q.Sort)
+	require.True(t, q.Desc)
+
+	var page struct {
+		Wallets    []wallet.Wallet `json:"wallets"`
+		NextCursor string          `json:"nextCursor"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Wallets, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminReq("/api/v1/wallets?limit=2&cursor="+page.NextCursor))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, ff.wallets[1].ID, ff.lastQuery.After.ID)
+
+	page.Wallets, page.NextCursor = nil, ""
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Wallets, 1)
+	require.Empty(t, page.NextCursor)
+}
+
+func TestListWallets_BadParams(t *testing.T) {
+	r := adminRouter(newHandler(&fakeFacade{}))
+
+	for _, path := range []string{
+		"/api/v1/wallets?minBalance=abc",
+		"/api/v1/wallets?createdBefore=yesterday",
+		"/api/v1/wallets?cursor=!!!",
+		"/api/v1/wallets?sort=owner",
+		"/api/v1/wallets?limit=5000",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, adminReq(path))
+		require.Equalf(t, http.StatusBadRequest, w.Code, "path=%s body=%s", path, w.Body.String())
+	}
+}
+
+func TestListWallets_CSVExportsAllPages(t *testing.T) {
+	ff := &fakeFacade{}
+	for i := 0; i < 1500; i++ {
+		ff.wallets = append(ff.wallets, wallet.Wallet{ID: uuid.New(), Status: wallet.Active, Currency: "EUR"})
+	}
+	ff.wallets[0].DisplayName = "=HYPERLINK(\"x\")"
+	r := adminRouter(newHandler(ff))
+
+	req := adminReq("/api/v1/wallets")
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 1501)
+	require.Equal(t, "walletId", records[0][0])
+	require.Equal(t, ff.wallets[0].ID.String(), records[1][0])
+	require.Equal(t, "'=HYPERLINK(\"x\")", records[1][2])
+}
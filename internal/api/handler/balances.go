@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type BalancesAtRequest struct {
+	WalletIDs []uuid.UUID `json:"walletIds"`
+	At        time.Time   `json:"at"`
+}
+
+func (h *RestHandler) GetBalanceAt(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, r.URL.Query().Get("at"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid at parameter")
+		return
+	}
+
+	balance, err := h.s.GetBalanceAt(ctx, walletId, at)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "wallet not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"walletId": walletId.String(), "balance": balance, "at": at})
+}
+
+func (h *RestHandler) GetBalancesAt(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	var req BalancesAtRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	balances, missing, err := h.s.GetBalancesAt(ctx, req.WalletIDs, req.At)
+	if err != nil {
+		status := http.StatusInternalServerError
+		msg := err.Error()
+		if strings.HasPrefix(msg, "at ") || strings.HasPrefix(msg, "walletIds") || strings.HasPrefix(msg, "too many") {
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, msg)
+		return
+	}
+	if missing == nil {
+		missing = []uuid.UUID{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"at": req.At, "balances": balances, "notFound": missing})
+}
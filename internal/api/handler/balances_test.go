@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/wallet"
+)
+
+func TestGetBalanceAt(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{getBal: 250}
+	r := chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}/balance", newHandler(ff).GetBalanceAt)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"/balance?at=2025-10-31T23:59:59%2B02:00", nil))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, id, ff.lastGetID)
+	require.True(t, ff.lastAt.Equal(time.Date(2025, 10, 31, 21, 59, 59, 0, time.UTC)))
+
+	var resp struct {
+		Balance int64 `json:"balance"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, int64(250), resp.Balance)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"/balance", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	ff.getErr = errAny("wallet not found")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"/balance?at=2025-10-31T23:59:59Z", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetBalancesAt(t *testing.T) {
+	a, missing := uuid.New(), uuid.New()
+	ff := &fakeFacade{wallets: []wallet.Wallet{{ID: a, Balance: 40}}}
+	h := newHandler(ff)
+
+	w := httptest.NewRecorder()
+	h.GetBalancesAt(w, doJSONReq(http.MethodPost, "/api/v1/wallets/balances", map[string]any{
+		"walletIds": []string{a.String(), missing.String()},
+		"at":        "2025-10-31T23:59:59Z",
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+	var resp struct {
+		Balances []struct {
+			WalletID uuid.UUID `json:"walletId"`
+			Balance  int64     `json:"balance"`
+		} `json:"balances"`
+		NotFound []uuid.UUID `json:"notFound"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Balances, 1)
+	require.Equal(t, int64(40), resp.Balances[0].Balance)
+	require.Equal(t, []uuid.UUID{missing}, resp.NotFound)
+
+	w = httptest.NewRecorder()
+	h.GetBalancesAt(w, doJSONReq(http.MethodPost, "/api/v1/wallets/balances", map[string]any{"walletIds": []string{a.String()}}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
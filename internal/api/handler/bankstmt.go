@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/bankstmt"
+	"project/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultStatementExceptions = 100
+	bankImportTimeout          = 2 * time.Minute
+)
+
+type BankStatementHandler struct {
+	s *service.BankStatementService
+}
+
+func NewBankStatementHandler(svc *service.BankStatementService) *BankStatementHandler {
+	return &BankStatementHandler{
+		s: svc,
+	}
+}
+
+// Import takes a CAMT.053 or MT940 file, either raw or as a multipart
+// upload. Without a format parameter the format is guessed from the content.
+func (h *BankStatementHandler) Import(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), bankImportTimeout)
+	defer cancel()
+
+	format := bankstmt.Format(r.URL.Query().Get("format"))
+	if format != "" && format != bankstmt.CAMT053 && format != bankstmt.MT940 {
+		respondError(w, http.StatusBadRequest, "format must be camt053 or mt940")
+		return
+	}
+
+	content, err := readUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.s.Import(ctx, format, content)
+	if err != nil {
+		msg := err.Error()
+		status := http.StatusInternalServerError
+		if msg == "unknown statement format" || strings.HasPrefix(msg, "invalid camt.053") ||
+			strings.HasPrefix(msg, "invalid mt940") {
+			status = http.StatusUnprocessableEntity
+		}
+		respondError(w, status, msg)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+func (h *BankStatementHandler) ListExceptions(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	var after int64
+	if v := r.URL.Query().Get("after"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid after parameter")
+			return
+		}
+		after = parsed
+	}
+
+	limit := defaultStatementExceptions
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid limit parameter")
+			return
+		}
+		limit = parsed
+	}
+
+	lines, err := h.s.ListExceptions(ctx, after, limit)
+	if err != nil {
+		respondError(w, resolveStatus(err), err.Error())
+		return
+	}
+	if lines == nil {
+		lines = []bankstmt.Line{}
+	}
+
+	resp := map[string]any{"lines": lines}
+	if len(lines) == limit {
+		resp["nextAfter"] = lines[len(lines)-1].ID
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+type resolveRequest struct {
+	Action   service.ResolveAction `json:"action"`
+	WalletID *uuid.UUID            `json:"walletId"`
+	Note     string                `json:"note"`
+}
+
+func (h *BankStatementHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	lineId, err := strconv.ParseInt(chi.URLParam(r, "lineId"), 10, 64)
+	if err != nil || lineId <= 0 {
+		respondError(w, http.StatusBadRequest, "invalid lineId parameter")
+		return
+	}
+
+	var req resolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.WalletID != nil && *req.WalletID == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId")
+		return
+	}
+
+	line, err := h.s.Resolve(ctx, lineId, req.Action, req.WalletID, req.Note)
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		respondError(w, resolveStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, line)
+}
+
+func resolveStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "statement line not found", msg == "wallet not found":
+		return http.StatusNotFound
+	case msg == "statement line already resolved":
+		return http.StatusConflict
+	case msg == "action must be credit or ignore", msg == "only credit lines can be credited",
+		msg == "walletId is required", msg == "invalid after parameter",
+		strings.HasPrefix(msg, "note "), strings.HasPrefix(msg, "limit "),
+		strings.HasPrefix(msg, "wallet is "), strings.HasPrefix(msg, "currency mismatch"):
+		return http.StatusBadRequest
+	}
+	return transferStatus(err)
+}
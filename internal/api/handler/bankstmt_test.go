@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/bankstmt"
+	"project/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type passTx struct{}
+
+func (passTx) RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeBankStore struct {
+	bankstmt.Store
+
+	line bankstmt.Line
+}
+
+func (f *fakeBankStore) GetStatementLineForUpdate(ctx context.Context, id int64) (bankstmt.Line, error) {
+	if id != f.line.ID {
+		return bankstmt.Line{}, errAny("statement line not found")
+	}
+	return f.line, nil
+}
+
+func (f *fakeBankStore) UpdateStatementLine(ctx context.Context, id int64, status bankstmt.LineStatus, walletId *uuid.UUID, reason, note string) error {
+	f.line.Status = status
+	return nil
+}
+
+func newBankRouter(store *fakeBankStore) *chi.Mux {
+	h := NewBankStatementHandler(service.NewBankStatementService(passTx{}, store, service.NewWalletService(&fakeFacade{})))
+	r := chi.NewRouter()
+	r.Post("/api/v1/bank-statements", h.Import)
+	r.Post("/api/v1/bank-statements/exceptions/{lineId}/resolve", h.Resolve)
+	return r
+}
+
+func TestBankStatementImport_Rejected(t *testing.T) {
+	r := newBankRouter(&fakeBankStore{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/bank-statements?format=bai2", strings.NewReader("x")))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/bank-statements?format=camt053", strings.NewReader("<Document/>")))
+	require.Equalf(t, http.StatusUnprocessableEntity, w.Code, "body=%s", w.Body.String())
+}
+
+func TestBankStatementResolve(t *testing.T) {
+	store := &fakeBankStore{line: bankstmt.Line{ID: 7, Status: bankstmt.Exception, Amount: 100, Credit: false}}
+	r := newBankRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/bank-statements/exceptions/8/resolve", map[string]any{"action": "ignore"}))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/bank-statements/exceptions/7/resolve", map[string]any{
+		"action": "credit", "walletId": uuid.New().String(),
+	}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/bank-statements/exceptions/7/resolve", map[string]any{"action": "ignore"}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, bankstmt.Ignored, store.line.Status)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/bank-statements/exceptions/7/resolve", map[string]any{"action": "ignore"}))
+	require.Equal(t, http.StatusConflict, w.Code)
+}
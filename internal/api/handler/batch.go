@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type BatchRequest struct {
+	Mode       batch.Mode      `json:"mode"`
+	Operations []WalletRequest `json:"operations"`
+}
+
+func (h *RestHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, decodeError(err))
+		return
+	}
+
+	ops := make([]batch.Operation, len(req.Operations))
+	for i, op := range req.Operations {
+		walletId, err := uuid.Parse(op.WalletID)
+		if err != nil || walletId == uuid.Nil {
+			respondError(w, http.StatusBadRequest, "operations["+strconv.Itoa(i)+"]: invalid walletId parameter")
+			return
+		}
+		ops[i] = batch.Operation{
+			WalletID:      walletId,
+			OperationType: ledger.OperationType(op.OperationType),
+			Amount:        int64(op.Amount),
+		}
+	}
+
+	b, err := h.s.ProcessBatch(ctx, req.Mode, ops)
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		msg := err.Error()
+		if msg == "invalid mode parameter" || msg == "operations must not be empty" ||
+			strings.HasPrefix(msg, "too many operations") || strings.HasPrefix(msg, "operations[") {
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, msg)
+		return
+	}
+
+	// An atomic batch that failed was recorded, but none of its operations
+	// were applied.
+	if b.Mode == batch.Atomic && b.Status == batch.Failed {
+		respondJSON(w, http.StatusUnprocessableEntity, b)
+		return
+	}
+	respondJSON(w, http.StatusCreated, b)
+}
+
+func (h *RestHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	batchId, err := uuid.Parse(chi.URLParam(r, "batchId"))
+	if err != nil || batchId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid batchId parameter")
+		return
+	}
+
+	b, err := h.s.GetBatch(ctx, batchId)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "batch not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, b)
+}
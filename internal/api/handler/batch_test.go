@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/batch"
+	"project/internal/ledger"
+)
+
+func TestCreateBatch_Success(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ff := &fakeFacade{batch: batch.Batch{ID: uuid.New(), Mode: batch.Atomic, Status: batch.Completed}}
+	h := newHandler(ff)
+
+	req := doJSONReq(http.MethodPost, "/api/v1/batches", map[string]any{
+		"mode": "ATOMIC",
+		"operations": []map[string]any{
+			{"walletId": a.String(), "operationType": "DEPOSIT", "amount": 100},
+			{"walletId": b.String(), "operationType": "WITHDRAW", "amount": 40},
+		},
+	})
+	w := httptest.NewRecorder()
+	h.CreateBatch(w, req)
+
+	require.Equalf(t, http.StatusCreated, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, []batch.Operation{
+		{WalletID: a, OperationType: ledger.Deposit, Amount: 100},
+		{WalletID: b, OperationType: ledger.Withdraw, Amount: 40},
+	}, ff.lastBatch)
+}
+
+func TestCreateBatch_Validation(t *testing.T) {
+	h := newHandler(&fakeFacade{})
+
+	req := doJSONReq(http.MethodPost, "/api/v1/batches", map[string]any{
+		"mode":       "ATOMIC",
+		"operations": []map[string]any{{"walletId": "nope", "operationType": "DEPOSIT", "amount": 1}},
+	})
+	w := httptest.NewRecorder()
+	h.CreateBatch(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = doJSONReq(http.MethodPost, "/api/v1/batches", map[string]any{"mode": "ATOMIC", "operations": []any{}})
+	w = httptest.NewRecorder()
+	h.CreateBatch(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBatch(t *testing.T) {
+	ff := &fakeFacade{batchErr: errAny("batch not found")}
+	h := newHandler(ff)
+	r := chi.NewRouter()
+	r.Get("/api/v1/batches/{batchId}", h.GetBatch)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/batches/"+uuid.New().String(), nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/batches/xyz", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateBatch_AtomicFailure(t *testing.T) {
+	ff := &fakeFacade{batch: batch.Batch{ID: uuid.New(), Mode: batch.Atomic, Status: batch.Failed}}
+	h := newHandler(ff)
+
+	req := doJSONReq(http.MethodPost, "/api/v1/batches", map[string]any{
+		"mode": "ATOMIC",
+		"operations": []map[string]any{
+			{"walletId": uuid.New().String(), "operationType": "WITHDRAW", "amount": 40},
+		},
+	})
+	w := httptest.NewRecorder()
+	h.CreateBatch(w, req)
+
+	require.Equalf(t, http.StatusUnprocessableEntity, w.Code, "body=%s", w.Body.String())
+	require.Contains(t, w.Body.String(), `"status":"FAILED"`)
+}
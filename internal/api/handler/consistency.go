@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"project/internal/lsn"
+)
+
+// ReadYourWrites passes the client's X-Min-LSN to the storage layer and
+// returns the WAL position of the request's last write as X-LSN.
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if required := r.Header.Get(lsn.RequestHeader); required != "" {
+			if !lsn.Valid(required) {
+				respondError(w, http.StatusBadRequest, "invalid "+lsn.RequestHeader+" header")
+				return
+			}
+			ctx = lsn.WithRequired(ctx, required)
+		}
+
+		ctx, tracker := lsn.WithTracker(ctx)
+		next.ServeHTTP(&lsnWriter{ResponseWriter: w, tracker: tracker}, r.WithContext(ctx))
+	})
+}
+
+type lsnWriter struct {
+	http.ResponseWriter
+	tracker     *lsn.Tracker
+	wroteHeader bool
+}
+
+func (w *lsnWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if pos := w.tracker.Get(); pos != "" {
+			w.Header().Set(lsn.ResponseHeader, pos)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *lsnWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *lsnWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *lsnWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project/internal/lsn"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	var required string
+	h := ReadYourWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required = lsn.Required(r.Context())
+		lsn.TrackerFrom(r.Context()).Set("0/16B3748")
+		respondJSON(w, http.StatusOK, map[string]any{"status": "success"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(lsn.RequestHeader, "0/16B3700")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0/16B3700", required)
+	require.Equal(t, "0/16B3748", w.Header().Get(lsn.ResponseHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(lsn.RequestHeader, "latest")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
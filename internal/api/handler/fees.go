@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuoteFee takes the same body as TransferFunds and answers with the fee
+// the operation would be charged if it were sent now. Deposits are free.
+func (h *RestHandler) QuoteFee(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	var req WalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, decodeError(err))
+		return
+	}
+
+	walletId, err := uuid.Parse(req.WalletID)
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var quote service.FeeQuote
+	switch req.OperationType {
+	case Deposit:
+		quote = service.FeeQuote{Amount: int64(req.Amount), Total: int64(req.Amount)}
+	case Withdraw:
+		quote, err = h.s.QuoteWithdraw(ctx, walletId, int64(req.Amount))
+		if err != nil {
+			respondError(w, transferStatus(err), err.Error())
+			return
+		}
+	case Transfer:
+		if _, ok := parseToWallet(w, req); !ok {
+			return
+		}
+		quote, err = h.s.QuoteTransfer(ctx, walletId, int64(req.Amount))
+		if err != nil {
+			respondError(w, transferStatus(err), err.Error())
+			return
+		}
+	default:
+		respondError(w, http.StatusBadRequest, "invalid operationType parameter")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
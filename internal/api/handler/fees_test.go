@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/ledger"
+	"project/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newFeeHandler(ff *fakeFacade, revenue uuid.UUID) *RestHandler {
+	ws := service.NewWalletService(ff)
+	ws.Fees = service.FeeSchedule{
+		RevenueWallet: revenue,
+		Withdraw:      service.FeeRule{Flat: 5, RateBps: 200, Tiers: []service.FeeTier{{FromVolume: 1000, RateBps: 100}}},
+	}
+	return NewHandler(ws)
+}
+
+func TestQuoteFee(t *testing.T) {
+	ff := &fakeFacade{withdrawn: 5000}
+	h := newFeeHandler(ff, uuid.New())
+	id := uuid.New()
+
+	w := httptest.NewRecorder()
+	h.QuoteFee(w, doJSONReq(http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId": id.String(), "operationType": "WITHDRAW", "amount": 700,
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.JSONEq(t, `{"amount":700,"fee":12,"total":712,"rateBps":100,"monthlyVolume":5000}`, w.Body.String())
+	require.Equal(t, uuid.Nil, ff.lastWithdrawID)
+
+	w = httptest.NewRecorder()
+	h.QuoteFee(w, doJSONReq(http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId": id.String(), "operationType": "DEPOSIT", "amount": 700,
+	}))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"amount":700,"fee":0,"total":700,"rateBps":0,"monthlyVolume":0}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.QuoteFee(w, doJSONReq(http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId": id.String(), "operationType": "WITHDRAW", "amount": 0,
+	}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "amount must be positive")
+
+	w = httptest.NewRecorder()
+	h.QuoteFee(w, doJSONReq(http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId": id.String(), "operationType": "TRANSFER", "amount": 700,
+	}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTransferFunds_WithdrawReportsFee(t *testing.T) {
+	ff := &fakeFacade{}
+	revenue := uuid.New()
+	h := newFeeHandler(ff, revenue)
+
+	w := httptest.NewRecorder()
+	h.TransferFunds(w, doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": uuid.New().String(), "operationType": "WITHDRAW", "amount": 700,
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.JSONEq(t, `{"status":"success","fee":19}`, w.Body.String())
+	require.Equal(t, ledger.FeeCharge{Amount: 19, Revenue: revenue}, ff.lastFee)
+}
+
+func TestTransferFunds_TransferReportsFee(t *testing.T) {
+	ff := &fakeFacade{}
+	revenue, from, to := uuid.New(), uuid.New(), uuid.New()
+	ws := service.NewWalletService(ff)
+	ws.Fees = service.FeeSchedule{RevenueWallet: revenue, Transfer: service.FeeRule{Flat: 7}}
+	h := NewHandler(ws)
+
+	w := httptest.NewRecorder()
+	h.QuoteFee(w, doJSONReq(http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId": from.String(), "toWalletId": to.String(), "operationType": "TRANSFER", "amount": 700,
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.JSONEq(t, `{"amount":700,"fee":7,"total":707,"rateBps":0,"monthlyVolume":0}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.TransferFunds(w, doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": from.String(), "toWalletId": to.String(), "operationType": "TRANSFER", "amount": 700,
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.JSONEq(t, `{"status":"success","fee":7}`, w.Body.String())
+	require.Equal(t, from, ff.lastWithdrawID)
+	require.Equal(t, to, ff.lastTransferTo)
+	require.Equal(t, ledger.FeeCharge{Amount: 7, Revenue: revenue}, ff.lastFee)
+
+	w = httptest.NewRecorder()
+	h.TransferFunds(w, doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": from.String(), "toWalletId": from.String(), "operationType": "TRANSFER", "amount": 700,
+	}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "cannot transfer to the same wallet")
+
+	w = httptest.NewRecorder()
+	h.TransferFunds(w, doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": from.String(), "operationType": "TRANSFER", "amount": 700,
+	}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "invalid toWalletId parameter")
+}
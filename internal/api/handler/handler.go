@@ -2,8 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"project/internal/contention"
+	"project/internal/money"
 	"project/internal/service"
+	"strconv"
 )
 
 type RestHandler struct {
@@ -27,3 +32,29 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
+
+// respondBusy answers 409 with Retry-After when err is lock contention on a
+// wallet, and reports whether it did.
+func respondBusy(w http.ResponseWriter, err error) bool {
+	var busy *contention.BusyError
+	if !errors.As(err, &busy) {
+		return false
+	}
+	seconds := int(math.Ceil(busy.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respondError(w, http.StatusConflict, busy.Error())
+	return true
+}
+
+// decodeError keeps a rejected amount's reason, which is more useful to the
+// client than "invalid json".
+func decodeError(err error) string {
+	var amountErr *money.Error
+	if errors.As(err, &amountErr) {
+		return amountErr.Error()
+	}
+	return "invalid json"
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"project/internal/importer"
+	"project/internal/service"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const maxImportBytes = 32 << 20
+
+type ImportHandler struct {
+	s *service.ImportService
+}
+
+func NewImportHandler(svc *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		s: svc,
+	}
+}
+
+func (h *ImportHandler) Upload(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	content, err := readUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, created, err := h.s.Submit(ctx, content)
+	if err != nil {
+		var validationErr *importer.ValidationError
+		if errors.As(err, &validationErr) {
+			respondJSON(w, http.StatusUnprocessableEntity, map[string]any{
+				"error": err.Error(),
+				"rows":  validationErr.Errors,
+			})
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := http.StatusAccepted
+	if !created {
+		status = http.StatusOK
+	}
+	w.Header().Set("Location", "/api/v1/imports/"+job.ID.String())
+	respondJSON(w, status, job)
+}
+
+func (h *ImportHandler) Get(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	jobId, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil || jobId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid jobId parameter")
+		return
+	}
+
+	job, err := h.s.Get(ctx, jobId)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "import job not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// readUpload accepts either a raw text/csv body or a multipart form with the
+// file in the "file" field.
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("missing file field")
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.New("failed to read body")
+	}
+	if len(content) == 0 {
+		return nil, errors.New("empty body")
+	}
+	return content, nil
+}
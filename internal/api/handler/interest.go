@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type InterestRateRequest struct {
+	InterestRateBps int64 `json:"interestRateBps"`
+}
+
+func (h *RestHandler) SetInterestRate(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var req InterestRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	if err := h.s.SetInterestRate(ctx, walletId, req.InterestRateBps); err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case err.Error() == "wallet not found":
+			status = http.StatusNotFound
+		case err.Error() == "wallet is not a savings wallet":
+			status = http.StatusConflict
+		case strings.HasPrefix(err.Error(), "interestRateBps must be"):
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"walletId": walletId.String(), "interestRateBps": req.InterestRateBps})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestSetInterestRate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body map[string]any
+		err  error
+		want int
+	}{
+		"ok":           {map[string]any{"interestRateBps": 350}, nil, http.StatusOK},
+		"out of range": {map[string]any{"interestRateBps": 10001}, nil, http.StatusBadRequest},
+		"not found":    {map[string]any{"interestRateBps": 350}, errors.New("wallet not found"), http.StatusNotFound},
+		"not savings":  {map[string]any{"interestRateBps": 350}, errors.New("wallet is not a savings wallet"), http.StatusConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ff := &fakeFacade{rateErr: tc.err}
+			r := chi.NewRouter()
+			r.Put("/api/v1/wallets/{walletId}/interest-rate", newHandler(ff).SetInterestRate)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, doJSONReq(http.MethodPut, "/api/v1/wallets/"+uuid.NewString()+"/interest-rate", tc.body))
+			require.Equalf(t, tc.want, w.Code, "body=%s", w.Body.String())
+			if tc.want == http.StatusOK {
+				require.Equal(t, int64(350), ff.lastRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/money"
+	"project/internal/payout"
+	"project/internal/service"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type PayoutHandler struct {
+	s *service.PayoutService
+}
+
+func NewPayoutHandler(svc *service.PayoutService) *PayoutHandler {
+	return &PayoutHandler{
+		s: svc,
+	}
+}
+
+type PayoutRequest struct {
+	Amount      money.Amount       `json:"amount"`
+	Beneficiary payout.Beneficiary `json:"beneficiary"`
+	Reference   string             `json:"reference"`
+}
+
+func (h *PayoutHandler) Request(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var req PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, decodeError(err))
+		return
+	}
+
+	p, err := h.s.Request(ctx, walletId, int64(req.Amount), req.Beneficiary, req.Reference)
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		respondError(w, payoutStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/payouts/"+p.ID.String())
+	respondJSON(w, http.StatusCreated, p)
+}
+
+func (h *PayoutHandler) Get(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	payoutId, err := uuid.Parse(chi.URLParam(r, "payoutId"))
+	if err != nil || payoutId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid payoutId parameter")
+		return
+	}
+
+	p, err := h.s.Get(ctx, payoutId)
+	if err != nil {
+		respondError(w, payoutStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+func payoutStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "payout not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "beneficiary "), strings.HasPrefix(msg, "reference "),
+		strings.HasPrefix(msg, "wallet is "):
+		return http.StatusBadRequest
+	}
+	return transferStatus(err)
+}
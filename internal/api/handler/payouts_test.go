@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/payout"
+	"project/internal/service"
+	"project/internal/wallet"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type fakePayoutStore struct {
+	payout.Store
+
+	inserted []payout.Payout
+}
+
+func (f *fakePayoutStore) InsertPayout(ctx context.Context, p payout.Payout) error {
+	f.inserted = append(f.inserted, p)
+	return nil
+}
+
+func (f *fakePayoutStore) GetPayout(ctx context.Context, id uuid.UUID) (payout.Payout, error) {
+	return payout.Payout{}, errAny("payout not found")
+}
+
+func TestRequestPayout(t *testing.T) {
+	walletId := uuid.New()
+	f := &fakeFacade{wallets: []wallet.Wallet{{ID: walletId, Status: wallet.Active, Currency: "EUR"}}}
+	store := &fakePayoutStore{}
+	h := NewPayoutHandler(service.NewPayoutService(passTx{}, store, service.NewWalletService(f)))
+	r := chi.NewRouter()
+	r.Post("/api/v1/wallets/{walletId}/payouts", h.Request)
+	r.Get("/api/v1/payouts/{payoutId}", h.Get)
+
+	body := map[string]any{
+		"amount":      250,
+		"beneficiary": map[string]any{"name": "Jane", "iban": "DE89370400440532013000"},
+		"reference":   "March",
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/"+walletId.String()+"/payouts", body))
+	require.Equalf(t, http.StatusCreated, w.Code, "body=%s", w.Body.String())
+
+	var resp payout.Payout
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, payout.Pending, resp.Status)
+	require.Equal(t, "/api/v1/payouts/"+resp.ID.String(), w.Header().Get("Location"))
+	require.Equal(t, int64(250), f.lastAmount)
+	require.Len(t, store.inserted, 1)
+
+	body["beneficiary"] = map[string]any{"name": "Jane", "iban": "DE00"}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/"+walletId.String()+"/payouts", body))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/"+uuid.NewString()+"/payouts", map[string]any{
+		"amount": 1, "beneficiary": map[string]any{"name": "Jane", "iban": "DE89370400440532013000"},
+	}))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/payouts/"+uuid.NewString(), nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
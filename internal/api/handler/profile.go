@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"project/internal/wallet"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const defaultOwnerWallets = 100
+
+type UpdateProfileRequest struct {
+	DisplayName *string         `json:"displayName"`
+	ExternalRef *string         `json:"externalRef"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+func (h *RestHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	updated, err := h.s.UpdateWalletProfile(ctx, walletId, wallet.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		ExternalRef: req.ExternalRef,
+		Metadata:    nullAsAbsent(req.Metadata),
+	})
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		respondError(w, profileStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(updated.Version))
+	respondJSON(w, http.StatusOK, updated)
+}
+
+func (h *RestHandler) ListOwnerWallets(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	ownerId := chi.URLParam(r, "ownerId")
+
+	var after uuid.UUID
+	if v := r.URL.Query().Get("after"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid after parameter")
+			return
+		}
+		after = parsed
+	}
+
+	limit := defaultOwnerWallets
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid limit parameter")
+			return
+		}
+		limit = parsed
+	}
+
+	wallets, err := h.s.ListOwnerWallets(ctx, ownerId, after, limit)
+	if err != nil {
+		respondError(w, profileStatus(err), err.Error())
+		return
+	}
+	if wallets == nil {
+		wallets = []wallet.Wallet{}
+	}
+
+	resp := map[string]any{"wallets": wallets}
+	if len(wallets) == limit {
+		resp["nextAfter"] = wallets[len(wallets)-1].ID.String()
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *RestHandler) GetWalletByExternalRef(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	externalRef, err := url.PathUnescape(chi.URLParam(r, "externalRef"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid externalRef parameter")
+		return
+	}
+
+	found, err := h.s.FindWalletByExternalRef(ctx, chi.URLParam(r, "ownerId"), externalRef)
+	if err != nil {
+		respondError(w, profileStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(found.Version))
+	respondJSON(w, http.StatusOK, found)
+}
+
+func profileStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "wallet not found":
+		return http.StatusNotFound
+	case msg == "wallet already exists", msg == "external reference already exists":
+		return http.StatusConflict
+	case msg == "nothing to update", msg == "externalRef requires ownerId",
+		strings.HasPrefix(msg, "ownerId "), strings.HasPrefix(msg, "displayName "),
+		strings.HasPrefix(msg, "externalRef "), strings.HasPrefix(msg, "metadata "),
+		strings.HasPrefix(msg, "currency "), strings.HasPrefix(msg, "type "), strings.HasPrefix(msg, "limit "):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func nullAsAbsent(raw json.RawMessage) json.RawMessage {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/wallet"
+)
+
+func profileRouter(h *RestHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Post("/api/v1/wallets/new", h.CreateWallet)
+	r.Patch("/api/v1/wallets/{walletId}", h.UpdateProfile)
+	r.Get("/api/v1/owners/{ownerId}/wallets", h.ListOwnerWallets)
+	r.Get("/api/v1/owners/{ownerId}/wallets/{externalRef}", h.GetWalletByExternalRef)
+	return r
+}
+
+func TestCreateWallet_GeneratesID(t *testing.T) {
+	ff := &fakeFacade{}
+	r := profileRouter(newHandler(ff))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/new", map[string]any{
+		"ownerId":     "cust-1",
+		"externalRef": "acc/42",
+		"metadata":    map[string]any{"tier": "gold"},
+	}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEqual(t, uuid.Nil, ff.lastCreateID)
+	require.Equal(t, ff.lastCreateID.String(), resp["walletId"])
+	require.Equal(t, "cust-1", ff.lastCreate.OwnerID)
+	require.JSONEq(t, `{"tier":"gold"}`, string(ff.lastCreate.Metadata))
+}
+
+func TestCreateWallet_ProfileValidation(t *testing.T) {
+	r := profileRouter(newHandler(&fakeFacade{}))
+
+	for _, body := range []map[string]any{
+		{"externalRef": "acc/42"},
+		{"ownerId": "cust-1", "metadata": []int{1, 2}},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/new", body))
+		require.Equalf(t, http.StatusBadRequest, w.Code, "body=%s", w.Body.String())
+	}
+
+	ff := &fakeFacade{createErr: errAny("external reference already exists")}
+	r = profileRouter(newHandler(ff))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/wallets/new", map[string]any{"ownerId": "cust-1", "externalRef": "acc/42"}))
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{wallets: []wallet.Wallet{{ID: id, OwnerID: "cust-1", Version: 4}}}
+	r := profileRouter(newHandler(ff))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPatch, "/api/v1/wallets/"+id.String(), map[string]any{"displayName": "Savings"}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, `"4"`, w.Header().Get("ETag"))
+	require.NotNil(t, ff.lastProfile.DisplayName)
+	require.Equal(t, "Savings", *ff.lastProfile.DisplayName)
+	require.Nil(t, ff.lastProfile.ExternalRef)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPatch, "/api/v1/wallets/"+id.String(), map[string]any{}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPatch, "/api/v1/wallets/"+uuid.New().String(), map[string]any{"displayName": "x"}))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOwnerLookups(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ff := &fakeFacade{wallets: []wallet.Wallet{
+		{ID: a, OwnerID: "cust-1", ExternalRef: "acc/42"},
+		{ID: b, OwnerID: "cust-1"},
+		{ID: uuid.New(), OwnerID: "cust-2"},
+	}}
+	r := profileRouter(newHandler(ff))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/owners/cust-1/wallets?limit=1", nil))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	var page struct {
+		Wallets   []wallet.Wallet `json:"wallets"`
+		NextAfter string          `json:"nextAfter"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Wallets, 1)
+	require.Equal(t, a.String(), page.NextAfter)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/owners/cust-1/wallets?limit=5000", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/owners/cust-1/wallets/acc%2F42", nil))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	var found wallet.Wallet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	require.Equal(t, a, found.ID)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/owners/cust-2/wallets/acc%2F42", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"project/internal/money"
+	"project/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReversalHandler struct {
+	s          *service.WalletService
+	adminToken string
+}
+
+// NewReversalHandler allows forced reversals only to requests carrying
+// adminToken; with an empty token they are never allowed.
+func NewReversalHandler(svc *service.WalletService, adminToken string) *ReversalHandler {
+	return &ReversalHandler{
+		s:          svc,
+		adminToken: adminToken,
+	}
+}
+
+type ReverseRequest struct {
+	Amount money.Amount `json:"amount"`
+	Force  bool         `json:"force"`
+}
+
+func (h *ReversalHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	entryId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || entryId <= 0 {
+		respondError(w, http.StatusBadRequest, "invalid id parameter")
+		return
+	}
+
+	var req ReverseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, decodeError(err))
+		return
+	}
+	if req.Force && !isAdmin(r, h.adminToken) {
+		respondError(w, http.StatusForbidden, "force requires admin authorization")
+		return
+	}
+
+	entry, err := h.s.ReverseOperation(ctx, entryId, int64(req.Amount), req.Force)
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		respondError(w, reversalStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, entry)
+}
+
+func reversalStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "operation not found":
+		return http.StatusNotFound
+	case msg == "operation already reversed", msg == "a reversal cannot be reversed",
+		msg == "payout withdrawals cannot be reversed", strings.HasSuffix(msg, "entries cannot be reversed"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "reversal exceeds remaining amount"):
+		return http.StatusBadRequest
+	}
+	return transferStatus(err)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/ledger"
+	"project/internal/service"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newReversalRouter(ff *fakeFacade) *chi.Mux {
+	h := NewReversalHandler(service.NewWalletService(ff), "secret")
+	r := chi.NewRouter()
+	r.Post("/api/v1/operations/{id}/reverse", h.Reverse)
+	return r
+}
+
+func TestReverseOperation(t *testing.T) {
+	ff := &fakeFacade{}
+	r := newReversalRouter(ff)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/operations/41/reverse", nil))
+	require.Equalf(t, http.StatusCreated, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, int64(0), ff.lastAmount)
+
+	var entry ledger.Entry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	require.Equal(t, ledger.Reversal, entry.OperationType)
+	require.Equal(t, int64(41), *entry.ReversalOf)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/operations/41/reverse", map[string]any{"amount": 25}))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, int64(25), ff.lastAmount)
+	require.False(t, ff.lastForce)
+}
+
+func TestReverseOperation_ForceNeedsAdmin(t *testing.T) {
+	ff := &fakeFacade{}
+	r := newReversalRouter(ff)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/operations/41/reverse", map[string]any{"force": true}))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	req := doJSONReq(http.MethodPost, "/api/v1/operations/41/reverse", map[string]any{"force": true})
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.True(t, ff.lastForce)
+}
+
+func TestReverseOperation_Errors(t *testing.T) {
+	cases := map[string]int{
+		"operation not found":                      http.StatusNotFound,
+		"operation already reversed":               http.StatusConflict,
+		"reversal exceeds remaining amount: 5 > 1": http.StatusBadRequest,
+		"not enough balance: 0 < 5":                http.StatusBadRequest,
+		"FEE entries cannot be reversed":           http.StatusConflict,
+		"payout withdrawals cannot be reversed":    http.StatusConflict,
+	}
+	for msg, status := range cases {
+		r := newReversalRouter(&fakeFacade{reverseErr: errAny(msg)})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/operations/3/reverse", nil))
+		require.Equal(t, status, w.Code, msg)
+	}
+
+	r := newReversalRouter(&fakeFacade{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/operations/3/reverse", map[string]any{"amount": -5}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/operations/abc/reverse", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ShardRequest struct {
+	ShardCount int `json:"shardCount"`
+}
+
+func (h *RestHandler) ConfigureShards(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var req ShardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	if err := h.s.ConfigureSharding(ctx, walletId, req.ShardCount); err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case err.Error() == "wallet not found":
+			status = http.StatusNotFound
+		case strings.HasPrefix(err.Error(), "shardCount must be"):
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"walletId": walletId.String(), "shardCount": req.ShardCount})
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestConfigureShards(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{}
+	h := newHandler(ff)
+	r := chi.NewRouter()
+	r.Put("/api/v1/wallets/{walletId}/shards", h.ConfigureShards)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPut, "/api/v1/wallets/"+id.String()+"/shards", map[string]any{"shardCount": 8}))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	require.Equal(t, 8, ff.lastShards)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPut, "/api/v1/wallets/"+id.String()+"/shards", map[string]any{"shardCount": 1000}))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	ff.shardErr = errAny("wallet not found")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPut, "/api/v1/wallets/"+id.String()+"/shards", map[string]any{"shardCount": 2}))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
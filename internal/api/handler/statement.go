@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"project/internal/statement"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *RestHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	from, err := time.Parse(statement.DateLayout, r.URL.Query().Get("from"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid from parameter")
+		return
+	}
+	to, err := time.Parse(statement.DateLayout, r.URL.Query().Get("to"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid to parameter")
+		return
+	}
+
+	format, ok := statementFormat(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "invalid format parameter")
+		return
+	}
+
+	st, err := h.s.GetStatement(ctx, walletId, from, to)
+	if err != nil {
+		status := http.StatusInternalServerError
+		msg := err.Error()
+		switch {
+		case msg == "wallet not found":
+			status = http.StatusNotFound
+		case strings.HasPrefix(msg, "from "), strings.HasPrefix(msg, "date range "), strings.HasPrefix(msg, "statement has too many"):
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, msg)
+		return
+	}
+
+	// The file is rendered in full before anything is sent, so a render
+	// failure can still be answered with an error status.
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+		err = statement.WriteCSV(&buf, st)
+	case "pdf":
+		contentType = "application/pdf"
+		err = statement.WritePDF(&buf, st)
+	default:
+		respondJSON(w, http.StatusOK, st)
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s-%s.%s", walletId, st.From, st.To, format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
+
+// statementFormat takes ?format= over the Accept header, and JSON when
+// neither asks for something else.
+func statementFormat(r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "json", "csv", "pdf":
+		return format, true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		return "csv", true
+	case strings.Contains(accept, "application/pdf"):
+		return "pdf", true
+	}
+	return "json", true
+}
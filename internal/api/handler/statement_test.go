@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/statement"
+)
+
+func TestGetStatement_Formats(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{statement: statement.Statement{Opening: 10, Entries: []ledger.Entry{{ID: 1, Amount: 5, Balance: 15}}}}
+	r := chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}/statement", newHandler(ff).GetStatement)
+	path := "/api/v1/wallets/" + id.String() + "/statement?from=2025-10-01&to=2025-10-31"
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equalf(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "2025-10-01", resp["from"])
+	require.Equal(t, float64(15), resp["closingBalance"])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+"&format=csv", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	require.Contains(t, w.Header().Get("Content-Disposition"), "2025-10-01-2025-10-31.csv")
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", "application/pdf")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	require.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+	require.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+}
+
+func TestGetStatement_BadRequests(t *testing.T) {
+	id := uuid.New()
+	r := chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}/statement", newHandler(&fakeFacade{}).GetStatement)
+
+	for _, q := range []string{
+		"from=2025-10-01",
+		"from=2025-10-31&to=2025-10-01",
+		"from=2024-01-01&to=2025-10-01",
+		"from=2025-10-01&to=2025-10-31&format=xml",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"/statement?"+q, nil))
+		require.Equalf(t, http.StatusBadRequest, w.Code, "query=%s body=%s", q, w.Body.String())
+	}
+
+	r = chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}/statement", newHandler(&fakeFacade{getErr: errAny("wallet not found")}).GetStatement)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"/statement?from=2025-10-01&to=2025-10-01", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
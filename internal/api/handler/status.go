@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/wallet"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WalletStatusRequest struct {
+	Status wallet.Status `json:"status"`
+}
+
+func (h *RestHandler) SetWalletStatus(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	var req WalletStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	wl, err := h.s.SetStatus(ctx, walletId, wallet.Status(strings.ToUpper(string(req.Status))))
+	if err != nil {
+		if respondBusy(w, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "wallet not found":
+			status = http.StatusNotFound
+		case "invalid status parameter":
+			status = http.StatusBadRequest
+		case "wallet is CLOSED", "wallet balance must be zero to close", "wallet has payouts in flight":
+			status = http.StatusConflict
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wl)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/wallet"
+)
+
+func TestSetWalletStatus(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body map[string]any
+		err  error
+		want int
+	}{
+		"ok":          {map[string]any{"status": "frozen"}, nil, http.StatusOK},
+		"bad status":  {map[string]any{"status": "GONE"}, nil, http.StatusBadRequest},
+		"not found":   {map[string]any{"status": "FROZEN"}, errors.New("wallet not found"), http.StatusNotFound},
+		"closed":      {map[string]any{"status": "FROZEN"}, errors.New("wallet is CLOSED"), http.StatusConflict},
+		"not emptied": {map[string]any{"status": "CLOSED"}, errors.New("wallet balance must be zero to close"), http.StatusConflict},
+		"payouts":     {map[string]any{"status": "CLOSED"}, errors.New("wallet has payouts in flight"), http.StatusConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ff := &fakeFacade{statusErr: tc.err}
+			r := chi.NewRouter()
+			r.With(RequireAdmin("secret")).Put("/api/v1/wallets/{walletId}/status", newHandler(ff).SetWalletStatus)
+
+			req := doJSONReq(http.MethodPut, "/api/v1/wallets/"+uuid.NewString()+"/status", tc.body)
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equalf(t, tc.want, w.Code, "body=%s", w.Body.String())
+			if tc.want == http.StatusOK {
+				require.Equal(t, wallet.Frozen, ff.lastStatus)
+			}
+		})
+	}
+}
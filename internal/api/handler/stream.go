@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project/internal/ledger"
+	"project/internal/service"
+	"project/internal/stream"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const replayPageSize = 500
+
+type StreamHandler struct {
+	s         *service.WalletService
+	broker    *stream.Broker
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(svc *service.WalletService, broker *stream.Broker, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{
+		s:         svc,
+		broker:    broker,
+		heartbeat: heartbeat,
+	}
+}
+
+func (h *StreamHandler) Events(w http.ResponseWriter, r *http.Request) {
+
+	walletId, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil || walletId == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+		return
+	}
+
+	// Event ids are ledger cursors: entry ids only follow commit order per
+	// ledger lane, so the cursor holds the last id seen in each lane.
+	var cursor ledger.Cursor
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("lastEventId")
+	}
+	if resume != "" {
+		cursor, err = ledger.ParseCursor(resume)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	ctxCheck, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	_, err = h.s.GetBalance(ctxCheck, walletId)
+	if err == nil && resume == "" {
+		// A new stream starts after what is committed now. The replay below
+		// picks up whatever commits before the subscription.
+		cursor, err = h.s.LedgerHead(ctxCheck, walletId)
+	}
+	cancel()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "wallet not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost;
+	// duplicates are filtered by the cursor below.
+	entries, unsubscribe := h.broker.Subscribe(walletId)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+
+	for {
+		page, err := h.s.LedgerSince(ctx, walletId, cursor, replayPageSize)
+		if err != nil {
+			return
+		}
+		for _, entry := range page {
+			cursor.Advance(entry)
+			if err := writeEvent(w, entry, cursor); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		if len(page) < replayPageSize {
+			break
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			if entry.ID <= cursor.After(entry.Lane()) {
+				continue
+			}
+			cursor.Advance(entry)
+			if err := writeEvent(w, entry, cursor); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, entry ledger.Entry, cursor ledger.Cursor) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: balance\ndata: %s\n\n", cursor, data)
+	return err
+}
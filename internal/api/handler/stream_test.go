@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/service"
+	"project/internal/stream"
+)
+
+func newStreamServer(ff *fakeFacade, broker *stream.Broker, heartbeat time.Duration) *httptest.Server {
+	h := NewStreamHandler(service.NewWalletService(ff), broker, heartbeat)
+	r := chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}/events", h.Events)
+	return httptest.NewServer(r)
+}
+
+func readEventID(t *testing.T, sc *bufio.Scanner) string {
+	t.Helper()
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "id: ") {
+			return strings.TrimPrefix(line, "id: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", sc.Err())
+	return ""
+}
+
+func TestEvents_ReplaysFromLastEventIDThenStreamsLive(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{ledger: []ledger.Entry{
+		{ID: 1, WalletID: id, Amount: 10, Balance: 10},
+		{ID: 2, WalletID: id, Amount: 5, Balance: 15},
+		{ID: 3, WalletID: id, Amount: -5, Balance: 10},
+	}}
+	broker := stream.NewBroker()
+	srv := newStreamServer(ff, broker, time.Minute)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/wallets/"+id.String()+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	sc := bufio.NewScanner(resp.Body)
+	require.Equal(t, "1,-1:2", readEventID(t, sc))
+	require.Equal(t, "1,-1:3", readEventID(t, sc))
+
+	broker.Publish(ledger.Entry{ID: 3, WalletID: id})
+	broker.Publish(ledger.Entry{ID: 4, WalletID: id, Amount: 1, Balance: 11})
+	require.Equal(t, "1,-1:4", readEventID(t, sc))
+}
+
+func TestEvents_ResumesEachLane(t *testing.T) {
+	id := uuid.New()
+	shard := 2
+	// Entry 5 committed on the wallets row before the sharded deposit that
+	// had taken id 4.
+	ff := &fakeFacade{ledger: []ledger.Entry{
+		{ID: 5, WalletID: id, Amount: 10},
+		{ID: 4, WalletID: id, Amount: 7, Shard: &shard},
+	}}
+	broker := stream.NewBroker()
+	srv := newStreamServer(ff, broker, time.Minute)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/wallets/"+id.String()+"/events", nil)
+	req.Header.Set("Last-Event-ID", "0,-1:5")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	require.Equal(t, "0,-1:5,2:4", readEventID(t, sc))
+
+	broker.Publish(ledger.Entry{ID: 4, WalletID: id, Shard: &shard})
+	broker.Publish(ledger.Entry{ID: 6, WalletID: id, Shard: &shard})
+	require.Equal(t, "0,-1:5,2:6", readEventID(t, sc))
+}
+
+func TestEvents_NewStreamStartsAtHead(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{ledger: []ledger.Entry{
+		{ID: 1, WalletID: id, Amount: 10},
+		{ID: 2, WalletID: id, Amount: 5},
+	}}
+	broker := stream.NewBroker()
+	srv := newStreamServer(ff, broker, time.Minute)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/wallets/"+id.String()+"/events", nil)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	broker.Publish(ledger.Entry{ID: 2, WalletID: id})
+	broker.Publish(ledger.Entry{ID: 3, WalletID: id})
+	require.Equal(t, "0,-1:3", readEventID(t, sc))
+}
+
+func TestEvents_Heartbeat(t *testing.T) {
+	id := uuid.New()
+	srv := newStreamServer(&fakeFacade{}, stream.NewBroker(), 10*time.Millisecond)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/wallets/"+id.String()+"/events", nil)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if sc.Text() == ": heartbeat" {
+			return
+		}
+	}
+	t.Fatalf("no heartbeat received")
+}
+
+func TestEvents_Errors(t *testing.T) {
+	id := uuid.New()
+
+	srv := newStreamServer(&fakeFacade{}, stream.NewBroker(), time.Minute)
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/wallets/"+id.String()+"/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	srvMissing := newStreamServer(&fakeFacade{getErr: errAny("wallet not found")}, stream.NewBroker(), time.Minute)
+	defer srvMissing.Close()
+	resp, err = http.Get(srvMissing.URL + "/api/v1/wallets/" + id.String() + "/events")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
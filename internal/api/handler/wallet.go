@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"project/internal/money"
+	"project/internal/wallet"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,16 +19,25 @@ type WalletOperationType string
 const (
 	Deposit  WalletOperationType = "DEPOSIT"
 	Withdraw WalletOperationType = "WITHDRAW"
+	// Transfer moves the amount from walletId to toWalletId.
+	Transfer WalletOperationType = "TRANSFER"
 )
 
 type WalletRequest struct {
 	WalletID      string              `json:"walletId"`
 	OperationType WalletOperationType `json:"operationType"`
-	Amount        int64               `json:"amount"`
+	Amount        money.Amount        `json:"amount"`
+	ToWalletID    string              `json:"toWalletId,omitempty"`
 }
 
 type CreateWalletRequest struct {
-	WalletID string `json:"walletId"`
+	WalletID    string          `json:"walletId"`
+	OwnerID     string          `json:"ownerId"`
+	DisplayName string          `json:"displayName"`
+	ExternalRef string          `json:"externalRef"`
+	Currency    string          `json:"currency"`
+	Type        string          `json:"type"`
+	Metadata    json.RawMessage `json:"metadata"`
 }
 
 func (h *RestHandler) TransferFunds(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +47,7 @@ func (h *RestHandler) TransferFunds(w http.ResponseWriter, r *http.Request) {
 
 	var req WalletRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid json")
+		respondError(w, http.StatusBadRequest, decodeError(err))
 		return
 	}
 
@@ -44,33 +57,65 @@ func (h *RestHandler) TransferFunds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, conditional, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		respondError(w, http.StatusPreconditionFailed, "version mismatch")
+		return
+	}
+
 	switch req.OperationType {
 	case Deposit:
-		if err := h.s.DepositFunds(ctx, parsedWalletID, req.Amount); err != nil {
-			status := http.StatusInternalServerError
-			switch err.Error() {
-			case "wallet not found":
-				status = http.StatusNotFound
-			case "amount must be positive":
-				status = http.StatusBadRequest
+		deposit := h.s.DepositFunds
+		if conditional {
+			deposit = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+				return h.s.DepositFundsIfVersion(ctx, walletId, amount, version)
+			}
+		}
+		if err := deposit(ctx, parsedWalletID, int64(req.Amount)); err != nil {
+			if respondBusy(w, err) {
+				return
 			}
-			respondError(w, status, err.Error())
+			respondError(w, transferStatus(err), err.Error())
 			return
 		}
 	case Withdraw:
-		if err := h.s.WithdrawFunds(ctx, parsedWalletID, req.Amount); err != nil {
-			status := http.StatusInternalServerError
-			switch err.Error() {
-			case "wallet not found":
-				status = http.StatusNotFound
-			case "amount must be positive":
-				status = http.StatusBadRequest
-			case "not enough balance":
-				status = http.StatusBadRequest
+		withdraw := h.s.WithdrawFunds
+		if conditional {
+			withdraw = func(ctx context.Context, walletId uuid.UUID, amount int64) (int64, error) {
+				return h.s.WithdrawFundsIfVersion(ctx, walletId, amount, version)
 			}
-			respondError(w, status, err.Error())
+		}
+		fee, err := withdraw(ctx, parsedWalletID, int64(req.Amount))
+		if err != nil {
+			if respondBusy(w, err) {
+				return
+			}
+			respondError(w, transferStatus(err), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"status": "success", "fee": fee})
+		return
+	case Transfer:
+		to, ok := parseToWallet(w, req)
+		if !ok {
 			return
 		}
+		// A transfer changes two wallets, so one wallet's version cannot
+		// guard it.
+		if conditional {
+			respondError(w, http.StatusBadRequest, "If-Match is not supported for transfers")
+			return
+		}
+		fee, err := h.s.TransferFunds(ctx, parsedWalletID, to, int64(req.Amount))
+		if err != nil {
+			if respondBusy(w, err) {
+				return
+			}
+			respondError(w, transferStatus(err), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"status": "success", "fee": fee})
+		return
 	default:
 		respondError(w, http.StatusBadRequest, "invalid operationType parameter")
 		return
@@ -90,22 +135,35 @@ func (h *RestHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parsedWalletID, err := uuid.Parse(req.WalletID)
-	if err != nil || parsedWalletID == uuid.Nil {
-		respondError(w, http.StatusBadRequest, "invalid walletId parameter")
-		return
+	// An omitted walletId is generated by the server.
+	var walletId uuid.UUID
+	if req.WalletID != "" {
+		parsed, err := uuid.Parse(req.WalletID)
+		if err != nil || parsed == uuid.Nil {
+			respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+			return
+		}
+		walletId = parsed
 	}
 
-	if err := h.s.CreateWallet(ctx, parsedWalletID); err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "wallet already exists" {
-			status = http.StatusConflict
+	created, err := h.s.CreateWalletWithProfile(ctx, wallet.Wallet{
+		ID:          walletId,
+		OwnerID:     req.OwnerID,
+		DisplayName: req.DisplayName,
+		ExternalRef: req.ExternalRef,
+		Currency:    req.Currency,
+		Type:        wallet.Type(req.Type),
+		Metadata:    nullAsAbsent(req.Metadata),
+	})
+	if err != nil {
+		if respondBusy(w, err) {
+			return
 		}
-		respondError(w, status, err.Error())
+		respondError(w, profileStatus(err), err.Error())
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]any{"status": "success"})
+	respondJSON(w, http.StatusOK, map[string]any{"status": "success", "walletId": created.ID.String()})
 }
 
 func (h *RestHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
@@ -120,7 +178,7 @@ func (h *RestHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balance, err := h.s.GetBalance(ctx, walletId)
+	balance, version, err := h.s.GetBalanceVersion(ctx, walletId)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "wallet not found" {
@@ -130,5 +188,55 @@ func (h *RestHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(version))
 	respondJSON(w, http.StatusOK, map[string]any{"walletId": walletId.String(), "balance": balance})
 }
+
+func transferStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "wallet not found":
+		return http.StatusNotFound
+	case msg == "version mismatch":
+		return http.StatusPreconditionFailed
+	case strings.HasPrefix(msg, "amount must be"), strings.HasPrefix(msg, "not enough balance"),
+		msg == "balance limit exceeded", msg == "amount overflow",
+		msg == "cannot transfer to the same wallet", strings.HasPrefix(msg, "currency mismatch"),
+		strings.HasPrefix(msg, "wallet is "):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// parseToWallet reads the receiving wallet of a TRANSFER, answering 400 when
+// it is missing or invalid.
+func parseToWallet(w http.ResponseWriter, req WalletRequest) (uuid.UUID, bool) {
+	to, err := uuid.Parse(req.ToWalletID)
+	if err != nil || to == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid toWalletId parameter")
+		return uuid.Nil, false
+	}
+	return to, true
+}
+
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads the wallet version a request is conditional on. An
+// absent header or "*" makes it unconditional; anything that is not one of
+// our ETags can never match.
+func parseIfMatch(header string) (version int64, conditional bool, ok bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, false, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, true, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, true, false
+	}
+	return version, true, true
+}
@@ -4,15 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"project/internal/batch"
+	"project/internal/contention"
+	"project/internal/ledger"
 	"project/internal/service"
+	"project/internal/statement"
+	"project/internal/wallet"
 )
 
 type fakeFacade struct {
@@ -21,22 +28,56 @@ type fakeFacade struct {
 	getBal      int64
 	getErr      error
 	createErr   error
+	ledger      []ledger.Entry
+	batch       batch.Batch
+	batchErr    error
+	lastBatch   []batch.Operation
+	shardErr    error
+	lastShards  int
+	version     int64
+	reverseErr  error
+	lastForce   bool
 
 	lastDepositID  uuid.UUID
 	lastWithdrawID uuid.UUID
 	lastGetID      uuid.UUID
 	lastCreateID   uuid.UUID
 	lastAmount     int64
+	lastIfVersion  int64
+	lastCreate     wallet.Wallet
+	lastProfile    wallet.ProfileUpdate
+	wallets        []wallet.Wallet
+	lastQuery      wallet.Query
+	lastAt         time.Time
+	statement      statement.Statement
+	lastFee        ledger.FeeCharge
+	lastTransferTo uuid.UUID
+	withdrawn      int64
+	lastRate       int64
+	rateErr        error
+	lastStatus     wallet.Status
+	statusErr      error
 }
 
+func (f *fakeFacade) Refund(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	return nil
+}
 func (f *fakeFacade) Deposit(ctx context.Context, walletId uuid.UUID, amount int64) error {
 	f.lastDepositID = walletId
 	f.lastAmount = amount
 	return f.depositErr
 }
-func (f *fakeFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64) error {
+func (f *fakeFacade) WithdrawRef(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	return f.Withdraw(ctx, walletId, amount, fee)
+}
+func (f *fakeFacade) Transfer(ctx context.Context, from, to uuid.UUID, amount int64, fee ledger.FeeCharge) error {
+	f.lastTransferTo = to
+	return f.Withdraw(ctx, from, amount, fee)
+}
+func (f *fakeFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge) error {
 	f.lastWithdrawID = walletId
 	f.lastAmount = amount
+	f.lastFee = fee
 	return f.withdrawErr
 }
 func (f *fakeFacade) GetByID(ctx context.Context, walletId uuid.UUID) (int64, error) {
@@ -50,6 +91,157 @@ func (f *fakeFacade) Create(ctx context.Context, walletId uuid.UUID) error {
 	f.lastCreateID = walletId
 	return f.createErr
 }
+func (f *fakeFacade) GetLedger(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	var out []ledger.Entry
+	for _, e := range f.ledger {
+		if e.WalletID == walletId && e.ID > after.After(e.Lane()) && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) GetLedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	head := ledger.Cursor{Lanes: make(map[int]int64)}
+	for _, e := range f.ledger {
+		if e.WalletID == walletId {
+			head.Advance(e)
+		}
+	}
+	return head, nil
+}
+func (f *fakeFacade) ApplyBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+	f.lastBatch = ops
+	if f.batchErr != nil {
+		return batch.Batch{}, f.batchErr
+	}
+	return f.batch, nil
+}
+func (f *fakeFacade) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	if f.batchErr != nil {
+		return batch.Batch{}, f.batchErr
+	}
+	return f.batch, nil
+}
+func (f *fakeFacade) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	f.lastGetID = walletId
+	if f.getErr != nil {
+		return 0, 0, f.getErr
+	}
+	return f.getBal, f.version, nil
+}
+func (f *fakeFacade) DepositIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error {
+	f.lastDepositID = walletId
+	f.lastAmount = amount
+	f.lastIfVersion = version
+	return f.depositErr
+}
+func (f *fakeFacade) WithdrawIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64, fee ledger.FeeCharge) error {
+	f.lastWithdrawID = walletId
+	f.lastAmount = amount
+	f.lastIfVersion = version
+	f.lastFee = fee
+	return f.withdrawErr
+}
+func (f *fakeFacade) CreateWithProfile(ctx context.Context, wl wallet.Wallet) error {
+	f.lastCreateID = wl.ID
+	f.lastCreate = wl
+	return f.createErr
+}
+func (f *fakeFacade) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	for _, wl := range f.wallets {
+		if wl.ID == walletId {
+			return wl, nil
+		}
+	}
+	return wallet.Wallet{}, errAny("wallet not found")
+}
+func (f *fakeFacade) GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	for _, wl := range f.wallets {
+		if wl.OwnerID == ownerId && wl.ExternalRef == externalRef {
+			return wl, nil
+		}
+	}
+	return wallet.Wallet{}, errAny("wallet not found")
+}
+func (f *fakeFacade) ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	var out []wallet.Wallet
+	for _, wl := range f.wallets {
+		if wl.OwnerID == ownerId && len(out) < limit {
+			out = append(out, wl)
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) UpdateProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error) {
+	f.lastProfile = upd
+	return f.GetWallet(ctx, walletId)
+}
+func (f *fakeFacade) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	f.lastQuery = q
+	start := 0
+	if q.After != nil {
+		for i, wl := range f.wallets {
+			if wl.ID == q.After.ID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + q.Limit
+	if end > len(f.wallets) {
+		end = len(f.wallets)
+	}
+	return f.wallets[start:end], nil
+}
+func (f *fakeFacade) GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	f.lastGetID = walletId
+	f.lastAt = at
+	return f.getBal, f.getErr
+}
+func (f *fakeFacade) GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error) {
+	f.lastAt = at
+	var out []ledger.PointBalance
+	for _, id := range walletIds {
+		for _, wl := range f.wallets {
+			if wl.ID == id {
+				out = append(out, ledger.PointBalance{WalletID: id, Balance: wl.Balance, At: at})
+			}
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time, maxEntries int) (statement.Statement, error) {
+	if f.getErr != nil {
+		return statement.Statement{}, f.getErr
+	}
+	st := f.statement
+	st.WalletID, st.From, st.To = walletId, statement.Date(from), statement.Date(to)
+	st.Total()
+	return st, nil
+}
+func (f *fakeFacade) SetShardCount(ctx context.Context, walletId uuid.UUID, shards int) error {
+	f.lastShards = shards
+	return f.shardErr
+}
+
+func (f *fakeFacade) Reverse(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error) {
+	f.lastAmount, f.lastForce = amount, force
+	if f.reverseErr != nil {
+		return ledger.Entry{}, f.reverseErr
+	}
+	reversalOf := entryId
+	return ledger.Entry{ID: entryId + 1, OperationType: ledger.Reversal, Amount: -amount, ReversalOf: &reversalOf}, nil
+}
+func (f *fakeFacade) GetWithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error) {
+	return f.withdrawn, nil
+}
+func (f *fakeFacade) SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	f.lastRate = rateBps
+	return f.rateErr
+}
+func (f *fakeFacade) SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error) {
+	f.lastStatus = status
+	return wallet.Wallet{ID: walletId, Status: status}, f.statusErr
+}
 
 func newHandler(ff *fakeFacade) *RestHandler {
 	ws := service.NewWalletService(ff)
@@ -329,4 +521,91 @@ func TestGetBalance_Success(t *testing.T) {
 
 type errAny string
 
-func (e errAny) Error() string { return string(e) }
\ No newline at end of file
+func (e errAny) Error() string { return string(e) }
+func TestTransferFunds_WalletBusy(t *testing.T) {
+	ff := &fakeFacade{depositErr: fmt.Errorf("transaction failed after 3 attempts: %w",
+		&contention.BusyError{RetryAfter: 1500 * time.Millisecond})}
+	h := newHandler(ff)
+
+	req := doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": uuid.New().String(), "operationType": "DEPOSIT", "amount": 10,
+	})
+	w := httptest.NewRecorder()
+	h.TransferFunds(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	require.Contains(t, w.Body.String(), "wallet busy")
+}
+
+func TestGetBalance_ETag(t *testing.T) {
+	id := uuid.New()
+	ff := &fakeFacade{getBal: 500, version: 7}
+	h := newHandler(ff)
+	r := chi.NewRouter()
+	r.Get("/api/v1/wallets/{walletId}", h.GetBalance)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
+
+func TestTransferFunds_IfMatch(t *testing.T) {
+	id := uuid.New()
+	body := map[string]any{"walletId": id.String(), "operationType": "WITHDRAW", "amount": 100}
+
+	ff := &fakeFacade{}
+	h := newHandler(ff)
+	req := doJSONReq(http.MethodPost, "/api/v1/wallet", body)
+	req.Header.Set("If-Match", `"7"`)
+	w := httptest.NewRecorder()
+	h.TransferFunds(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(7), ff.lastIfVersion)
+
+	ff = &fakeFacade{withdrawErr: errAny("version mismatch")}
+	h = newHandler(ff)
+	req = doJSONReq(http.MethodPost, "/api/v1/wallet", body)
+	req.Header.Set("If-Match", `"6"`)
+	w = httptest.NewRecorder()
+	h.TransferFunds(w, req)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req = doJSONReq(http.MethodPost, "/api/v1/wallet", body)
+	req.Header.Set("If-Match", `W/"6"`)
+	w = httptest.NewRecorder()
+	h.TransferFunds(w, req)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestTransferFunds_RejectsNonIntegerAmounts(t *testing.T) {
+	ff := &fakeFacade{}
+	h := newHandler(ff)
+	id := uuid.New()
+
+	for _, amount := range []string{"1.5", "1e3", `"10"`, "99999999999999999999"} {
+		body := `{"walletId":"` + id.String() + `","operationType":"DEPOSIT","amount":` + amount + `}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.TransferFunds(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, amount)
+		require.NotContains(t, w.Body.String(), "invalid json", amount)
+	}
+	require.Equal(t, uuid.Nil, ff.lastDepositID)
+}
+
+func TestTransferFunds_NotEnoughBalanceWithDetail(t *testing.T) {
+	ff := &fakeFacade{withdrawErr: errAny("not enough balance: 10 < 50")}
+	h := newHandler(ff)
+
+	req := doJSONReq(http.MethodPost, "/api/v1/wallet", map[string]any{
+		"walletId": uuid.New().String(), "operationType": "WITHDRAW", "amount": 50,
+	})
+	w := httptest.NewRecorder()
+	h.TransferFunds(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
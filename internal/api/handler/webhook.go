@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"project/internal/service"
+	"project/internal/webhook"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	s *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		s: svc,
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	WalletID   string   `json:"walletId,omitempty"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	sub := webhook.Subscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	}
+	if req.WalletID != "" {
+		walletId, err := uuid.Parse(req.WalletID)
+		if err != nil || walletId == uuid.Nil {
+			respondError(w, http.StatusBadRequest, "invalid walletId parameter")
+			return
+		}
+		sub.WalletID = &walletId
+	}
+
+	created, err := h.s.Subscribe(ctx, sub)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "invalid url", "eventTypes must not be empty", "unknown event type":
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	subs, err := h.s.List(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if subs == nil {
+		subs = []webhook.Subscription{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"subscriptions": subs})
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	id, ok := parseSubscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Unsubscribe(ctx, id); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "subscription not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"status": "success"})
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	id, ok := parseSubscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.s.Deliveries(ctx, id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "subscription not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []webhook.Delivery{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+	defer cancel()
+
+	id, ok := parseSubscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryId, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+	if err != nil || deliveryId <= 0 {
+		respondError(w, http.StatusBadRequest, "invalid deliveryId parameter")
+		return
+	}
+
+	if err := h.s.Redeliver(ctx, id, deliveryId); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "delivery not found" {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]any{"status": "success"})
+}
+
+func parseSubscriptionID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "subscriptionId"))
+	if err != nil || id == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "invalid subscriptionId parameter")
+		return uuid.Nil, false
+	}
+	return id, true
+}
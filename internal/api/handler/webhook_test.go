@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"project/internal/service"
+	"project/internal/webhook"
+)
+
+type fakeWebhookStore struct {
+	webhook.Store
+
+	inserted       webhook.Subscription
+	redeliverErr   error
+	lastRedelivery int64
+}
+
+func (f *fakeWebhookStore) InsertSubscription(ctx context.Context, sub webhook.Subscription) error {
+	f.inserted = sub
+	return nil
+}
+
+func (f *fakeWebhookStore) RedeliverDelivery(ctx context.Context, subscriptionId uuid.UUID, id int64) error {
+	f.lastRedelivery = id
+	return f.redeliverErr
+}
+
+func newWebhookRouter(store *fakeWebhookStore) *chi.Mux {
+	h := NewWebhookHandler(service.NewWebhookService(store))
+	r := chi.NewRouter()
+	r.Post("/api/v1/webhooks", h.Create)
+	r.Post("/api/v1/webhooks/{subscriptionId}/deliveries/{deliveryId}/redeliver", h.Redeliver)
+	return r
+}
+
+func TestCreateWebhook_Success(t *testing.T) {
+	store := &fakeWebhookStore{}
+	r := newWebhookRouter(store)
+	walletId := uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/webhooks", map[string]any{
+		"url":        "https://partner.example.com/hooks",
+		"eventTypes": []string{"WalletCredited", "WalletDebited"},
+		"walletId":   walletId.String(),
+	}))
+
+	require.Equalf(t, http.StatusCreated, w.Code, "body=%s", w.Body.String())
+	var resp webhook.Subscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Secret)
+	require.Equal(t, walletId, *store.inserted.WalletID)
+}
+
+func TestCreateWebhook_InvalidEventType(t *testing.T) {
+	r := newWebhookRouter(&fakeWebhookStore{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, doJSONReq(http.MethodPost, "/api/v1/webhooks", map[string]any{
+		"url":        "https://partner.example.com/hooks",
+		"eventTypes": []string{"Nope"},
+	}))
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRedeliver(t *testing.T) {
+	store := &fakeWebhookStore{}
+	r := newWebhookRouter(store)
+	subId := uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+subId.String()+"/deliveries/42/redeliver", nil))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, int64(42), store.lastRedelivery)
+
+	store.redeliverErr = errAny("delivery not found")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+subId.String()+"/deliveries/42/redeliver", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+subId.String()+"/deliveries/abc/redeliver", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
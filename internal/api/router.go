@@ -2,35 +2,114 @@ package api
 
 import (
 	"context"
+	"expvar"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"net/http"
 	"project/internal/api/handler"
 	"project/internal/service"
+	"project/internal/stream"
+	"time"
 )
 
+const sseHeartbeat = 15 * time.Second
+
 type Router struct {
 	r *chi.Mux
 	s *http.Server
 }
 
-func SetupRouter(s *service.WalletService) *Router {
+type Services struct {
+	Wallet   *service.WalletService
+	Webhooks *service.WebhookService
+	Events   *stream.Broker
+	Imports  *service.ImportService
+	Payouts  *service.PayoutService
+
+	// BankStatements is served under the admin routes.
+	BankStatements *service.BankStatementService
+
+	// AdminToken enables the back-office routes; they are not mounted
+	// without it.
+	AdminToken string
+}
+
+func SetupRouter(svc Services) *Router {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(handler.ReadYourWrites)
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("OK"))
 	})
 
-	h := handler.NewHandler(s)
+	if svc.AdminToken != "" {
+		r.With(handler.RequireAdmin(svc.AdminToken)).Handle("/debug/vars", expvar.Handler())
+	}
+
+	h := handler.NewHandler(svc.Wallet)
 
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Post("/wallet", h.TransferFunds)
+		r.Post("/fees/quote", h.QuoteFee)
 		r.Get("/wallets/{walletId}", h.GetBalance)
+		r.Get("/wallets/{walletId}/balance", h.GetBalanceAt)
+		r.Get("/wallets/{walletId}/statement", h.GetStatement)
 		r.Post("/wallets/new", h.CreateWallet)
+		r.Post("/wallets/balances", h.GetBalancesAt)
+		r.Patch("/wallets/{walletId}", h.UpdateProfile)
+		r.Get("/owners/{ownerId}/wallets", h.ListOwnerWallets)
+		r.Get("/owners/{ownerId}/wallets/{externalRef}", h.GetWalletByExternalRef)
+		r.Post("/batches", h.CreateBatch)
+		r.Get("/batches/{batchId}", h.GetBatch)
+
+		if svc.AdminToken != "" {
+			rh := handler.NewReversalHandler(svc.Wallet, svc.AdminToken)
+			r.With(handler.RequireAdmin(svc.AdminToken)).Post("/operations/{id}/reverse", rh.Reverse)
+			r.With(handler.RequireAdmin(svc.AdminToken)).Get("/wallets", h.ListWallets)
+			r.With(handler.RequireAdmin(svc.AdminToken)).Put("/wallets/{walletId}/interest-rate", h.SetInterestRate)
+			r.With(handler.RequireAdmin(svc.AdminToken)).Put("/wallets/{walletId}/shards", h.ConfigureShards)
+			r.With(handler.RequireAdmin(svc.AdminToken)).Put("/wallets/{walletId}/status", h.SetWalletStatus)
+
+			if svc.BankStatements != nil {
+				bh := handler.NewBankStatementHandler(svc.BankStatements)
+				r.Group(func(r chi.Router) {
+					r.Use(handler.RequireAdmin(svc.AdminToken))
+					r.Post("/bank-statements", bh.Import)
+					r.Get("/bank-statements/exceptions", bh.ListExceptions)
+					r.Post("/bank-statements/exceptions/{lineId}/resolve", bh.Resolve)
+				})
+			}
+		}
+
+		if svc.Events != nil {
+			sh := handler.NewStreamHandler(svc.Wallet, svc.Events, sseHeartbeat)
+			r.Get("/wallets/{walletId}/events", sh.Events)
+		}
+
+		if svc.Imports != nil {
+			ih := handler.NewImportHandler(svc.Imports)
+			r.Post("/imports", ih.Upload)
+			r.Get("/imports/{jobId}", ih.Get)
+		}
+
+		if svc.Payouts != nil {
+			ph := handler.NewPayoutHandler(svc.Payouts)
+			r.Post("/wallets/{walletId}/payouts", ph.Request)
+			r.Get("/payouts/{payoutId}", ph.Get)
+		}
+
+		if svc.Webhooks != nil {
+			wh := handler.NewWebhookHandler(svc.Webhooks)
+			r.Post("/webhooks", wh.Create)
+			r.Get("/webhooks", wh.List)
+			r.Delete("/webhooks/{subscriptionId}", wh.Delete)
+			r.Get("/webhooks/{subscriptionId}/deliveries", wh.ListDeliveries)
+			r.Post("/webhooks/{subscriptionId}/deliveries/{deliveryId}/redeliver", wh.Redeliver)
+		}
 	})
 
 	return &Router{r: r}
@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"project/internal/batch"
+	"project/internal/ledger"
 	"project/internal/service"
+	"project/internal/statement"
+	"project/internal/wallet"
 )
 
 type fakeFacade struct {
@@ -20,20 +26,43 @@ type fakeFacade struct {
 	getBal      int64
 	getErr      error
 	createErr   error
+	ledger      []ledger.Entry
+	batch       batch.Batch
+	batchErr    error
+	lastBatch   []batch.Operation
+	shardErr    error
+	lastShards  int
+	version     int64
 
 	lastDepositID  uuid.UUID
 	lastWithdrawID uuid.UUID
 	lastGetID      uuid.UUID
 	lastCreateID   uuid.UUID
 	lastAmount     int64
+	lastIfVersion  int64
+	lastCreate     wallet.Wallet
+	lastProfile    wallet.ProfileUpdate
+	wallets        []wallet.Wallet
+	lastQuery      wallet.Query
+	lastAt         time.Time
+	statement      statement.Statement
 }
 
+func (f *fakeFacade) Refund(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	return nil
+}
 func (f *fakeFacade) Deposit(ctx context.Context, walletId uuid.UUID, amount int64) error {
 	f.lastDepositID = walletId
 	f.lastAmount = amount
 	return f.depositErr
 }
-func (f *fakeFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64) error {
+func (f *fakeFacade) WithdrawRef(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	return f.Withdraw(ctx, walletId, amount, fee)
+}
+func (f *fakeFacade) Transfer(ctx context.Context, from, to uuid.UUID, amount int64, fee ledger.FeeCharge) error {
+	return f.Withdraw(ctx, from, amount, fee)
+}
+func (f *fakeFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge) error {
 	f.lastWithdrawID = walletId
 	f.lastAmount = amount
 	return f.withdrawErr
@@ -49,11 +78,157 @@ func (f *fakeFacade) Create(ctx context.Context, walletId uuid.UUID) error {
 	f.lastCreateID = walletId
 	return f.createErr
 }
+func (f *fakeFacade) GetLedger(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	var out []ledger.Entry
+	for _, e := range f.ledger {
+		if e.WalletID == walletId && e.ID > after.After(e.Lane()) && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) GetLedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	head := ledger.Cursor{Lanes: make(map[int]int64)}
+	for _, e := range f.ledger {
+		if e.WalletID == walletId {
+			head.Advance(e)
+		}
+	}
+	return head, nil
+}
+func (f *fakeFacade) ApplyBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+	f.lastBatch = ops
+	if f.batchErr != nil {
+		return batch.Batch{}, f.batchErr
+	}
+	return f.batch, nil
+}
+func (f *fakeFacade) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	if f.batchErr != nil {
+		return batch.Batch{}, f.batchErr
+	}
+	return f.batch, nil
+}
+func (f *fakeFacade) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	f.lastGetID = walletId
+	if f.getErr != nil {
+		return 0, 0, f.getErr
+	}
+	return f.getBal, f.version, nil
+}
+func (f *fakeFacade) DepositIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error {
+	f.lastDepositID = walletId
+	f.lastAmount = amount
+	f.lastIfVersion = version
+	return f.depositErr
+}
+func (f *fakeFacade) WithdrawIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64, fee ledger.FeeCharge) error {
+	f.lastWithdrawID = walletId
+	f.lastAmount = amount
+	f.lastIfVersion = version
+	return f.withdrawErr
+}
+func (f *fakeFacade) CreateWithProfile(ctx context.Context, wl wallet.Wallet) error {
+	f.lastCreateID = wl.ID
+	f.lastCreate = wl
+	return f.createErr
+}
+func (f *fakeFacade) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	for _, wl := range f.wallets {
+		if wl.ID == walletId {
+			return wl, nil
+		}
+	}
+	return wallet.Wallet{}, errors.New("wallet not found")
+}
+func (f *fakeFacade) GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	for _, wl := range f.wallets {
+		if wl.OwnerID == ownerId && wl.ExternalRef == externalRef {
+			return wl, nil
+		}
+	}
+	return wallet.Wallet{}, errors.New("wallet not found")
+}
+func (f *fakeFacade) ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	var out []wallet.Wallet
+	for _, wl := range f.wallets {
+		if wl.OwnerID == ownerId && len(out) < limit {
+			out = append(out, wl)
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) UpdateProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error) {
+	f.lastProfile = upd
+	return f.GetWallet(ctx, walletId)
+}
+func (f *fakeFacade) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	f.lastQuery = q
+	start := 0
+	if q.After != nil {
+		for i, wl := range f.wallets {
+			if wl.ID == q.After.ID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + q.Limit
+	if end > len(f.wallets) {
+		end = len(f.wallets)
+	}
+	return f.wallets[start:end], nil
+}
+func (f *fakeFacade) GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	f.lastGetID = walletId
+	f.lastAt = at
+	return f.getBal, f.getErr
+}
+func (f *fakeFacade) GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error) {
+	f.lastAt = at
+	var out []ledger.PointBalance
+	for _, id := range walletIds {
+		for _, wl := range f.wallets {
+			if wl.ID == id {
+				out = append(out, ledger.PointBalance{WalletID: id, Balance: wl.Balance, At: at})
+			}
+		}
+	}
+	return out, nil
+}
+func (f *fakeFacade) GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time, maxEntries int) (statement.Statement, error) {
+	if f.getErr != nil {
+		return statement.Statement{}, f.getErr
+	}
+	st := f.statement
+	st.WalletID, st.From, st.To = walletId, statement.Date(from), statement.Date(to)
+	st.Total()
+	return st, nil
+}
+func (f *fakeFacade) SetShardCount(ctx context.Context, walletId uuid.UUID, shards int) error {
+	f.lastShards = shards
+	return f.shardErr
+}
+
+func (f *fakeFacade) Reverse(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error) {
+	return ledger.Entry{}, nil
+}
+
+func (f *fakeFacade) GetWithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeFacade) SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	return nil
+}
+
+func (f *fakeFacade) SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error) {
+	return wallet.Wallet{ID: walletId, Status: status}, nil
+}
 
 func newTestServer() (*Router, *fakeFacade) {
 	ff := &fakeFacade{getBal: 123}
 	ws := service.NewWalletService(ff)
-	rt := SetupRouter(ws)
+	rt := SetupRouter(Services{Wallet: ws})
 	return rt, ff
 }
 
@@ -109,6 +284,19 @@ func TestTransferFunds_Withdraw_Success(t *testing.T) {
 	require.Equalf(t, int64(70), ff.lastAmount, "facade not called as expected: id=%v amount=%d", ff.lastWithdrawID, ff.lastAmount)
 }
 
+func TestQuoteFee_Route(t *testing.T) {
+	rt, _ := newTestServer()
+
+	w := doReq(rt.r, http.MethodPost, "/api/v1/fees/quote", map[string]any{
+		"walletId":      uuid.New().String(),
+		"operationType": "WITHDRAW",
+		"amount":        70,
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"amount":70,"fee":0,"total":70,"rateBps":0,"monthlyVolume":0}`, w.Body.String())
+}
+
 func TestGetBalance_Success(t *testing.T) {
 	rt, ff := newTestServer()
 	id := uuid.New()
@@ -148,3 +336,33 @@ func TestUnknownRoute_404(t *testing.T) {
 	w := doReq(rt.r, http.MethodGet, "/nope", nil)
 	require.Equalf(t, http.StatusNotFound, w.Code, "want 404, got %d body=%s", w.Code, w.Body.Bytes())
 }
+
+func TestRouter_AdminRoutes(t *testing.T) {
+	ws := service.NewWalletService(&fakeFacade{})
+
+	rt := SetupRouter(Services{Wallet: ws})
+	w := doReq(rt.r, http.MethodGet, "/api/v1/wallets", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doReq(rt.r, http.MethodPost, "/api/v1/operations/1/reverse", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = doReq(rt.r, http.MethodPut, "/api/v1/wallets/"+uuid.NewString()+"/shards", map[string]any{"shardCount": 8})
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	rt = SetupRouter(Services{Wallet: ws, AdminToken: "secret"})
+	w = doReq(rt.r, http.MethodGet, "/api/v1/wallets", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doReq(rt.r, http.MethodPost, "/api/v1/operations/1/reverse", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doReq(rt.r, http.MethodPut, "/api/v1/wallets/"+uuid.NewString()+"/shards", map[string]any{"shardCount": 8})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	bs := service.NewBankStatementService(nil, nil, ws)
+	rt = SetupRouter(Services{Wallet: ws, BankStatements: bs})
+	w = doReq(rt.r, http.MethodGet, "/api/v1/bank-statements/exceptions", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	rt = SetupRouter(Services{Wallet: ws, BankStatements: bs, AdminToken: "secret"})
+	w = doReq(rt.r, http.MethodGet, "/api/v1/bank-statements/exceptions", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
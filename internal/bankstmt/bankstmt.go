@@ -0,0 +1,147 @@
+package bankstmt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Format string
+
+const (
+	CAMT053 Format = "camt053"
+	MT940   Format = "mt940"
+)
+
+type LineStatus string
+
+const (
+	// Matched lines were credited to WalletID on import.
+	Matched LineStatus = "MATCHED"
+	// Exception lines wait for someone to credit or ignore them.
+	Exception LineStatus = "EXCEPTION"
+	Resolved  LineStatus = "RESOLVED"
+	Ignored   LineStatus = "IGNORED"
+)
+
+// Line is one booked entry of a bank statement. Amount is in minor units
+// and always positive; Credit tells the direction. EntryRef identifies the
+// entry at the bank and is what makes re-importing a statement harmless.
+type Line struct {
+	ID           int64      `json:"id,omitempty"`
+	EntryRef     string     `json:"entryRef"`
+	Account      string     `json:"account"`
+	BookingDate  time.Time  `json:"bookingDate"`
+	Amount       int64      `json:"amount"`
+	Currency     string     `json:"currency"`
+	Credit       bool       `json:"credit"`
+	Reference    string     `json:"reference"`
+	Counterparty string     `json:"counterparty,omitempty"`
+	Status       LineStatus `json:"status,omitempty"`
+	WalletID     *uuid.UUID `json:"walletId,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	Note         string     `json:"note,omitempty"`
+}
+
+type Statement struct {
+	ID         uuid.UUID `json:"statementId"`
+	Format     Format    `json:"format"`
+	FileHash   string    `json:"fileHash"`
+	Lines      []Line    `json:"-"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+// Summary is what an import did with each line of the statement.
+type Summary struct {
+	StatementID uuid.UUID `json:"statementId"`
+	Lines       int       `json:"lines"`
+	Matched     int       `json:"matched"`
+	Exceptions  int       `json:"exceptions"`
+	Duplicates  int       `json:"duplicates"`
+}
+
+type Store interface {
+	// InsertBankStatement records st and returns its ID, or the ID of the
+	// statement imported earlier from the same file.
+	InsertBankStatement(ctx context.Context, st Statement) (uuid.UUID, error)
+	InsertStatementLine(ctx context.Context, statementId uuid.UUID, line Line) (int64, bool, error)
+	GetStatementLineForUpdate(ctx context.Context, id int64) (Line, error)
+	UpdateStatementLine(ctx context.Context, id int64, status LineStatus, walletId *uuid.UUID, reason, note string) error
+	ListStatementExceptions(ctx context.Context, afterId int64, limit int) ([]Line, error)
+}
+
+type TxRunner interface {
+	RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
+
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Detect guesses the format from the first non-blank bytes.
+func Detect(content []byte) (Format, error) {
+	s := strings.TrimSpace(string(content))
+	switch {
+	case strings.HasPrefix(s, "<"):
+		return CAMT053, nil
+	case strings.HasPrefix(s, "{1:"), strings.HasPrefix(s, ":20:"), strings.Contains(s, "\n:20:"):
+		return MT940, nil
+	}
+	return "", errors.New("unknown statement format")
+}
+
+func Parse(format Format, content []byte) ([]Line, error) {
+	switch format {
+	case CAMT053:
+		return ParseCAMT053(content)
+	case MT940:
+		return ParseMT940(content)
+	}
+	return nil, errors.New("unknown statement format")
+}
+
+// parseAmount turns a decimal amount with sep as decimal separator into
+// minor units, assuming two decimals.
+func parseAmount(s string, sep byte) (int64, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), string(sep))
+	if whole == "" || len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	var n int64
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		if n > (1<<63-1)/10-1 {
+			return 0, fmt.Errorf("amount out of range %q", s)
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return n, nil
+}
+
+// fallbackRefs identifies lines the bank gave no reference for by their
+// content alone, so the same line gets the same ref in every statement that
+// carries it. Identical lines within a file are told apart by how many of
+// them came before.
+type fallbackRefs map[string]int
+
+func (seen fallbackRefs) next(l Line) string {
+	key := fmt.Sprintf("%s|%s|%d|%s|%t|%s",
+		l.Account, l.BookingDate.Format("2006-01-02"), l.Amount, l.Currency, l.Credit, l.Reference)
+	seen[key]++
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+	return "sha256:" + hex.EncodeToString(sum[:16])
+}
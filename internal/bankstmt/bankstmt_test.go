@@ -0,0 +1,179 @@
+package bankstmt
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const camtSample = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG1</MsgId></GrpHdr>
+    <Stmt>
+      <Id>STMT-2025-10-01</Id>
+      <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id></Acct>
+      <Ntry>
+        <Amt Ccy="EUR">150.25</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2025-10-01</Dt></BookgDt>
+        <AcctSvcrRef>BANKREF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties><Dbtr><Pty><Nm>Jane Doe</Nm></Pty></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Top-up 7d444840-9dc0-11d1-b245-5ffdce74fad2</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">30</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2025-10-01T10:00:00</DtTm></BookgDt>
+        <AcctSvcrRef>BANKREF-2</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls><AmtDtls><TxAmt><Amt Ccy="EUR">10.00</Amt></TxAmt></AmtDtls><RmtInf><Ustrd>first</Ustrd></RmtInf></TxDtls>
+          <TxDtls><AmtDtls><TxAmt><Amt Ccy="EUR">20.00</Amt></TxAmt></AmtDtls><RmtInf><Ustrd>second</Ustrd></RmtInf></TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2025-10-01</Dt></BookgDt>
+        <AddtlNtryInf>Bank fee</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">99.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <BookgDt><Dt>2025-10-01</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+const mt940Sample = `{1:F01BANKDEFFAXXX0000000000}{2:O9401200251001BANKDEFFAXXX00000000002510011200N}{4:
+:20:STMT20251001
+:25:DE89370400440532013000
+:28C:00001/001
+:60F:C251001EUR1000,00
+:61:2510011001C150,25NTRFNONREF//BANKREF-1
+:86:166?00SEPA CREDIT?207d444840-9dc0-11d1-b245-5ff?21dce74fad2?32JANE DOE
+:61:2510011001D5,NCHGNONREF
+:86:Bank fee
+:62F:C251001EUR1145,25
+-}`
+
+func TestParseCAMT053(t *testing.T) {
+	lines, err := ParseCAMT053([]byte(camtSample))
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+
+	day := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, Line{
+		EntryRef: "DE89370400440532013000:BANKREF-1", Account: "DE89370400440532013000", BookingDate: day,
+		Amount: 15025, Currency: "EUR", Credit: true,
+		Reference: "Top-up 7d444840-9dc0-11d1-b245-5ffdce74fad2", Counterparty: "Jane Doe",
+	}, lines[0])
+
+	require.Equal(t, int64(1000), lines[1].Amount)
+	require.Equal(t, "DE89370400440532013000:BANKREF-2/1", lines[1].EntryRef)
+	require.Equal(t, "second", lines[2].Reference)
+	require.Equal(t, int64(2000), lines[2].Amount)
+
+	require.False(t, lines[3].Credit)
+	require.Equal(t, "Bank fee", lines[3].Reference)
+	require.Contains(t, lines[3].EntryRef, "sha256:")
+}
+
+func TestParseMT940(t *testing.T) {
+	lines, err := ParseMT940([]byte(mt940Sample))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	require.Equal(t, "DE89370400440532013000:BANKREF-1", lines[0].EntryRef)
+	require.Equal(t, int64(15025), lines[0].Amount)
+	require.Equal(t, "EUR", lines[0].Currency)
+	require.True(t, lines[0].Credit)
+	require.Equal(t, "JANE DOE", lines[0].Counterparty)
+	require.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), lines[0].BookingDate)
+
+	id, ok := MatchWallet(lines[0].Reference)
+	require.True(t, ok)
+	require.Equal(t, uuid.MustParse("7d444840-9dc0-11d1-b245-5ffdce74fad2"), id)
+
+	require.False(t, lines[1].Credit)
+	require.Equal(t, int64(500), lines[1].Amount)
+	require.Equal(t, "Bank fee", lines[1].Reference)
+}
+
+func TestParseMT940_FallbackRefIgnoresPosition(t *testing.T) {
+	lines, err := ParseMT940([]byte(mt940Sample))
+	require.NoError(t, err)
+
+	overlapping := strings.Replace(mt940Sample, ":61:2510011001D5,NCHGNONREF",
+		":61:2510011001D5,NCHGNONREF\n:86:Bank fee\n:61:2510011001C7,NTRFNONREF\n:86:Cash\n:61:2510011001D5,NCHGNONREF", 1)
+	again, err := ParseMT940([]byte(overlapping))
+	require.NoError(t, err)
+	require.Len(t, again, 4)
+
+	require.Equal(t, lines[1].EntryRef, again[1].EntryRef)
+	require.NotEqual(t, again[1].EntryRef, again[3].EntryRef)
+	require.NotEqual(t, again[1].EntryRef, again[2].EntryRef)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := ParseCAMT053([]byte("<Document><BkToCstmrStmt/></Document>"))
+	require.EqualError(t, err, "invalid camt.053: no statements")
+
+	_, err = ParseMT940([]byte(":20:X\n:25:ACC\n:60F:C251001EUR0,00\n:61:25100C1,5NTRF\n"))
+	require.ErrorContains(t, err, "invalid :61: field")
+
+	_, err = Detect([]byte("walletId,amount"))
+	require.EqualError(t, err, "unknown statement format")
+}
+
+func TestDetect(t *testing.T) {
+	f, err := Detect([]byte(camtSample))
+	require.NoError(t, err)
+	require.Equal(t, CAMT053, f)
+
+	f, err = Detect([]byte(mt940Sample))
+	require.NoError(t, err)
+	require.Equal(t, MT940, f)
+}
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]int64{"1": 100, "1.5": 150, "0.01": 1, "12.34": 1234} {
+		got, err := parseAmount(in, '.')
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "0", "1.234", "-1", "1,5", "99999999999999999999"} {
+		_, err := parseAmount(in, '.')
+		require.Error(t, err, in)
+	}
+}
+
+func TestMatchWallet(t *testing.T) {
+	id := uuid.New()
+
+	got, ok := MatchWallet("wallet " + id.String())
+	require.True(t, ok)
+	require.Equal(t, id, got)
+
+	compact := id.String()
+	got, ok = MatchWallet("REF " + compact[:20] + "\n" + compact[20:])
+	require.True(t, ok)
+	require.Equal(t, id, got)
+
+	_, ok = MatchWallet(id.String() + " " + uuid.New().String())
+	require.False(t, ok)
+
+	_, ok = MatchWallet("invoice 42")
+	require.False(t, ok)
+}
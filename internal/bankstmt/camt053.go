@@ -0,0 +1,184 @@
+package bankstmt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID      string      `xml:"Id"`
+	IBAN    string      `xml:"Acct>Id>IBAN"`
+	Other   string      `xml:"Acct>Id>Othr>Id"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtEntry struct {
+	Amount      camtAmount   `xml:"Amt"`
+	Indicator   string       `xml:"CdtDbtInd"`
+	Status      camtStatus   `xml:"Sts"`
+	BookingDate string       `xml:"BookgDt>Dt"`
+	BookingTime string       `xml:"BookgDt>DtTm"`
+	Ref         string       `xml:"AcctSvcrRef"`
+	Details     []camtDetail `xml:"NtryDtls>TxDtls"`
+	Info        string       `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is a plain code up to camt.053.001.04 and a <Cd> element
+// from .05 on.
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDetail struct {
+	Amount     camtAmount `xml:"Amt"`
+	TxAmount   camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	Ref        string     `xml:"Refs>AcctSvcrRef"`
+	EndToEndID string     `xml:"Refs>EndToEndId"`
+	Unstruct   []string   `xml:"RmtInf>Ustrd"`
+	Creditor   []string   `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Debtor     string     `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty  string     `xml:"RltdPties>Dbtr>Pty>Nm"`
+}
+
+// ParseCAMT053 reads the booked entries of every statement in the file.
+// An entry that batches several transactions yields one line per
+// transaction, each with its own amount.
+func ParseCAMT053(content []byte) ([]Line, error) {
+	var doc camtDocument
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, errors.New("invalid camt.053: no statements")
+	}
+
+	var lines []Line
+	refs := fallbackRefs{}
+	for s, st := range doc.Statements {
+		account := st.IBAN
+		if account == "" {
+			account = st.Other
+		}
+
+		for e, entry := range st.Entries {
+			status := strings.TrimSpace(entry.Status.Code)
+			if status == "" {
+				status = strings.TrimSpace(entry.Status.Value)
+			}
+			if status != "BOOK" {
+				continue
+			}
+
+			pos := fmt.Sprintf("statement %d entry %d", s+1, e+1)
+			base, err := camtBase(account, entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid camt.053: %s: %w", pos, err)
+			}
+
+			if len(entry.Details) <= 1 {
+				line := base
+				line.Amount, err = parseAmount(entry.Amount.Value, '.')
+				if err != nil {
+					return nil, fmt.Errorf("invalid camt.053: %s: %w", pos, err)
+				}
+				if len(entry.Details) == 1 {
+					camtApplyDetail(&line, entry.Details[0])
+				}
+				if line.EntryRef == "" {
+					line.EntryRef = refs.next(line)
+				}
+				lines = append(lines, line)
+				continue
+			}
+
+			for d, detail := range entry.Details {
+				line := base
+				amount := detail.TxAmount
+				if amount.Value == "" {
+					amount = detail.Amount
+				}
+				if line.Amount, err = parseAmount(amount.Value, '.'); err != nil {
+					return nil, fmt.Errorf("invalid camt.053: %s transaction %d: %w", pos, d+1, err)
+				}
+				if amount.Currency != "" {
+					line.Currency = amount.Currency
+				}
+				camtApplyDetail(&line, detail)
+				switch {
+				case detail.Ref != "":
+					line.EntryRef = account + ":" + detail.Ref
+				case base.EntryRef != "":
+					line.EntryRef = base.EntryRef + "/" + strconv.Itoa(d+1)
+				default:
+					line.EntryRef = refs.next(line)
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, nil
+}
+
+func camtBase(account string, entry camtEntry) (Line, error) {
+	line := Line{Account: account, Currency: entry.Amount.Currency, Reference: strings.TrimSpace(entry.Info)}
+
+	switch entry.Indicator {
+	case "CRDT":
+		line.Credit = true
+	case "DBIT":
+	default:
+		return Line{}, fmt.Errorf("invalid CdtDbtInd %q", entry.Indicator)
+	}
+
+	date := entry.BookingDate
+	if date == "" && len(entry.BookingTime) >= 10 {
+		date = entry.BookingTime[:10]
+	}
+	booked, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid booking date %q", date)
+	}
+	line.BookingDate = booked
+
+	if entry.Ref != "" {
+		line.EntryRef = account + ":" + entry.Ref
+	}
+	return line, nil
+}
+
+func camtApplyDetail(line *Line, d camtDetail) {
+	var refs []string
+	refs = append(refs, d.Creditor...)
+	refs = append(refs, d.Unstruct...)
+	if d.EndToEndID != "" && d.EndToEndID != "NOTPROVIDED" {
+		refs = append(refs, d.EndToEndID)
+	}
+	if line.Reference != "" {
+		refs = append(refs, line.Reference)
+	}
+	line.Reference = strings.TrimSpace(strings.Join(refs, " "))
+
+	line.Counterparty = d.Debtor
+	if line.Counterparty == "" {
+		line.Counterparty = d.DebtorPty
+	}
+	if d.Ref != "" && line.EntryRef == "" {
+		line.EntryRef = line.Account + ":" + d.Ref
+	}
+}
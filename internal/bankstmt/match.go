@@ -0,0 +1,34 @@
+package bankstmt
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var uuidPattern = regexp.MustCompile(`(?i)[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}`)
+
+// MatchWallet finds the wallet a credit is meant for: customers are asked
+// to put their wallet ID in the transfer reference. Banks may break the
+// reference across lines or drop the dashes, so both forms are accepted.
+// A reference naming more than one wallet is ambiguous and does not match.
+func MatchWallet(reference string) (uuid.UUID, bool) {
+	var found uuid.UUID
+	for _, text := range []string{reference, strings.Join(strings.Fields(reference), "")} {
+		for _, m := range uuidPattern.FindAllString(text, -1) {
+			id, err := uuid.Parse(m)
+			if err != nil || id == uuid.Nil {
+				continue
+			}
+			if found != uuid.Nil && found != id {
+				return uuid.Nil, false
+			}
+			found = id
+		}
+		if found != uuid.Nil {
+			return found, true
+		}
+	}
+	return uuid.Nil, false
+}
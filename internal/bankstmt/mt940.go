@@ -0,0 +1,173 @@
+package bankstmt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// :61:YYMMDD[MMDD](C|D|RC|RD)[funds code]amount(N|F|S)type customer-ref[//bank-ref][\nsupplementary]
+var mt940Line = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})[NFS][A-Z0-9]{3}([^/\n]*?)(?://([^\n]*))?(?:\n(.*))?$`)
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 reads the :61: statement lines of every statement in the file,
+// taking each line's reference from the :86: field that follows it.
+// Reversals (RC, RD) flip the direction of the original booking.
+func ParseMT940(content []byte) ([]Line, error) {
+	fields, err := mt940Fields(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lines    []Line
+		account  string
+		currency string
+		current  *Line
+		bankRef  string
+		refs     = fallbackRefs{}
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if bankRef != "" && bankRef != "NONREF" {
+			current.EntryRef = current.Account + ":" + bankRef
+		} else {
+			current.EntryRef = refs.next(*current)
+		}
+		lines = append(lines, *current)
+		current, bankRef = nil, ""
+	}
+
+	for _, f := range fields {
+		switch f.tag {
+		case "20":
+			flush()
+			account, currency = "", ""
+		case "25":
+			account = strings.TrimSpace(f.value)
+		case "60F", "60M":
+			// C251001EUR1000,00: the currency follows the mark and date.
+			if len(f.value) < 10 {
+				return nil, fmt.Errorf("invalid mt940: invalid :%s: field", f.tag)
+			}
+			currency = f.value[7:10]
+		case "61":
+			flush()
+			line, ref, err := mt940StatementLine(f.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mt940: %w", err)
+			}
+			line.Account, line.Currency = account, currency
+			current, bankRef = &line, ref
+		case "86":
+			if current != nil {
+				current.Reference = strings.TrimSpace(strings.Join([]string{current.Reference, mt940Text(f.value)}, " "))
+				if name := mt940Subfield(f.value, "32"); name != "" {
+					current.Counterparty = name
+				}
+			}
+		case "62F", "62M":
+			flush()
+		}
+	}
+	flush()
+
+	if len(lines) == 0 && len(fields) == 0 {
+		return nil, errors.New("invalid mt940: no statements")
+	}
+	return lines, nil
+}
+
+func mt940Fields(content []byte) ([]mt940Field, error) {
+	var fields []mt940Field
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		text := strings.TrimRight(sc.Text(), "\r")
+		switch {
+		case text == "" || text == "-" || text == "-}" || strings.HasPrefix(text, "{"):
+			// Blocks 1-3 of the SWIFT envelope and statement separators.
+			if i := strings.Index(text, "{4:"); i >= 0 && strings.HasPrefix(text[i+3:], ":") {
+				fields = append(fields, mt940Split(text[i+3:]))
+			}
+		case strings.HasPrefix(text, ":") && strings.Index(text[1:], ":") > 0:
+			fields = append(fields, mt940Split(text))
+		default:
+			if len(fields) == 0 {
+				return nil, errors.New("invalid mt940: text before the first field")
+			}
+			fields[len(fields)-1].value += "\n" + text
+		}
+	}
+	return fields, sc.Err()
+}
+
+func mt940Split(text string) mt940Field {
+	end := strings.Index(text[1:], ":") + 1
+	return mt940Field{tag: text[1:end], value: text[end+1:]}
+}
+
+func mt940StatementLine(value string) (Line, string, error) {
+	m := mt940Line.FindStringSubmatch(value)
+	if m == nil {
+		return Line{}, "", fmt.Errorf("invalid :61: field %q", value)
+	}
+
+	booked, err := time.Parse("060102", m[1])
+	if err != nil {
+		return Line{}, "", fmt.Errorf("invalid :61: date %q", m[1])
+	}
+	amount, err := parseAmount(m[5], ',')
+	if err != nil {
+		return Line{}, "", fmt.Errorf("invalid :61: field: %w", err)
+	}
+
+	line := Line{
+		BookingDate: booked,
+		Amount:      amount,
+		Credit:      m[3] == "C" || m[3] == "RD",
+	}
+	if ref := strings.TrimSpace(m[6]); ref != "" && ref != "NONREF" {
+		line.Reference = ref
+	}
+	if extra := strings.TrimSpace(m[8]); extra != "" {
+		line.Reference = strings.TrimSpace(line.Reference + " " + extra)
+	}
+	return line, strings.TrimSpace(m[7]), nil
+}
+
+// mt940Text flattens a :86: field. Structured fields (?20 to ?29 hold the
+// remittance text) are joined without separators, because banks wrap
+// long references across subfields.
+func mt940Text(value string) string {
+	if !strings.Contains(value, "?") {
+		return strings.Join(strings.Fields(value), " ")
+	}
+	var b strings.Builder
+	for i := 20; i <= 29; i++ {
+		b.WriteString(mt940Subfield(value, fmt.Sprint(i)))
+	}
+	if b.Len() == 0 {
+		return strings.Join(strings.Fields(value), " ")
+	}
+	return b.String()
+}
+
+func mt940Subfield(value, code string) string {
+	flat := strings.ReplaceAll(value, "\n", "")
+	for _, part := range strings.Split(flat, "?")[1:] {
+		if strings.HasPrefix(part, code) {
+			return strings.TrimSpace(part[len(code):])
+		}
+	}
+	return ""
+}
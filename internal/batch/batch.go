@@ -0,0 +1,73 @@
+package batch
+
+import (
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Mode string
+
+const (
+	// Atomic applies every operation in one transaction: all or nothing.
+	Atomic Mode = "ATOMIC"
+	// BestEffort applies each operation in its own transaction.
+	BestEffort Mode = "BEST_EFFORT"
+)
+
+type Status string
+
+const (
+	Processing Status = "PROCESSING"
+	Completed  Status = "COMPLETED"
+	Partial    Status = "PARTIAL"
+	Failed     Status = "FAILED"
+)
+
+type ItemStatus string
+
+const (
+	ItemSucceeded  ItemStatus = "SUCCEEDED"
+	ItemFailed     ItemStatus = "FAILED"
+	ItemRolledBack ItemStatus = "ROLLED_BACK"
+)
+
+type Operation struct {
+	WalletID      uuid.UUID            `json:"walletId"`
+	OperationType ledger.OperationType `json:"operationType"`
+	Amount        int64                `json:"amount"`
+	// Fee is what a withdrawal is charged on top of Amount. The service
+	// prices it; it is never taken from the request.
+	Fee ledger.FeeCharge `json:"-"`
+}
+
+type Item struct {
+	Index int `json:"index"`
+	Operation
+	Status        ItemStatus `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	LedgerEntryID int64      `json:"ledgerEntryId,omitempty"`
+}
+
+type Batch struct {
+	ID        uuid.UUID `json:"batchId"`
+	Mode      Mode      `json:"mode"`
+	Status    Status    `json:"status"`
+	Items     []Item    `json:"items"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func New(mode Mode, ops []Operation) Batch {
+	items := make([]Item, len(ops))
+	for i, op := range ops {
+		items[i] = Item{Index: i, Operation: op}
+	}
+	return Batch{
+		ID:        uuid.New(),
+		Mode:      mode,
+		Status:    Processing,
+		Items:     items,
+		CreatedAt: time.Now(),
+	}
+}
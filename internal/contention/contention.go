@@ -0,0 +1,18 @@
+// Package contention describes failures caused by other sessions holding
+// the rows an operation needs, which clients should retry later.
+package contention
+
+import "time"
+
+type BusyError struct {
+	RetryAfter time.Duration
+	Cause      error
+}
+
+func (e *BusyError) Error() string {
+	return "wallet busy"
+}
+
+func (e *BusyError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	Pending   Status = "PENDING"
+	Running   Status = "RUNNING"
+	Completed Status = "COMPLETED"
+	Failed    Status = "FAILED"
+)
+
+type Row struct {
+	Line          int
+	WalletID      uuid.UUID
+	OperationType ledger.OperationType
+	Amount        int64
+	ExternalRef   string
+	// Fee is charged on top of a withdrawal. It is priced by the runner,
+	// not read from the file.
+	Fee ledger.FeeCharge
+}
+
+type Job struct {
+	ID            uuid.UUID `json:"jobId"`
+	FileHash      string    `json:"fileHash"`
+	Status        Status    `json:"status"`
+	TotalRows     int       `json:"totalRows"`
+	ProcessedRows int       `json:"processedRows"`
+	SkippedRows   int       `json:"skippedRows"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Errors []RowError
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("csv validation failed: %d invalid rows", len(e.Errors))
+}
+
+type Store interface {
+	InsertImportJob(ctx context.Context, job Job, content []byte) error
+	GetImportJob(ctx context.Context, id uuid.UUID) (Job, error)
+	GetImportJobByHash(ctx context.Context, fileHash string) (Job, error)
+	ClaimImportJob(ctx context.Context, id uuid.UUID, staleAfter time.Duration) (Job, []byte, error)
+	RestartImportJob(ctx context.Context, id uuid.UUID) (Job, error)
+	ListRunnableImportJobs(ctx context.Context, staleAfter time.Duration, limit int) ([]uuid.UUID, error)
+	ApplyImportChunk(ctx context.Context, jobId uuid.UUID, offset int, rows []Row, maxBalance int64) error
+	FinishImportJob(ctx context.Context, jobId uuid.UUID, status Status, cause string) error
+}
+
+// FeeFunc prices a withdrawal of amount from walletId.
+type FeeFunc func(ctx context.Context, walletId uuid.UUID, amount int64) (ledger.FeeCharge, error)
+
+type TxRunner interface {
+	RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
+
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func NewJob(fileHash string, totalRows int) Job {
+	now := time.Now()
+	return Job{
+		ID:        uuid.New(),
+		FileHash:  fileHash,
+		Status:    Pending,
+		TotalRows: totalRows,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
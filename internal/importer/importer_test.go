@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/money"
+)
+
+func TestParse_Valid(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	csv := "wallet_id,type,amount,external_ref\n" +
+		a.String() + ",DEPOSIT,100,ref-1\n" +
+		b.String() + ",withdraw,25,ref-2\n"
+
+	rows, err := Parse(strings.NewReader(csv), money.Limits{})
+
+	require.NoError(t, err)
+	require.Equal(t, []Row{
+		{Line: 2, WalletID: a, OperationType: ledger.Deposit, Amount: 100, ExternalRef: "ref-1"},
+		{Line: 3, WalletID: b, OperationType: ledger.Withdraw, Amount: 25, ExternalRef: "ref-2"},
+	}, rows)
+}
+
+func TestParse_ColumnOrderFollowsHeader(t *testing.T) {
+	a := uuid.New()
+	csv := "external_ref,amount,type,wallet_id\nref-1,7,DEPOSIT," + a.String() + "\n"
+
+	rows, err := Parse(strings.NewReader(csv), money.Limits{})
+
+	require.NoError(t, err)
+	require.Equal(t, a, rows[0].WalletID)
+	require.Equal(t, int64(7), rows[0].Amount)
+}
+
+func TestParse_ReportsEveryInvalidRow(t *testing.T) {
+	a := uuid.New().String()
+	csv := "wallet_id,type,amount,external_ref\n" +
+		"nope,DEPOSIT,1,r1\n" +
+		a + ",MOVE,1,r2\n" +
+		a + ",DEPOSIT,1.5,r3\n" +
+		a + ",DEPOSIT,-4,r4\n" +
+		a + ",DEPOSIT,4,\n" +
+		a + ",DEPOSIT,4,r6\n" +
+		a + ",DEPOSIT,4,r6\n" +
+		a + ",DEPOSIT\n"
+
+	_, err := Parse(strings.NewReader(csv), money.Limits{})
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []RowError{
+		{Line: 2, Message: "invalid wallet_id"},
+		{Line: 3, Message: "invalid type"},
+		{Line: 4, Message: "amount must be an integer"},
+		{Line: 5, Message: "amount must be positive"},
+		{Line: 6, Message: "external_ref is required"},
+		{Line: 8, Message: "duplicate external_ref, first seen on line 7"},
+		{Line: 9, Message: "record on line 9: wrong number of fields"},
+	}, validationErr.Errors)
+}
+
+func TestParse_AppliesLimits(t *testing.T) {
+	a := uuid.New().String()
+	csv := "wallet_id,type,amount,external_ref\n" +
+		a + ",DEPOSIT,5,r1\n" +
+		a + ",DEPOSIT,2000,r2\n" +
+		a + ",WITHDRAW,600,r3\n" +
+		a + ",WITHDRAW,50,r4\n"
+	limits := money.Limits{
+		Deposit:    money.Bounds{Min: 10},
+		Withdraw:   money.Bounds{Max: 500},
+		MaxBalance: 1000,
+	}
+
+	_, err := Parse(strings.NewReader(csv), limits)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []RowError{
+		{Line: 2, Message: "amount must be at least 10"},
+		{Line: 3, Message: "balance limit exceeded"},
+		{Line: 4, Message: "amount must be at most 500"},
+	}, validationErr.Errors)
+}
+
+func TestParse_MissingColumn(t *testing.T) {
+	_, err := Parse(strings.NewReader("wallet_id,type,amount\n"), money.Limits{})
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, `missing column "external_ref"`, validationErr.Errors[0].Message)
+}
+
+type fakeTx struct{}
+
+func (fakeTx) RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeStore struct {
+	Store
+
+	job      Job
+	content  []byte
+	offsets  []int
+	sizes    []int
+	failAt   int
+	finished Status
+	cause    string
+	fees     []ledger.FeeCharge
+	maxBal   int64
+}
+
+func (f *fakeStore) ClaimImportJob(ctx context.Context, id uuid.UUID, staleAfter time.Duration) (Job, []byte, error) {
+	return f.job, f.content, nil
+}
+
+func (f *fakeStore) ApplyImportChunk(ctx context.Context, jobId uuid.UUID, offset int, rows []Row, maxBalance int64) error {
+	if f.failAt >= 0 && offset == f.failAt {
+		return errors.New("not enough balance")
+	}
+	f.offsets = append(f.offsets, offset)
+	f.maxBal = maxBalance
+	f.sizes = append(f.sizes, len(rows))
+	for _, row := range rows {
+		f.fees = append(f.fees, row.Fee)
+	}
+	return nil
+}
+
+func (f *fakeStore) FinishImportJob(ctx context.Context, jobId uuid.UUID, status Status, cause string) error {
+	f.finished = status
+	f.cause = cause
+	return nil
+}
+
+func csvWithRows(n int) []byte {
+	var sb strings.Builder
+	sb.WriteString("wallet_id,type,amount,external_ref\n")
+	id := uuid.New().String()
+	for i := 0; i < n; i++ {
+		sb.WriteString(id + ",DEPOSIT,1,ref-" + uuid.New().String() + "\n")
+	}
+	return []byte(sb.String())
+}
+
+func TestRunner_AppliesInChunksFromSavedOffset(t *testing.T) {
+	store := &fakeStore{
+		job:     Job{ID: uuid.New(), ProcessedRows: 2},
+		content: csvWithRows(7),
+		failAt:  -1,
+	}
+	r := NewRunner(fakeTx{}, store, 2, time.Second).WithLimits(money.Limits{MaxBalance: 1000})
+
+	r.Process(context.Background(), store.job.ID)
+
+	require.Equal(t, []int{2, 4, 6}, store.offsets)
+	require.Equal(t, []int{2, 2, 1}, store.sizes)
+	require.Equal(t, int64(1000), store.maxBal)
+	require.Equal(t, Completed, store.finished)
+}
+
+func TestRunner_FailsJobOnChunkError(t *testing.T) {
+	store := &fakeStore{
+		job:     Job{ID: uuid.New()},
+		content: csvWithRows(5),
+		failAt:  2,
+	}
+	r := NewRunner(fakeTx{}, store, 2, time.Second)
+
+	r.Process(context.Background(), store.job.ID)
+
+	require.Equal(t, []int{0}, store.offsets)
+	require.Equal(t, Failed, store.finished)
+	require.Equal(t, "not enough balance", store.cause)
+}
+
+func TestRunner_PricesWithdrawals(t *testing.T) {
+	revenue := uuid.New()
+	store := &fakeStore{
+		job:     Job{ID: uuid.New()},
+		content: []byte("wallet_id,type,amount,external_ref\n" + uuid.New().String() + ",DEPOSIT,100,a\n" + uuid.New().String() + ",WITHDRAW,40,b\n"),
+		failAt:  -1,
+	}
+	r := NewRunner(fakeTx{}, store, 10, time.Second).WithFees(func(ctx context.Context, walletId uuid.UUID, amount int64) (ledger.FeeCharge, error) {
+		return ledger.FeeCharge{Amount: amount / 10, Revenue: revenue}, nil
+	})
+
+	r.Process(context.Background(), store.job.ID)
+
+	require.Equal(t, Completed, store.finished)
+	require.Equal(t, []ledger.FeeCharge{{}, {Amount: 4, Revenue: revenue}}, store.fees)
+}
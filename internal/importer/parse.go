@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"project/internal/ledger"
+	"project/internal/money"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const maxRowErrors = 100
+
+var columns = []string{"wallet_id", "type", "amount", "external_ref"}
+
+// Parse reads and validates the whole file before anything is applied,
+// holding each amount to the limits an API deposit or withdrawal of it would
+// face. It returns a *ValidationError listing (up to maxRowErrors) invalid
+// rows.
+func Parse(r io.Reader, limits money.Limits) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &ValidationError{Errors: []RowError{{Line: 1, Message: "file is empty"}}}
+		}
+		return nil, &ValidationError{Errors: []RowError{{Line: 1, Message: err.Error()}}}
+	}
+
+	index, err := columnIndex(header)
+	if err != nil {
+		return nil, &ValidationError{Errors: []RowError{{Line: 1, Message: err.Error()}}}
+	}
+
+	var (
+		rows    []Row
+		rowErrs []RowError
+		refs    = make(map[string]int)
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			line := 0
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			rowErrs = append(rowErrs, RowError{Line: line, Message: err.Error()})
+			if len(rowErrs) >= maxRowErrors {
+				break
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		row, err := parseRow(record, index, limits)
+		if err == nil {
+			if first, dup := refs[row.ExternalRef]; dup {
+				err = fmt.Errorf("duplicate external_ref, first seen on line %d", first)
+			} else {
+				refs[row.ExternalRef] = line
+			}
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: line, Message: err.Error()})
+			if len(rowErrs) >= maxRowErrors {
+				break
+			}
+			continue
+		}
+
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	if len(rowErrs) > 0 {
+		return nil, &ValidationError{Errors: rowErrs}
+	}
+	if len(rows) == 0 {
+		return nil, &ValidationError{Errors: []RowError{{Line: 2, Message: "file has no rows"}}}
+	}
+	return rows, nil
+}
+
+func columnIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return index, nil
+}
+
+func parseRow(record []string, index map[string]int, limits money.Limits) (Row, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[index[name]])
+	}
+
+	walletId, err := uuid.Parse(field("wallet_id"))
+	if err != nil || walletId == uuid.Nil {
+		return Row{}, errors.New("invalid wallet_id")
+	}
+
+	opType := ledger.OperationType(strings.ToUpper(field("type")))
+	if opType != ledger.Deposit && opType != ledger.Withdraw {
+		return Row{}, errors.New("invalid type")
+	}
+
+	amount, err := strconv.ParseInt(field("amount"), 10, 64)
+	if err != nil {
+		return Row{}, errors.New("amount must be an integer")
+	}
+	check := limits.CheckDeposit
+	if opType == ledger.Withdraw {
+		check = limits.CheckWithdraw
+	}
+	if err := check(amount); err != nil {
+		return Row{}, err
+	}
+
+	ref := field("external_ref")
+	if ref == "" {
+		return Row{}, errors.New("external_ref is required")
+	}
+
+	return Row{
+		WalletID:      walletId,
+		OperationType: opType,
+		Amount:        amount,
+		ExternalRef:   ref,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"project/internal/ledger"
+	"project/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A RUNNING job whose progress has not moved for staleAfter is assumed to
+// belong to a crashed process and may be claimed again.
+const staleAfter = 5 * time.Minute
+
+type Runner struct {
+	tx        TxRunner
+	store     Store
+	chunkSize int
+	interval  time.Duration
+	queue     chan uuid.UUID
+	fees      FeeFunc
+	limits    money.Limits
+}
+
+func NewRunner(tx TxRunner, store Store, chunkSize int, interval time.Duration) *Runner {
+	return &Runner{
+		tx:        tx,
+		store:     store,
+		chunkSize: chunkSize,
+		interval:  interval,
+		queue:     make(chan uuid.UUID, 64),
+	}
+}
+
+// WithFees charges withdrawals the fee that fees prices them at, as
+// WithdrawFunds would.
+func (r *Runner) WithFees(fees FeeFunc) *Runner {
+	r.fees = fees
+	return r
+}
+
+// WithLimits validates the rows of a job against limits again when it runs,
+// as they may have changed since it was submitted.
+func (r *Runner) WithLimits(limits money.Limits) *Runner {
+	r.limits = limits
+	return r
+}
+
+// Enqueue schedules a job for immediate processing. If the queue is full the
+// job is still picked up by the next poll.
+func (r *Runner) Enqueue(jobId uuid.UUID) {
+	select {
+	case r.queue <- jobId:
+	default:
+	}
+}
+
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case jobId := <-r.queue:
+			r.Process(ctx, jobId)
+		case <-ticker.C:
+			ids, err := r.store.ListRunnableImportJobs(ctx, staleAfter, 10)
+			if err != nil {
+				log.Printf("import runner: %v", err)
+				continue
+			}
+			for _, id := range ids {
+				r.Process(ctx, id)
+			}
+		}
+	}
+}
+
+func (r *Runner) Process(ctx context.Context, jobId uuid.UUID) {
+	job, content, err := r.store.ClaimImportJob(ctx, jobId, staleAfter)
+	if err != nil {
+		if err.Error() != "import job not claimable" {
+			log.Printf("import runner: claim %s: %v", jobId, err)
+		}
+		return
+	}
+
+	if err := r.apply(ctx, job, content); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("import runner: job %s failed: %v", jobId, err)
+		if err := r.store.FinishImportJob(ctx, jobId, Failed, err.Error()); err != nil {
+			log.Printf("import runner: job %s: %v", jobId, err)
+		}
+		return
+	}
+
+	if err := r.store.FinishImportJob(ctx, jobId, Completed, ""); err != nil {
+		log.Printf("import runner: job %s: %v", jobId, err)
+	}
+}
+
+func (r *Runner) apply(ctx context.Context, job Job, content []byte) error {
+	rows, err := Parse(bytes.NewReader(content), r.limits)
+	if err != nil {
+		return err
+	}
+
+	for offset := job.ProcessedRows; offset < len(rows); offset += r.chunkSize {
+		end := offset + r.chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		chunk := rows[offset:end]
+
+		if err := r.price(ctx, chunk); err != nil {
+			return err
+		}
+		if err := r.tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+			return r.store.ApplyImportChunk(ctxTx, job.ID, offset, chunk, r.limits.MaxBalance)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// price is done outside the chunk's transaction: quoting reads the monthly
+// volume in a transaction of its own.
+func (r *Runner) price(ctx context.Context, rows []Row) error {
+	if r.fees == nil {
+		return nil
+	}
+	for i := range rows {
+		if rows[i].OperationType != ledger.Withdraw {
+			continue
+		}
+		fee, err := r.fees(ctx, rows[i].WalletID, rows[i].Amount)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", rows[i].Line, err)
+		}
+		rows[i].Fee = fee
+	}
+	return nil
+}
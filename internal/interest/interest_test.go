@@ -0,0 +1,44 @@
+package interest
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAccrue(t *testing.T) {
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+
+	// 100000 at 3.65% a year is exactly 10 a day.
+	a := Accrue(Input{Balance: 100000, RateBps: 365}, day)
+	require.Equal(t, int64(10), a.Amount)
+	require.Zero(t, a.Remainder)
+
+	// 1000 at 5% earns 50/365 a day: nothing whole on day one, the fraction
+	// carried until it adds up to a minor unit.
+	carry := int64(0)
+	var paid int64
+	for i := 0; i < 365; i++ {
+		a = Accrue(Input{Balance: 1000, RateBps: 500, Carry: carry}, day)
+		paid += a.Amount
+		carry = a.Remainder
+		require.Less(t, carry, int64(Denominator))
+	}
+	require.Equal(t, int64(50), paid)
+	require.Zero(t, carry)
+
+	a = Accrue(Input{Balance: -500, RateBps: 500, Carry: 42}, day)
+	require.Zero(t, a.Amount)
+	require.Equal(t, int64(42), a.Remainder)
+
+	a = Accrue(Input{Balance: math.MaxInt64, RateBps: 10000, Carry: Denominator - 1}, day)
+	require.Equal(t, int64(math.MaxInt64/365+1), a.Amount, "the carry tips the largest balance over")
+}
+
+func TestPeriods(t *testing.T) {
+	at := time.Date(2024, 2, 17, 23, 30, 0, 0, time.FixedZone("X", -2*3600))
+	require.Equal(t, time.Date(2024, 2, 18, 0, 0, 0, 0, time.UTC), Day(at))
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Month(at))
+	require.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), LastDay(at))
+}
@@ -0,0 +1,99 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MainLane is the lane of every entry written under the wallets row lock.
+// A sharded deposit is written under its shard's row lock instead, so its
+// lane is the shard number.
+const MainLane = -1
+
+// Lane is the lane the entry was written in. Entry ids follow commit order
+// within a lane, not across lanes of the same wallet.
+func (e Entry) Lane() int {
+	if e.Shard == nil {
+		return MainLane
+	}
+	return *e.Shard
+}
+
+// Cursor is how far a reader got through a wallet's ledger: the last id seen
+// in each lane, and Floor for every lane it holds no id for.
+type Cursor struct {
+	Floor int64
+	Lanes map[int]int64
+}
+
+// After is the id the next entry of lane must exceed.
+func (c Cursor) After(lane int) int64 {
+	if id, ok := c.Lanes[lane]; ok && id > c.Floor {
+		return id
+	}
+	return c.Floor
+}
+
+// Advance records entry as seen.
+func (c *Cursor) Advance(entry Entry) {
+	if entry.ID <= c.After(entry.Lane()) {
+		return
+	}
+	if c.Lanes == nil {
+		c.Lanes = make(map[int]int64)
+	}
+	c.Lanes[entry.Lane()] = entry.ID
+}
+
+// String encodes the cursor as the floor followed by "lane:id" for every
+// lane past it, e.g. "0,-1:120,3:118". A plain id is a cursor with no lanes.
+func (c Cursor) String() string {
+	lanes := make([]int, 0, len(c.Lanes))
+	for lane, id := range c.Lanes {
+		if id > c.Floor {
+			lanes = append(lanes, lane)
+		}
+	}
+	sort.Ints(lanes)
+
+	var b strings.Builder
+	b.WriteString(strconv.FormatInt(c.Floor, 10))
+	for _, lane := range lanes {
+		fmt.Fprintf(&b, ",%d:%d", lane, c.Lanes[lane])
+	}
+	return b.String()
+}
+
+// ParseCursor reads a cursor written by String.
+func ParseCursor(s string) (Cursor, error) {
+	invalid := errors.New("invalid cursor")
+
+	parts := strings.Split(s, ",")
+	floor, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || floor < 0 {
+		return Cursor{}, invalid
+	}
+	c := Cursor{Floor: floor}
+	for _, part := range parts[1:] {
+		laneStr, idStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return Cursor{}, invalid
+		}
+		lane, err := strconv.Atoi(laneStr)
+		if err != nil || lane < MainLane {
+			return Cursor{}, invalid
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id < 0 {
+			return Cursor{}, invalid
+		}
+		if c.Lanes == nil {
+			c.Lanes = make(map[int]int64)
+		}
+		c.Lanes[lane] = id
+	}
+	return c, nil
+}
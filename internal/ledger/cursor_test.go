@@ -0,0 +1,35 @@
+package ledger
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	shard := 3
+	c := Cursor{Floor: 10}
+	c.Advance(Entry{ID: 12})
+	c.Advance(Entry{ID: 15, Shard: &shard})
+	c.Advance(Entry{ID: 14, Shard: &shard})
+	c.Advance(Entry{ID: 9})
+
+	require.Equal(t, "10,-1:12,3:15", c.String())
+	require.Equal(t, int64(12), c.After(MainLane))
+	require.Equal(t, int64(15), c.After(shard))
+	require.Equal(t, int64(10), c.After(0))
+
+	parsed, err := ParseCursor(c.String())
+	require.NoError(t, err)
+	require.Equal(t, c, parsed)
+}
+
+func TestParseCursor(t *testing.T) {
+	c, err := ParseCursor("42")
+	require.NoError(t, err)
+	require.Equal(t, Cursor{Floor: 42}, c)
+
+	for _, bad := range []string{"", "abc", "-1", "1,2", "1,-2:5", "1,0:x", "1,0:-3"} {
+		_, err := ParseCursor(bad)
+		require.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OperationType string
+
+const (
+	Deposit  OperationType = "DEPOSIT"
+	Withdraw OperationType = "WITHDRAW"
+	// Transfer entries move money between two wallets, as a debit on the
+	// sender and a credit on the receiver.
+	Transfer OperationType = "TRANSFER"
+	// Reversal undoes all or part of the entry named by ReversalOf.
+	Reversal OperationType = "REVERSAL"
+	// Fee entries come in pairs: a debit on the charged wallet and a credit
+	// on the revenue wallet, written in the operation's transaction. A
+	// rejected payout's fee is handed back as another such pair.
+	Fee OperationType = "FEE"
+	// Interest entries pay a savings wallet's monthly interest out of the
+	// treasury wallet, again as a debit and credit pair.
+	Interest OperationType = "INTEREST"
+	// Refund credits a rejected payout back to its wallet. Its ExternalRef
+	// names the payout, so a payout is refunded at most once.
+	Refund OperationType = "REFUND"
+	// Opening entries carry the balances wallets had before the ledger
+	// existed, one per wallet, dated before its other entries.
+	Opening OperationType = "OPENING"
+)
+
+// PayoutRef prefixes the ExternalRef of entries that move a payout's
+// funds. They are settled through the payout and never reversed.
+const PayoutRef = "payout:"
+
+// Entry is a single committed balance change. Amount is signed: credits are
+// positive and debits negative, so summing a wallet's entries yields its
+// balance. ID is a global, monotonically increasing sequence. Shard is set
+// on sharded deposits, whose Balance counts the wallets row and the shards
+// as committed when the entry was written, so deposits in flight on other
+// shards are not in it yet.
+type Entry struct {
+	ID            int64         `json:"id"`
+	WalletID      uuid.UUID     `json:"walletId"`
+	OperationType OperationType `json:"operationType"`
+	Amount        int64         `json:"amount"`
+	Balance       int64         `json:"balance"`
+	ExternalRef   string        `json:"externalRef,omitempty"`
+	ReversalOf    *int64        `json:"reversalOf,omitempty"`
+	Shard         *int          `json:"shard,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+// FeeCharge is a fee charged on top of an operation's amount and credited
+// to the Revenue wallet. A zero Amount charges nothing.
+type FeeCharge struct {
+	Amount  int64
+	Revenue uuid.UUID
+}
+
+// PointBalance is a wallet's balance as of At: the sum of every entry
+// created at or before that instant.
+type PointBalance struct {
+	WalletID uuid.UUID `json:"walletId"`
+	Balance  int64     `json:"balance"`
+	At       time.Time `json:"at"`
+}
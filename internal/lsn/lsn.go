@@ -0,0 +1,63 @@
+// Package lsn carries read-your-writes tokens through a request. A token is
+// the primary's WAL position (pg_lsn text, e.g. "16/B374D848") after a write;
+// reads that present it are only served by a replica that has replayed it.
+package lsn
+
+import (
+	"context"
+	"regexp"
+	"sync"
+)
+
+const (
+	RequestHeader  = "X-Min-LSN"
+	ResponseHeader = "X-LSN"
+)
+
+var format = regexp.MustCompile(`^[0-9A-Fa-f]{1,8}/[0-9A-Fa-f]{1,8}$`)
+
+func Valid(s string) bool {
+	return format.MatchString(s)
+}
+
+type requiredKey struct{}
+
+func WithRequired(ctx context.Context, lsn string) context.Context {
+	return context.WithValue(ctx, requiredKey{}, lsn)
+}
+
+func Required(ctx context.Context) string {
+	lsn, _ := ctx.Value(requiredKey{}).(string)
+	return lsn
+}
+
+// Tracker records the WAL position of the last write committed while
+// serving a request.
+type Tracker struct {
+	mu  sync.Mutex
+	lsn string
+}
+
+func (t *Tracker) Set(lsn string) {
+	t.mu.Lock()
+	t.lsn = lsn
+	t.mu.Unlock()
+}
+
+func (t *Tracker) Get() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lsn
+}
+
+type trackerKey struct{}
+
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+func TrackerFrom(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
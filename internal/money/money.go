@@ -0,0 +1,100 @@
+// Package money holds amounts in minor units and the rules for accepting
+// them: strict integer JSON, overflow-checked arithmetic and per-operation
+// bounds.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+type Amount int64
+
+var ErrOverflow = errors.New("amount overflow")
+
+// Error is a rejected amount; its message is safe to return to clients.
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+var integer = regexp.MustCompile(`^-?[0-9]+$`)
+
+// UnmarshalJSON accepts only plain JSON integers, so 1.5, 1e3 and "10" are
+// rejected instead of being truncated or coerced.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if !integer.MatchString(s) {
+		return &Error{msg: "amount must be an integer"}
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return &Error{msg: "amount out of range"}
+	}
+	*a = Amount(v)
+	return nil
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return a.Add(-b)
+}
+
+// Bounds limits a single operation's amount. Zero Min or Max means no
+// bound on that side beyond the amount being positive.
+type Bounds struct {
+	Min int64
+	Max int64
+}
+
+func (b Bounds) Check(amount int64) error {
+	if amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if b.Min > 0 && amount < b.Min {
+		return fmt.Errorf("amount must be at least %d", b.Min)
+	}
+	if b.Max > 0 && amount > b.Max {
+		return fmt.Errorf("amount must be at most %d", b.Max)
+	}
+	return nil
+}
+
+type Limits struct {
+	Deposit  Bounds
+	Withdraw Bounds
+	// MaxBalance caps a wallet's balance; zero leaves only the BIGINT range.
+	MaxBalance int64
+}
+
+func (l Limits) CheckDeposit(amount int64) error {
+	if err := l.Deposit.Check(amount); err != nil {
+		return err
+	}
+	if l.MaxBalance > 0 && amount > l.MaxBalance {
+		return errors.New("balance limit exceeded")
+	}
+	return nil
+}
+
+func (l Limits) CheckWithdraw(amount int64) error {
+	return l.Withdraw.Check(amount)
+}
@@ -0,0 +1,61 @@
+package money
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestAmount_UnmarshalJSON(t *testing.T) {
+	var req struct {
+		Amount Amount `json:"amount"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 150}`), &req))
+	require.Equal(t, Amount(150), req.Amount)
+
+	for _, body := range []string{`{"amount": 1.5}`, `{"amount": 1e3}`, `{"amount": "10"}`, `{"amount": 10.0}`} {
+		err := json.Unmarshal([]byte(body), &req)
+		var amountErr *Error
+		require.ErrorAs(t, err, &amountErr, body)
+		require.EqualError(t, err, "amount must be an integer")
+	}
+
+	err := json.Unmarshal([]byte(`{"amount": 9223372036854775808}`), &req)
+	require.EqualError(t, err, "amount out of range")
+}
+
+func TestAmount_CheckedArithmetic(t *testing.T) {
+	sum, err := Amount(40).Add(2)
+	require.NoError(t, err)
+	require.Equal(t, Amount(42), sum)
+
+	_, err = Amount(math.MaxInt64).Add(1)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = Amount(math.MinInt64).Add(-1)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = Amount(0).Sub(math.MinInt64)
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestBounds_Check(t *testing.T) {
+	b := Bounds{Min: 10, Max: 1000}
+
+	require.NoError(t, b.Check(10))
+	require.NoError(t, b.Check(1000))
+	require.EqualError(t, b.Check(0), "amount must be positive")
+	require.EqualError(t, b.Check(9), "amount must be at least 10")
+	require.EqualError(t, b.Check(1001), "amount must be at most 1000")
+	require.NoError(t, Bounds{}.Check(math.MaxInt64))
+}
+
+func TestLimits_CheckDeposit(t *testing.T) {
+	l := Limits{MaxBalance: 100}
+
+	require.NoError(t, l.CheckDeposit(100))
+	require.EqualError(t, l.CheckDeposit(101), "balance limit exceeded")
+	require.NoError(t, l.CheckWithdraw(101))
+}
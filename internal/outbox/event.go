@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	WalletCreated  EventType = "WalletCreated"
+	WalletCredited EventType = "WalletCredited"
+	WalletDebited  EventType = "WalletDebited"
+)
+
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      EventType       `json:"type"`
+	WalletID  uuid.UUID       `json:"walletId"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"-"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type BalanceChange struct {
+	Amount        int64 `json:"amount"`
+	Balance       int64 `json:"balance"`
+	LedgerEntryID int64 `json:"ledgerEntryId"`
+}
+
+func NewEvent(eventType EventType, walletId uuid.UUID, payload any) (Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Type:     eventType,
+		WalletID: walletId,
+		Payload:  raw,
+	}, nil
+}
@@ -0,0 +1,16 @@
+package outbox
+
+import "context"
+
+// MultiPublisher publishes every event to each publisher in order and stops
+// at the first failure, so the relay retries the whole event.
+type MultiPublisher []EventPublisher
+
+func (m MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
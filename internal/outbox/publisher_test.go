@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewEvent(t *testing.T) {
+	id := uuid.New()
+
+	event, err := NewEvent(WalletCredited, id, BalanceChange{Amount: 42, Balance: 100, LedgerEntryID: 3})
+
+	require.NoError(t, err)
+	require.Equal(t, WalletCredited, event.Type)
+	require.Equal(t, id, event.WalletID)
+	require.JSONEq(t, `{"amount":42,"balance":100,"ledgerEntryId":3}`, string(event.Payload))
+}
+
+func TestFilePublisher_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	pub, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	id := uuid.New()
+	require.NoError(t, pub.Publish(context.Background(), Event{ID: 1, Type: WalletCreated, WalletID: id, Payload: json.RawMessage(`{}`)}))
+	require.NoError(t, pub.Publish(context.Background(), Event{ID: 2, Type: WalletCredited, WalletID: id, Payload: json.RawMessage(`{"amount":5}`)}))
+	require.NoError(t, pub.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var ids []int64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+	require.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	pub := NewWebhookPublisher(srv.URL, time.Second)
+	event := Event{ID: 7, Type: WalletDebited, WalletID: uuid.New(), Payload: json.RawMessage(`{"amount":1}`)}
+
+	require.NoError(t, pub.Publish(context.Background(), event))
+	require.Equal(t, event.ID, got.ID)
+	require.Equal(t, event.WalletID, got.WalletID)
+}
+
+func TestWebhookPublisher_Non2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	pub := NewWebhookPublisher(srv.URL, time.Second)
+
+	require.EqualError(t, pub.Publish(context.Background(), Event{ID: 1}), "webhook responded with status 502")
+}
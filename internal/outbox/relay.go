@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Store interface {
+	ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	MarkEventDelivered(ctx context.Context, id int64) error
+	MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, cause string) error
+}
+
+type TxRunner interface {
+	RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
+
+const (
+	baseBackoff = time.Second
+	maxBackoff  = 5 * time.Minute
+
+	// claimLease is how long a claimed batch stays away from other relays.
+	// A batch still publishing after that may be published twice.
+	claimLease = 5 * time.Minute
+)
+
+type Relay struct {
+	tx        TxRunner
+	store     Store
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(tx TxRunner, store Store, publisher EventPublisher, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		tx:        tx,
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ProcessBatch(ctx); err != nil {
+				log.Printf("outbox relay: %v", err)
+			}
+		}
+	}
+}
+
+// ProcessBatch claims a batch of due events in a short transaction,
+// publishes them with no transaction open and records each outcome in a
+// transaction of its own. A crash before an outcome is recorded leaves the
+// event to be claimed again once its lease runs out, so delivery is
+// at-least-once.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	var events []Event
+	err := r.tx.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+		var err error
+		events, err = r.store.ClaimPendingEvents(ctxTx, r.batchSize, claimLease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		publishErr := r.publisher.Publish(ctx, event)
+		err := r.tx.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+			if publishErr != nil {
+				next := time.Now().Add(Backoff(event.Attempts + 1))
+				return r.store.MarkEventFailed(ctxTx, event.ID, next, publishErr.Error())
+			}
+			return r.store.MarkEventDelivered(ctxTx, event.ID)
+		})
+		if err != nil {
+			return delivered, err
+		}
+		if publishErr == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
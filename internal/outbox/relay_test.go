@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeTx struct {
+	calls int
+	open  bool
+}
+
+func (f *fakeTx) RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	f.calls++
+	f.open = true
+	defer func() { f.open = false }()
+	return fn(ctx)
+}
+
+type fakeStore struct {
+	pending   []Event
+	delivered []int64
+	failed    map[int64]time.Time
+	lease     time.Duration
+}
+
+func (s *fakeStore) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	s.lease = lease
+	if len(s.pending) > limit {
+		return s.pending[:limit], nil
+	}
+	return s.pending, nil
+}
+
+func (s *fakeStore) MarkEventDelivered(ctx context.Context, id int64) error {
+	s.delivered = append(s.delivered, id)
+	return nil
+}
+
+func (s *fakeStore) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, cause string) error {
+	if s.failed == nil {
+		s.failed = map[int64]time.Time{}
+	}
+	s.failed[id] = nextAttemptAt
+	return nil
+}
+
+type fakePublisher struct {
+	failFor   map[int64]bool
+	published []int64
+	tx        *fakeTx
+	inTx      bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event Event) error {
+	if p.tx != nil && p.tx.open {
+		p.inTx = true
+	}
+	if p.failFor[event.ID] {
+		return errors.New("publish failed")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func TestRelay_ProcessBatch(t *testing.T) {
+	store := &fakeStore{pending: []Event{
+		{ID: 1, Type: WalletCredited, WalletID: uuid.New()},
+		{ID: 2, Type: WalletDebited, WalletID: uuid.New(), Attempts: 2},
+		{ID: 3, Type: WalletCreated, WalletID: uuid.New()},
+	}}
+	tx := &fakeTx{}
+	pub := &fakePublisher{failFor: map[int64]bool{2: true}, tx: tx}
+
+	relay := NewRelay(tx, store, pub, time.Second, 10)
+
+	before := time.Now()
+	n, err := relay.ProcessBatch(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	// One claim, then one transaction per recorded outcome; publishing
+	// happens outside all of them.
+	require.Equal(t, 4, tx.calls)
+	require.False(t, pub.inTx)
+	require.Equal(t, claimLease, store.lease)
+	require.Equal(t, []int64{1, 3}, pub.published)
+	require.Equal(t, []int64{1, 3}, store.delivered)
+	require.Contains(t, store.failed, int64(2))
+	require.WithinDuration(t, before.Add(Backoff(3)), store.failed[2], time.Second)
+}
+
+func TestRelay_ProcessBatch_RespectsBatchSize(t *testing.T) {
+	store := &fakeStore{pending: []Event{{ID: 1}, {ID: 2}, {ID: 3}}}
+	pub := &fakePublisher{}
+
+	relay := NewRelay(&fakeTx{}, store, pub, time.Second, 2)
+
+	n, err := relay.ProcessBatch(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int64{1, 2}, pub.published)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, Backoff(0))
+	require.Equal(t, time.Second, Backoff(1))
+	require.Equal(t, 2*time.Second, Backoff(2))
+	require.Equal(t, 8*time.Second, Backoff(4))
+	require.Equal(t, maxBackoff, Backoff(100))
+}
@@ -0,0 +1,170 @@
+package payout
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+
+type painDocument struct {
+	XMLName xml.Name        `xml:"Document"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	GrpHdr  painGroupHeader `xml:"CstmrCdtTrfInitn>GrpHdr"`
+	PmtInf  []painPmtInf    `xml:"CstmrCdtTrfInitn>PmtInf"`
+}
+
+type painGroupHeader struct {
+	MsgId    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	NbOfTxs  int    `xml:"NbOfTxs"`
+	CtrlSum  string `xml:"CtrlSum"`
+	InitgPty string `xml:"InitgPty>Nm"`
+}
+
+type painPmtInf struct {
+	PmtInfId    string       `xml:"PmtInfId"`
+	PmtMtd      string       `xml:"PmtMtd"`
+	NbOfTxs     int          `xml:"NbOfTxs"`
+	CtrlSum     string       `xml:"CtrlSum"`
+	PmtTpInf    *painPmtTp   `xml:"PmtTpInf"`
+	ReqdExctnDt string       `xml:"ReqdExctnDt>Dt"`
+	Dbtr        string       `xml:"Dbtr>Nm"`
+	DbtrIBAN    string       `xml:"DbtrAcct>Id>IBAN"`
+	DbtrBIC     string       `xml:"DbtrAgt>FinInstnId>BICFI"`
+	ChrgBr      string       `xml:"ChrgBr"`
+	Txs         []painCdtTrf `xml:"CdtTrfTxInf"`
+}
+
+type painPmtTp struct {
+	SvcLvl string `xml:"SvcLvl>Cd"`
+}
+
+type painCdtTrf struct {
+	EndToEndId string     `xml:"PmtId>EndToEndId"`
+	Amt        painAmount `xml:"Amt>InstdAmt"`
+	CdtrAgt    *painAgent `xml:"CdtrAgt"`
+	Cdtr       string     `xml:"Cdtr>Nm"`
+	CdtrIBAN   string     `xml:"CdtrAcct>Id>IBAN"`
+	RmtInf     *painRmt   `xml:"RmtInf"`
+}
+
+type painAgent struct {
+	BICFI string `xml:"FinInstnId>BICFI"`
+}
+
+type painRmt struct {
+	Ustrd string `xml:"Ustrd"`
+}
+
+type painAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// PaymentInfoID names the payment information block holding the payouts
+// in currency; status reports may refer to it.
+func PaymentInfoID(messageId, currency string) string {
+	return messageId + "-" + currency
+}
+
+// BuildPain001 renders payouts as a pain.001.001.09 credit transfer
+// initiation, one payment information block per currency. Control sums
+// are the plain sum of the amounts, as the standard defines them.
+func BuildPain001(messageId string, debtor Debtor, payouts []Payout, execution, created time.Time) ([]byte, int64, error) {
+	if len(payouts) == 0 {
+		return nil, 0, errors.New("no payouts to export")
+	}
+
+	byCurrency := map[string][]Payout{}
+	var total int64
+	for _, p := range payouts {
+		byCurrency[p.Currency] = append(byCurrency[p.Currency], p)
+		total += p.Amount
+	}
+	currencies := make([]string, 0, len(byCurrency))
+	for c := range byCurrency {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	doc := painDocument{
+		Xmlns: pain001Namespace,
+		GrpHdr: painGroupHeader{
+			MsgId:    messageId,
+			CreDtTm:  created.UTC().Format("2006-01-02T15:04:05Z"),
+			NbOfTxs:  len(payouts),
+			CtrlSum:  FormatAmount(total),
+			InitgPty: debtor.Name,
+		},
+	}
+	for _, c := range currencies {
+		group := byCurrency[c]
+		info := painPmtInf{
+			PmtInfId:    PaymentInfoID(messageId, c),
+			PmtMtd:      "TRF",
+			NbOfTxs:     len(group),
+			ReqdExctnDt: execution.Format("2006-01-02"),
+			Dbtr:        debtor.Name,
+			DbtrIBAN:    debtor.IBAN,
+			DbtrBIC:     debtor.BIC,
+			ChrgBr:      "SHAR",
+		}
+		if c == "EUR" {
+			info.PmtTpInf, info.ChrgBr = &painPmtTp{SvcLvl: "SEPA"}, "SLEV"
+		}
+
+		var sum int64
+		for _, p := range group {
+			sum += p.Amount
+			tx := painCdtTrf{
+				EndToEndId: p.EndToEndID,
+				Amt:        painAmount{Ccy: p.Currency, Value: FormatAmount(p.Amount)},
+				Cdtr:       p.Beneficiary.Name,
+				CdtrIBAN:   p.Beneficiary.IBAN,
+			}
+			if p.Beneficiary.BIC != "" {
+				tx.CdtrAgt = &painAgent{BICFI: p.Beneficiary.BIC}
+			}
+			if p.Reference != "" {
+				tx.RmtInf = &painRmt{Ustrd: p.Reference}
+			}
+			info.Txs = append(info.Txs, tx)
+		}
+		info.CtrlSum = FormatAmount(sum)
+		doc.PmtInf = append(doc.PmtInf, info)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, 0, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), total, nil
+}
+
+// FormatAmount writes minor units with two decimals.
+func FormatAmount(minor int64) string {
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+// MessageID derives a pain.001 message ID from the file ID; it must be
+// unique per debtor and at most 35 characters. Base32 keeps it short
+// enough to leave room for the currency in PaymentInfoID.
+func MessageID(fileId uuid.UUID) string {
+	return "PO" + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(fileId[:])
+}
@@ -0,0 +1,162 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"project/internal/ledger"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	// Pending payouts hold their funds until the next export.
+	Pending Status = "PENDING"
+	// Exported payouts were sent to the bank and wait for its status report.
+	Exported Status = "EXPORTED"
+	// Accepted payouts passed the bank's checks but are not settled yet and
+	// can still be rejected.
+	Accepted  Status = "ACCEPTED"
+	Confirmed Status = "CONFIRMED"
+	// Rejected payouts have had their funds returned to the wallet.
+	Rejected Status = "REJECTED"
+)
+
+type Beneficiary struct {
+	Name string `json:"name"`
+	IBAN string `json:"iban"`
+	BIC  string `json:"bic,omitempty"`
+}
+
+// Payout is a withdrawal to a bank account. Its amount left the wallet
+// when it was requested and comes back only if the bank rejects it, together
+// with the Fee charged on top.
+type Payout struct {
+	ID          uuid.UUID   `json:"payoutId"`
+	WalletID    uuid.UUID   `json:"walletId"`
+	Amount      int64       `json:"amount"`
+	Fee         int64       `json:"fee"`
+	Currency    string      `json:"currency"`
+	Beneficiary Beneficiary `json:"beneficiary"`
+	Reference   string      `json:"reference,omitempty"`
+	EndToEndID  string      `json:"endToEndId"`
+	Status      Status      `json:"status"`
+	FileID      *uuid.UUID  `json:"fileId,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// File is a pain.001 message as handed to the bank.
+type File struct {
+	ID         uuid.UUID `json:"fileId"`
+	MessageID  string    `json:"messageId"`
+	Payouts    int       `json:"payouts"`
+	ControlSum int64     `json:"controlSum"`
+	Content    []byte    `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Debtor is the account payouts are paid from.
+type Debtor struct {
+	Name string
+	IBAN string
+	BIC  string
+}
+
+type Store interface {
+	InsertPayout(ctx context.Context, p Payout) error
+	GetPayout(ctx context.Context, id uuid.UUID) (Payout, error)
+	ListPendingPayouts(ctx context.Context, limit int) ([]Payout, error)
+	InsertPayoutFile(ctx context.Context, f File) error
+	GetPayoutFile(ctx context.Context, id uuid.UUID) (File, error)
+	GetPayoutFileByMessageID(ctx context.Context, messageId string) (File, error)
+	MarkPayoutsExported(ctx context.Context, fileId uuid.UUID, ids []uuid.UUID) error
+	ListFilePayoutsForUpdate(ctx context.Context, fileId uuid.UUID) ([]Payout, error)
+	UpdatePayoutStatus(ctx context.Context, id uuid.UUID, status Status, reason string) error
+}
+
+type TxRunner interface {
+	RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
+
+// NewPayout assigns the IDs. The end-to-end ID travels with the transfer
+// and comes back in the bank's status report; pain.001 allows 35 characters.
+func NewPayout(walletId uuid.UUID, amount int64, currency string, b Beneficiary, reference string) Payout {
+	id := uuid.New()
+	now := time.Now()
+	return Payout{
+		ID:          id,
+		WalletID:    walletId,
+		Amount:      amount,
+		Currency:    currency,
+		Beneficiary: b,
+		Reference:   reference,
+		EndToEndID:  strings.ReplaceAll(id.String(), "-", ""),
+		Status:      Pending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// WithdrawalRef is the ledger reference of the entry that took the
+// payout's amount from its wallet.
+func (p Payout) WithdrawalRef() string {
+	return ledger.PayoutRef + p.ID.String() + ":withdrawal"
+}
+
+// RefundRef is the ledger reference of the entry refunding a rejected
+// payout.
+func (p Payout) RefundRef() string {
+	return ledger.PayoutRef + p.ID.String()
+}
+
+var (
+	ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicPattern  = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// NormalizeIBAN drops the spaces of the printed form and upper-cases it.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// ValidIBAN checks the format and the ISO 7064 mod 97 check digits.
+func ValidIBAN(iban string) bool {
+	if !ibanPattern.MatchString(iban) {
+		return false
+	}
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			fmt.Fprintf(&digits, "%d", c-'A'+10)
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func ValidBIC(bic string) bool {
+	return bicPattern.MatchString(bic)
+}
+
+func (d Debtor) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("payout debtor name is required")
+	}
+	if !ValidIBAN(d.IBAN) {
+		return errors.New("payout debtor IBAN is invalid")
+	}
+	if !ValidBIC(d.BIC) {
+		return errors.New("payout debtor BIC is invalid")
+	}
+	return nil
+}
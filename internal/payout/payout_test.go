@@ -0,0 +1,158 @@
+package payout
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var debtor = Debtor{Name: "Wallets Ltd", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"}
+
+func TestValidIBAN(t *testing.T) {
+	require.True(t, ValidIBAN("DE89370400440532013000"))
+	require.True(t, ValidIBAN(NormalizeIBAN("gb82 west 1234 5698 7654 32")))
+	require.False(t, ValidIBAN("DE89370400440532013001"))
+	require.False(t, ValidIBAN("DE8937"))
+	require.False(t, ValidIBAN("de89370400440532013000"))
+
+	require.True(t, ValidBIC("COBADEFF"))
+	require.True(t, ValidBIC("COBADEFFXXX"))
+	require.False(t, ValidBIC("COBADE"))
+}
+
+func TestBuildPain001(t *testing.T) {
+	created := time.Date(2025, 10, 30, 9, 0, 0, 0, time.UTC)
+	payouts := []Payout{
+		NewPayout(uuid.New(), 1050, "EUR", Beneficiary{Name: "Jane & Co", IBAN: "GB82WEST12345698765432"}, "Invoice <7>"),
+		NewPayout(uuid.New(), 2000, "EUR", Beneficiary{Name: "John", IBAN: "DE89370400440532013000", BIC: "COBADEFF"}, ""),
+		NewPayout(uuid.New(), 999, "GBP", Beneficiary{Name: "Ann", IBAN: "GB82WEST12345698765432"}, "x"),
+	}
+
+	content, total, err := BuildPain001("PO1", debtor, payouts, created, created)
+	require.NoError(t, err)
+	require.Equal(t, int64(4049), total)
+
+	var doc struct {
+		MsgId   string `xml:"CstmrCdtTrfInitn>GrpHdr>MsgId"`
+		NbOfTxs int    `xml:"CstmrCdtTrfInitn>GrpHdr>NbOfTxs"`
+		CtrlSum string `xml:"CstmrCdtTrfInitn>GrpHdr>CtrlSum"`
+		PmtInf  []struct {
+			ID      string `xml:"PmtInfId"`
+			CtrlSum string `xml:"CtrlSum"`
+			ChrgBr  string `xml:"ChrgBr"`
+			Txs     []struct {
+				EndToEndId string `xml:"PmtId>EndToEndId"`
+				Amount     string `xml:"Amt>InstdAmt"`
+				Name       string `xml:"Cdtr>Nm"`
+				Ustrd      string `xml:"RmtInf>Ustrd"`
+			} `xml:"CdtTrfTxInf"`
+		} `xml:"CstmrCdtTrfInitn>PmtInf"`
+	}
+	require.NoError(t, xml.Unmarshal(content, &doc))
+	require.Contains(t, string(content), `xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"`)
+	require.Equal(t, 1, strings.Count(string(content), "<CdtrAgt>"))
+	require.Equal(t, 2, strings.Count(string(content), "<RmtInf>"))
+	require.Equal(t, 1, strings.Count(string(content), "<PmtTpInf>"))
+
+	require.Equal(t, "PO1", doc.MsgId)
+	require.Equal(t, 3, doc.NbOfTxs)
+	require.Equal(t, "40.49", doc.CtrlSum)
+	require.Len(t, doc.PmtInf, 2)
+
+	eur := doc.PmtInf[0]
+	require.Equal(t, "PO1-EUR", eur.ID)
+	require.Equal(t, "30.50", eur.CtrlSum)
+	require.Equal(t, "SLEV", eur.ChrgBr)
+	require.Equal(t, "10.50", eur.Txs[0].Amount)
+	require.Equal(t, "Jane & Co", eur.Txs[0].Name)
+	require.Equal(t, "Invoice <7>", eur.Txs[0].Ustrd)
+	require.Equal(t, payouts[0].EndToEndID, eur.Txs[0].EndToEndId)
+	require.LessOrEqual(t, len(eur.Txs[0].EndToEndId), 35)
+
+	require.Equal(t, "PO1-GBP", doc.PmtInf[1].ID)
+	require.Equal(t, "9.99", doc.PmtInf[1].CtrlSum)
+	require.Equal(t, "SHAR", doc.PmtInf[1].ChrgBr)
+
+	_, _, err = BuildPain001("PO1", debtor, nil, created, created)
+	require.Error(t, err)
+}
+
+func TestBuildPain001_IDLengths(t *testing.T) {
+	payouts := []Payout{
+		NewPayout(uuid.New(), 100, "EUR", Beneficiary{Name: "Jane", IBAN: "GB82WEST12345698765432"}, ""),
+		NewPayout(uuid.New(), 100, "GBP", Beneficiary{Name: "Ann", IBAN: "GB82WEST12345698765432"}, ""),
+	}
+	content, _, err := BuildPain001(MessageID(uuid.New()), debtor, payouts, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	var doc struct {
+		MsgId  string   `xml:"CstmrCdtTrfInitn>GrpHdr>MsgId"`
+		PmtInf []string `xml:"CstmrCdtTrfInitn>PmtInf>PmtInfId"`
+		E2E    []string `xml:"CstmrCdtTrfInitn>PmtInf>CdtTrfTxInf>PmtId>EndToEndId"`
+	}
+	require.NoError(t, xml.Unmarshal(content, &doc))
+	require.LessOrEqual(t, len(doc.MsgId), 35)
+	require.Len(t, doc.PmtInf, 2)
+	require.Len(t, doc.E2E, 2)
+	for _, id := range append(doc.PmtInf, doc.E2E...) {
+		require.LessOrEqual(t, len(id), 35, id)
+	}
+}
+
+func TestParseStatusReport(t *testing.T) {
+	accepted := NewPayout(uuid.New(), 100, "EUR", Beneficiary{}, "")
+	rejected := NewPayout(uuid.New(), 100, "EUR", Beneficiary{}, "")
+	pound := NewPayout(uuid.New(), 100, "GBP", Beneficiary{}, "")
+	other := NewPayout(uuid.New(), 100, "USD", Beneficiary{}, "")
+
+	report, err := ParseStatusReport([]byte(`<?xml version="1.0"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10">
+  <CstmrPmtStsRpt>
+    <GrpHdr><MsgId>BANK-1</MsgId></GrpHdr>
+    <OrgnlGrpInfAndSts><OrgnlMsgId>PO1</OrgnlMsgId><OrgnlMsgNmId>pain.001.001.09</OrgnlMsgNmId><GrpSts>PART</GrpSts></OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>PO1-EUR</OrgnlPmtInfId>
+      <TxInfAndSts><OrgnlEndToEndId>` + accepted.EndToEndID + `</OrgnlEndToEndId><TxSts>ACSC</TxSts></TxInfAndSts>
+      <TxInfAndSts>
+        <OrgnlEndToEndId>` + rejected.EndToEndID + `</OrgnlEndToEndId><TxSts>RJCT</TxSts>
+        <StsRsnInf><Rsn><Cd>AC04</Cd></Rsn><AddtlInf>Account closed</AddtlInf></StsRsnInf>
+      </TxInfAndSts>
+    </OrgnlPmtInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>PO1-GBP</OrgnlPmtInfId><PmtInfSts>RJCT</PmtInfSts>
+      <StsRsnInf><Rsn><Cd>AM04</Cd></Rsn></StsRsnInf>
+    </OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>`))
+	require.NoError(t, err)
+	require.Equal(t, "PO1", report.OriginalMessageID)
+
+	require.Equal(t, Confirmed, report.StatusOf(accepted).Outcome())
+	require.Equal(t, TxStatus{Code: "RJCT", Reason: "AC04: Account closed"}, report.StatusOf(rejected))
+	require.Equal(t, TxStatus{Code: "RJCT", Reason: "AM04"}, report.StatusOf(pound))
+	require.Equal(t, Status(""), report.StatusOf(other).Outcome())
+
+	_, err = ParseStatusReport([]byte("<Document/>"))
+	require.EqualError(t, err, "invalid pain.002: missing OrgnlMsgId")
+}
+
+func TestTxStatus_Outcome(t *testing.T) {
+	for code, want := range map[string]Status{
+		"ACSC": Confirmed, "ACCC": Confirmed,
+		"ACCP": Accepted, "ACSP": Accepted, "ACWC": Accepted,
+		"RJCT": Rejected, "PDNG": "", "": "",
+	} {
+		require.Equal(t, want, TxStatus{Code: code}.Outcome(), code)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.05", FormatAmount(5))
+	require.Equal(t, "12.30", FormatAmount(1230))
+	require.True(t, strings.HasPrefix(MessageID(uuid.New()), "PO"))
+	require.Len(t, MessageID(uuid.New()), 28)
+}
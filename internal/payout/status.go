@@ -0,0 +1,130 @@
+package payout
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TxStatus is an ISO 20022 transaction status code with the reason the
+// bank gave for it.
+type TxStatus struct {
+	Code   string
+	Reason string
+}
+
+// Outcome maps the status to what it means for a payout: Confirmed once
+// it has settled, Accepted while the bank is still settling it, Rejected,
+// or "" while it is still being processed. Banks do reject or return
+// payouts after accepting them, so only settlement is final.
+func (s TxStatus) Outcome() Status {
+	switch s.Code {
+	case "ACSC", "ACCC":
+		return Confirmed
+	case "ACCP", "ACSP", "ACWC":
+		return Accepted
+	case "RJCT":
+		return Rejected
+	}
+	return ""
+}
+
+// StatusReport is a pain.002 customer payment status report. Statuses are
+// given per transaction, per payment information block or for the whole
+// message; the most specific one applies.
+type StatusReport struct {
+	OriginalMessageID string
+	Group             TxStatus
+	PaymentInfos      map[string]TxStatus
+	Transactions      map[string]TxStatus
+}
+
+// StatusOf returns the status the report gives p, which was exported in the
+// payment information block for its currency.
+func (r StatusReport) StatusOf(p Payout) TxStatus {
+	if s, ok := r.Transactions[p.EndToEndID]; ok {
+		return s
+	}
+	if s, ok := r.PaymentInfos[PaymentInfoID(r.OriginalMessageID, p.Currency)]; ok && s.Code != "" {
+		return s
+	}
+	return r.Group
+}
+
+type pain002Document struct {
+	Report struct {
+		Group struct {
+			OrgnlMsgId string          `xml:"OrgnlMsgId"`
+			GrpSts     string          `xml:"GrpSts"`
+			Reasons    []pain002Reason `xml:"StsRsnInf"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		PmtInfs []struct {
+			OrgnlPmtInfId string          `xml:"OrgnlPmtInfId"`
+			PmtInfSts     string          `xml:"PmtInfSts"`
+			Reasons       []pain002Reason `xml:"StsRsnInf"`
+			Txs           []struct {
+				OrgnlEndToEndId string          `xml:"OrgnlEndToEndId"`
+				TxSts           string          `xml:"TxSts"`
+				Reasons         []pain002Reason `xml:"StsRsnInf"`
+			} `xml:"TxInfAndSts"`
+		} `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type pain002Reason struct {
+	Code string   `xml:"Rsn>Cd"`
+	Info []string `xml:"AddtlInf"`
+}
+
+func reasonText(reasons []pain002Reason) string {
+	var parts []string
+	for _, r := range reasons {
+		text := strings.TrimSpace(strings.Join(r.Info, " "))
+		switch {
+		case r.Code != "" && text != "":
+			parts = append(parts, r.Code+": "+text)
+		case r.Code != "":
+			parts = append(parts, r.Code)
+		case text != "":
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseStatusReport reads a pain.002 file of any version from .001.03 on.
+func ParseStatusReport(content []byte) (StatusReport, error) {
+	var doc pain002Document
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	if err := dec.Decode(&doc); err != nil {
+		return StatusReport{}, fmt.Errorf("invalid pain.002: %w", err)
+	}
+
+	g := doc.Report.Group
+	if g.OrgnlMsgId == "" {
+		return StatusReport{}, errors.New("invalid pain.002: missing OrgnlMsgId")
+	}
+
+	report := StatusReport{
+		OriginalMessageID: strings.TrimSpace(g.OrgnlMsgId),
+		Group:             TxStatus{Code: strings.TrimSpace(g.GrpSts), Reason: reasonText(g.Reasons)},
+		PaymentInfos:      map[string]TxStatus{},
+		Transactions:      map[string]TxStatus{},
+	}
+	for _, info := range doc.Report.PmtInfs {
+		report.PaymentInfos[strings.TrimSpace(info.OrgnlPmtInfId)] = TxStatus{
+			Code: strings.TrimSpace(info.PmtInfSts), Reason: reasonText(info.Reasons),
+		}
+		for _, tx := range info.Txs {
+			if tx.OrgnlEndToEndId == "" || tx.TxSts == "" {
+				continue
+			}
+			report.Transactions[strings.TrimSpace(tx.OrgnlEndToEndId)] = TxStatus{
+				Code: strings.TrimSpace(tx.TxSts), Reason: reasonText(tx.Reasons),
+			}
+		}
+	}
+	return report, nil
+}
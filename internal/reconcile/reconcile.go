@@ -0,0 +1,73 @@
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	OK            Status = "OK"
+	Discrepancies Status = "DISCREPANCIES"
+	Failed        Status = "FAILED"
+)
+
+type Kind string
+
+const (
+	// BalanceMismatch: the wallet's balance, shards included, differs from
+	// the sum of its ledger entries.
+	BalanceMismatch Kind = "BALANCE_MISMATCH"
+	// SnapshotMismatch: the latest daily snapshot's closing balance differs
+	// from the sum of the entries up to the end of that day.
+	SnapshotMismatch Kind = "SNAPSHOT_MISMATCH"
+)
+
+type Discrepancy struct {
+	WalletID         uuid.UUID  `json:"walletId"`
+	Kinds            []Kind     `json:"kinds"`
+	Balance          int64      `json:"balance"`
+	LedgerSum        int64      `json:"ledgerSum"`
+	SnapshotDay      *time.Time `json:"snapshotDay,omitempty"`
+	SnapshotClosing  int64      `json:"snapshotClosing,omitempty"`
+	LedgerAtSnapshot int64      `json:"ledgerAtSnapshot,omitempty"`
+}
+
+// Totals are system-wide sums. Every balance change is a ledger entry, so
+// credits minus debits must equal the money held in all wallets.
+type Totals struct {
+	Wallets int   `json:"wallets"`
+	Credits int64 `json:"totalCredits"`
+	Debits  int64 `json:"totalDebits"`
+	Balance int64 `json:"totalBalance"`
+}
+
+func (t Totals) Balanced() bool {
+	return t.Credits-t.Debits == t.Balance
+}
+
+// Report is the outcome of one run. Discrepancies holds at most the
+// configured number of wallets; DiscrepancyCount is the full count.
+type Report struct {
+	ID               int64         `json:"id,omitempty"`
+	Status           Status        `json:"status"`
+	StartedAt        time.Time     `json:"startedAt"`
+	FinishedAt       time.Time     `json:"finishedAt"`
+	Totals           Totals        `json:"totals"`
+	DiscrepancyCount int           `json:"discrepancyCount"`
+	Discrepancies    []Discrepancy `json:"discrepancies"`
+	Error            string        `json:"error,omitempty"`
+}
+
+type Store interface {
+	ReconcileTotals(ctx context.Context) (Totals, error)
+	FindDiscrepancies(ctx context.Context, limit int) ([]Discrepancy, int, error)
+	InsertReconciliationRun(ctx context.Context, report Report) (int64, error)
+}
+
+type TxRunner interface {
+	RunReadOnlyDeferrable(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
@@ -0,0 +1,120 @@
+package reconcile
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"strings"
+	"time"
+)
+
+var metrics = expvar.NewMap("reconciliation")
+
+type Reconciler struct {
+	tx          TxRunner
+	store       Store
+	interval    time.Duration
+	maxReported int
+}
+
+func NewReconciler(tx TxRunner, store Store, interval time.Duration, maxReported int) *Reconciler {
+	return &Reconciler{
+		tx:          tx,
+		store:       store,
+		interval:    interval,
+		maxReported: maxReported,
+	}
+}
+
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce checks every wallet and the system-wide totals. All reads share
+// one deferrable serializable snapshot, so balances and ledger are seen at
+// the same instant even while deposits and withdrawals keep committing.
+// The run is recorded whatever its outcome.
+func (r *Reconciler) RunOnce(ctx context.Context) (Report, error) {
+	report := Report{StartedAt: time.Now()}
+
+	err := r.tx.RunReadOnlyDeferrable(ctx, func(ctxTx context.Context) error {
+		totals, err := r.store.ReconcileTotals(ctxTx)
+		if err != nil {
+			return err
+		}
+		found, count, err := r.store.FindDiscrepancies(ctxTx, r.maxReported)
+		if err != nil {
+			return err
+		}
+		report.Totals, report.Discrepancies, report.DiscrepancyCount = totals, found, count
+		return nil
+	})
+	report.FinishedAt = time.Now()
+
+	switch {
+	case err != nil:
+		report.Status = Failed
+		report.Error = err.Error()
+		report.Discrepancies, report.DiscrepancyCount = nil, 0
+	case report.DiscrepancyCount > 0 || !report.Totals.Balanced():
+		report.Status = Discrepancies
+	default:
+		report.Status = OK
+	}
+
+	id, recordErr := r.store.InsertReconciliationRun(ctx, report)
+	if recordErr != nil {
+		log.Printf("reconciliation: recording run: %v", recordErr)
+	}
+	report.ID = id
+
+	r.observe(report)
+	return report, err
+}
+
+func (r *Reconciler) observe(report Report) {
+	metrics.Add("runs", 1)
+	metrics.Add("runs_"+strings.ToLower(string(report.Status)), 1)
+
+	discrepancies, outOfBalance := new(expvar.Int), new(expvar.Int)
+	discrepancies.Set(int64(report.DiscrepancyCount))
+	if report.Status != Failed && !report.Totals.Balanced() {
+		outOfBalance.Set(1)
+	}
+	lastRun := new(expvar.Int)
+	lastRun.Set(report.FinishedAt.Unix())
+	metrics.Set("discrepancies", discrepancies)
+	metrics.Set("out_of_balance", outOfBalance)
+	metrics.Set("last_run_unix", lastRun)
+
+	t := report.Totals
+	switch report.Status {
+	case Failed:
+		log.Printf("reconciliation %d: failed: %s", report.ID, report.Error)
+		return
+	case OK:
+		log.Printf("reconciliation %d: ok, %d wallets, credits %d, debits %d, balance %d",
+			report.ID, t.Wallets, t.Credits, t.Debits, t.Balance)
+		return
+	}
+
+	if !t.Balanced() {
+		log.Printf("reconciliation %d: ledger out of balance: credits %d - debits %d != balance %d",
+			report.ID, t.Credits, t.Debits, t.Balance)
+	}
+	log.Printf("reconciliation %d: %d of %d wallets do not reconcile", report.ID, report.DiscrepancyCount, t.Wallets)
+	for _, d := range report.Discrepancies {
+		log.Printf("reconciliation %d: wallet %s %v: balance %d, ledger %d, snapshot %d, ledger at snapshot %d",
+			report.ID, d.WalletID, d.Kinds, d.Balance, d.LedgerSum, d.SnapshotClosing, d.LedgerAtSnapshot)
+	}
+}
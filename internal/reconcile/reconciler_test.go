@@ -0,0 +1,96 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeTx struct{ calls int }
+
+func (f *fakeTx) RunReadOnlyDeferrable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+type fakeStore struct {
+	totals    Totals
+	found     []Discrepancy
+	count     int
+	err       error
+	recorded  []Report
+	recordErr error
+}
+
+func (f *fakeStore) ReconcileTotals(ctx context.Context) (Totals, error) {
+	return f.totals, f.err
+}
+
+func (f *fakeStore) FindDiscrepancies(ctx context.Context, limit int) ([]Discrepancy, int, error) {
+	if len(f.found) > limit {
+		return f.found[:limit], f.count, nil
+	}
+	return f.found, f.count, nil
+}
+
+func (f *fakeStore) InsertReconciliationRun(ctx context.Context, report Report) (int64, error) {
+	f.recorded = append(f.recorded, report)
+	return int64(len(f.recorded)), f.recordErr
+}
+
+func TestRunOnce_OK(t *testing.T) {
+	tx := &fakeTx{}
+	store := &fakeStore{totals: Totals{Wallets: 3, Credits: 100, Debits: 40, Balance: 60}}
+
+	report, err := NewReconciler(tx, store, 0, 10).RunOnce(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, OK, report.Status)
+	require.Equal(t, 1, tx.calls)
+	require.Len(t, store.recorded, 1)
+	require.Equal(t, int64(1), report.ID)
+	require.False(t, report.FinishedAt.Before(report.StartedAt))
+}
+
+func TestRunOnce_ReportsWalletDiscrepancies(t *testing.T) {
+	store := &fakeStore{
+		totals: Totals{Wallets: 3, Credits: 100, Debits: 40, Balance: 60},
+		found: []Discrepancy{
+			{WalletID: uuid.New(), Kinds: []Kind{BalanceMismatch}, Balance: 10, LedgerSum: 5},
+			{WalletID: uuid.New(), Kinds: []Kind{SnapshotMismatch}},
+		},
+		count: 2,
+	}
+
+	report, err := NewReconciler(&fakeTx{}, store, 0, 1).RunOnce(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, Discrepancies, report.Status)
+	require.Equal(t, 2, report.DiscrepancyCount)
+	require.Len(t, report.Discrepancies, 1)
+	require.Equal(t, Discrepancies, store.recorded[0].Status)
+}
+
+func TestRunOnce_OutOfBalance(t *testing.T) {
+	store := &fakeStore{totals: Totals{Wallets: 1, Credits: 100, Debits: 40, Balance: 61}}
+
+	report, err := NewReconciler(&fakeTx{}, store, 0, 10).RunOnce(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, Discrepancies, report.Status)
+	require.Zero(t, report.DiscrepancyCount)
+}
+
+func TestRunOnce_FailureIsRecorded(t *testing.T) {
+	store := &fakeStore{err: errors.New("canceling statement due to statement timeout")}
+
+	report, err := NewReconciler(&fakeTx{}, store, 0, 10).RunOnce(context.Background())
+
+	require.Error(t, err)
+	require.Equal(t, Failed, report.Status)
+	require.Len(t, store.recorded, 1)
+	require.Equal(t, "canceling statement due to statement timeout", store.recorded[0].Error)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxBulkBalances = 1000
+
+func (ws *WalletService) GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	if at.IsZero() {
+		return 0, errors.New("at parameter is required")
+	}
+	return ws.Repo.GetBalanceAt(ctx, walletId, at)
+}
+
+// GetBalancesAt returns the balances of the known wallets in request order,
+// and the IDs that do not exist.
+func (ws *WalletService) GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, []uuid.UUID, error) {
+	if at.IsZero() {
+		return nil, nil, errors.New("at parameter is required")
+	}
+	if len(walletIds) == 0 {
+		return nil, nil, errors.New("walletIds must not be empty")
+	}
+	if len(walletIds) > MaxBulkBalances {
+		return nil, nil, fmt.Errorf("too many walletIds: %d > %d", len(walletIds), MaxBulkBalances)
+	}
+
+	var unique []uuid.UUID
+	seen := make(map[uuid.UUID]bool, len(walletIds))
+	for i, id := range walletIds {
+		if id == uuid.Nil {
+			return nil, nil, fmt.Errorf("walletIds[%d]: walletId parameter is required", i)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	found, err := ws.Repo.GetBalancesAt(ctx, unique, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[uuid.UUID]ledger.PointBalance, len(found))
+	for _, b := range found {
+		byID[b.WalletID] = b
+	}
+	balances := make([]ledger.PointBalance, 0, len(found))
+	var missing []uuid.UUID
+	for _, id := range unique {
+		if b, ok := byID[id]; ok {
+			balances = append(balances, b)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return balances, missing, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/bankstmt"
+	"project/internal/wallet"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxStatementExceptions = 1000
+	MaxResolutionNote      = 1024
+)
+
+type ResolveAction string
+
+const (
+	ResolveCredit ResolveAction = "credit"
+	ResolveIgnore ResolveAction = "ignore"
+)
+
+type BankStatementService struct {
+	Tx      bankstmt.TxRunner
+	Store   bankstmt.Store
+	Wallets *WalletService
+}
+
+func NewBankStatementService(tx bankstmt.TxRunner, store bankstmt.Store, wallets *WalletService) *BankStatementService {
+	return &BankStatementService{
+		Tx:      tx,
+		Store:   store,
+		Wallets: wallets,
+	}
+}
+
+// Import stores every booked line of a bank statement and credits the ones
+// whose reference names a wallet. Each line is stored and credited in its own
+// transaction, and lines the bank reported before are skipped, so uploading
+// the same or an overlapping statement again only picks up what is missing.
+func (s *BankStatementService) Import(ctx context.Context, format bankstmt.Format, content []byte) (bankstmt.Summary, error) {
+	if format == "" {
+		detected, err := bankstmt.Detect(content)
+		if err != nil {
+			return bankstmt.Summary{}, err
+		}
+		format = detected
+	}
+
+	lines, err := bankstmt.Parse(format, content)
+	if err != nil {
+		return bankstmt.Summary{}, err
+	}
+
+	st := bankstmt.Statement{
+		ID:         uuid.New(),
+		Format:     format,
+		FileHash:   bankstmt.Hash(content),
+		Lines:      lines,
+		ImportedAt: time.Now(),
+	}
+	st.ID, err = s.Store.InsertBankStatement(ctx, st)
+	if err != nil {
+		return bankstmt.Summary{}, err
+	}
+
+	summary := bankstmt.Summary{StatementID: st.ID, Lines: len(lines)}
+	for _, line := range lines {
+		status, err := s.importLine(ctx, st.ID, line)
+		if err != nil {
+			return summary, fmt.Errorf("line %s: %w", line.EntryRef, err)
+		}
+		switch status {
+		case bankstmt.Matched:
+			summary.Matched++
+		case bankstmt.Exception:
+			summary.Exceptions++
+		default:
+			summary.Duplicates++
+		}
+	}
+	return summary, nil
+}
+
+// importLine returns the status the line was stored with, or "" when it had
+// been imported before.
+func (s *BankStatementService) importLine(ctx context.Context, statementId uuid.UUID, line bankstmt.Line) (bankstmt.LineStatus, error) {
+	var status bankstmt.LineStatus
+	err := s.Tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+		status = ""
+
+		line := line
+		line.Status = bankstmt.Matched
+		line.WalletID, line.Reason = nil, ""
+
+		walletId, reason, err := s.match(ctxTx, line)
+		if err != nil {
+			return err
+		}
+		if walletId != uuid.Nil {
+			line.WalletID = &walletId
+		}
+		if reason != "" {
+			line.Status, line.Reason = bankstmt.Exception, reason
+		}
+
+		id, inserted, err := s.Store.InsertStatementLine(ctxTx, statementId, line)
+		if err != nil || !inserted {
+			return err
+		}
+		status = line.Status
+		if status != bankstmt.Matched {
+			return nil
+		}
+
+		if err := s.Wallets.DepositFunds(ctxTx, walletId, line.Amount); err != nil {
+			if !depositRejected(err) {
+				return err
+			}
+			status = bankstmt.Exception
+			return s.Store.UpdateStatementLine(ctxTx, id, bankstmt.Exception, line.WalletID, "deposit failed: "+err.Error(), "")
+		}
+		return nil
+	})
+	return status, err
+}
+
+// depositRejected reports whether the deposit was refused for a reason no
+// retry would change. Anything else, such as a serialization failure, a busy
+// wallet or a timeout, fails the import instead, so the line is stored again
+// and credited when the statement is uploaded again.
+func depositRejected(err error) bool {
+	msg := err.Error()
+	return msg == "wallet not found" || msg == "balance limit exceeded" || msg == "amount overflow" ||
+		strings.HasPrefix(msg, "amount must be")
+}
+
+// match picks the wallet a line should be credited to. A non-empty reason
+// means the line cannot be credited automatically; the wallet is still
+// returned when one was found so whoever resolves the line can see it.
+func (s *BankStatementService) match(ctx context.Context, line bankstmt.Line) (uuid.UUID, string, error) {
+	if !line.Credit {
+		return uuid.Nil, "debit entry", nil
+	}
+
+	walletId, ok := bankstmt.MatchWallet(line.Reference)
+	if !ok {
+		return uuid.Nil, "no wallet reference", nil
+	}
+
+	wl, err := s.Wallets.GetWallet(ctx, walletId)
+	if err != nil {
+		if err.Error() == "wallet not found" {
+			return uuid.Nil, "unknown wallet " + walletId.String(), nil
+		}
+		return uuid.Nil, "", err
+	}
+	if reason := creditable(wl, line); reason != "" {
+		return walletId, reason, nil
+	}
+	return walletId, "", nil
+}
+
+func creditable(wl wallet.Wallet, line bankstmt.Line) string {
+	if wl.Status != wallet.Active {
+		return "wallet is " + string(wl.Status)
+	}
+	if wl.Currency != line.Currency {
+		return fmt.Sprintf("currency mismatch: wallet %s, line %s", wl.Currency, line.Currency)
+	}
+	return ""
+}
+
+func (s *BankStatementService) ListExceptions(ctx context.Context, afterId int64, limit int) ([]bankstmt.Line, error) {
+	if afterId < 0 {
+		return nil, errors.New("invalid after parameter")
+	}
+	if limit < 1 || limit > MaxStatementExceptions {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxStatementExceptions)
+	}
+	return s.Store.ListStatementExceptions(ctx, afterId, limit)
+}
+
+// Resolve settles a line from the exceptions queue: credit deposits it into
+// walletId, or into the wallet matched on import when walletId is nil;
+// ignore closes the line without moving money.
+func (s *BankStatementService) Resolve(ctx context.Context, lineId int64, action ResolveAction, walletId *uuid.UUID, note string) (bankstmt.Line, error) {
+	if action != ResolveCredit && action != ResolveIgnore {
+		return bankstmt.Line{}, errors.New("action must be credit or ignore")
+	}
+	if err := checkText("note", note, MaxResolutionNote); err != nil {
+		return bankstmt.Line{}, err
+	}
+
+	var resolved bankstmt.Line
+	err := s.Tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+		line, err := s.Store.GetStatementLineForUpdate(ctxTx, lineId)
+		if err != nil {
+			return err
+		}
+		if line.Status != bankstmt.Exception {
+			return errors.New("statement line already resolved")
+		}
+
+		if action == ResolveIgnore {
+			line.Status, line.Note = bankstmt.Ignored, note
+			resolved = line
+			return s.Store.UpdateStatementLine(ctxTx, line.ID, line.Status, line.WalletID, line.Reason, note)
+		}
+
+		if !line.Credit {
+			return errors.New("only credit lines can be credited")
+		}
+		if walletId == nil {
+			walletId = line.WalletID
+		}
+		if walletId == nil {
+			return errors.New("walletId is required")
+		}
+
+		wl, err := s.Wallets.GetWallet(ctxTx, *walletId)
+		if err != nil {
+			return err
+		}
+		if reason := creditable(wl, line); reason != "" {
+			return errors.New(reason)
+		}
+		if err := s.Wallets.DepositFunds(ctxTx, *walletId, line.Amount); err != nil {
+			return err
+		}
+
+		line.Status, line.WalletID, line.Note = bankstmt.Resolved, walletId, note
+		resolved = line
+		return s.Store.UpdateStatementLine(ctxTx, line.ID, line.Status, line.WalletID, line.Reason, note)
+	})
+	return resolved, err
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"project/internal/bankstmt"
+	"project/internal/contention"
+	"project/internal/wallet"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeBankTx struct{ calls int }
+
+func (f *fakeBankTx) RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+type fakeBankStore struct {
+	statements map[string]uuid.UUID
+	lines      []bankstmt.Line
+}
+
+func newFakeBankStore() *fakeBankStore {
+	return &fakeBankStore{statements: map[string]uuid.UUID{}}
+}
+
+func (f *fakeBankStore) InsertBankStatement(ctx context.Context, st bankstmt.Statement) (uuid.UUID, error) {
+	if id, ok := f.statements[st.FileHash]; ok {
+		return id, nil
+	}
+	f.statements[st.FileHash] = st.ID
+	return st.ID, nil
+}
+
+func (f *fakeBankStore) InsertStatementLine(ctx context.Context, statementId uuid.UUID, line bankstmt.Line) (int64, bool, error) {
+	for _, l := range f.lines {
+		if l.EntryRef == line.EntryRef {
+			return 0, false, nil
+		}
+	}
+	line.ID = int64(len(f.lines) + 1)
+	f.lines = append(f.lines, line)
+	return line.ID, true, nil
+}
+
+func (f *fakeBankStore) GetStatementLineForUpdate(ctx context.Context, id int64) (bankstmt.Line, error) {
+	if id < 1 || id > int64(len(f.lines)) {
+		return bankstmt.Line{}, errAny("statement line not found")
+	}
+	return f.lines[id-1], nil
+}
+
+func (f *fakeBankStore) UpdateStatementLine(ctx context.Context, id int64, status bankstmt.LineStatus, walletId *uuid.UUID, reason, note string) error {
+	l := &f.lines[id-1]
+	l.Status, l.WalletID, l.Reason, l.Note = status, walletId, reason, note
+	return nil
+}
+
+func (f *fakeBankStore) ListStatementExceptions(ctx context.Context, afterId int64, limit int) ([]bankstmt.Line, error) {
+	var out []bankstmt.Line
+	for _, l := range f.lines {
+		if l.ID > afterId && l.Status == bankstmt.Exception && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func bankMT940(lines ...string) []byte {
+	return []byte(":20:STMT\n:25:DE89370400440532013000\n:60F:C251001EUR0,00\n" + strings.Join(lines, "\n") + "\n:62F:C251001EUR0,00\n")
+}
+
+func newBankService(wallets map[uuid.UUID]wallet.Wallet) (*BankStatementService, *fakeBankStore, *mockFacade) {
+	m := &mockFacade{
+		OnGetWallet: func(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+			if wl, ok := wallets[walletId]; ok {
+				return wl, nil
+			}
+			return wallet.Wallet{}, errAny("wallet not found")
+		},
+	}
+	store := newFakeBankStore()
+	return NewBankStatementService(&fakeBankTx{}, store, NewWalletService(m)), store, m
+}
+
+func TestBankStatementImport(t *testing.T) {
+	active := uuid.New()
+	frozen := uuid.New()
+	wallets := map[uuid.UUID]wallet.Wallet{
+		active: {ID: active, Status: wallet.Active, Currency: "EUR"},
+		frozen: {ID: frozen, Status: wallet.Frozen, Currency: "EUR"},
+	}
+
+	content := bankMT940(
+		":61:2510011001C10,00NTRFNONREF//A1", ":86:wallet "+active.String(),
+		":61:2510011001C5,00NTRFNONREF//A2", ":86:invoice 42",
+		":61:2510011001C7,00NTRFNONREF//A3", ":86:"+frozen.String(),
+		":61:2510011001C8,00NTRFNONREF//A4", ":86:"+uuid.New().String(),
+		":61:2510011001D1,00NCHGNONREF//A5", ":86:fee",
+	)
+
+	t.Run("credits matched lines and queues the rest", func(t *testing.T) {
+		s, store, m := newBankService(wallets)
+		var credited []int64
+		m.OnDeposit = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+			require.Equal(t, active, walletId)
+			credited = append(credited, amount)
+			return nil
+		}
+
+		summary, err := s.Import(context.Background(), "", content)
+		require.NoError(t, err)
+		require.Equal(t, 5, summary.Lines)
+		require.Equal(t, 1, summary.Matched)
+		require.Equal(t, 4, summary.Exceptions)
+		require.Equal(t, []int64{1000}, credited)
+
+		reasons := map[string]string{}
+		for _, l := range store.lines {
+			reasons[l.EntryRef[strings.LastIndex(l.EntryRef, ":")+1:]] = l.Reason
+		}
+		require.Equal(t, "", reasons["A1"])
+		require.Equal(t, "no wallet reference", reasons["A2"])
+		require.Equal(t, "wallet is FROZEN", reasons["A3"])
+		require.Contains(t, reasons["A4"], "unknown wallet")
+		require.Equal(t, "debit entry", reasons["A5"])
+		require.Equal(t, &frozen, store.lines[2].WalletID)
+		require.Nil(t, store.lines[3].WalletID)
+
+		again, err := s.Import(context.Background(), bankstmt.MT940, content)
+		require.NoError(t, err)
+		require.Equal(t, summary.StatementID, again.StatementID)
+		require.Equal(t, 5, again.Duplicates)
+		require.Equal(t, 1, m.depositCalls)
+	})
+
+	t.Run("failed deposit becomes an exception", func(t *testing.T) {
+		s, store, m := newBankService(wallets)
+		m.OnDeposit = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+			return errAny("balance limit exceeded")
+		}
+
+		summary, err := s.Import(context.Background(), bankstmt.MT940, bankMT940(
+			":61:2510011001C10,00NTRFNONREF//A1", ":86:"+active.String()))
+		require.NoError(t, err)
+		require.Equal(t, 0, summary.Matched)
+		require.Equal(t, 1, summary.Exceptions)
+		require.Equal(t, bankstmt.Exception, store.lines[0].Status)
+		require.Equal(t, "deposit failed: balance limit exceeded", store.lines[0].Reason)
+	})
+
+	t.Run("transient deposit error fails the import", func(t *testing.T) {
+		s, store, m := newBankService(wallets)
+		m.OnDeposit = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+			return &contention.BusyError{RetryAfter: time.Second, Cause: context.DeadlineExceeded}
+		}
+
+		_, err := s.Import(context.Background(), bankstmt.MT940, bankMT940(
+			":61:2510011001C10,00NTRFNONREF//A1", ":86:"+active.String()))
+		var busy *contention.BusyError
+		require.ErrorAs(t, err, &busy)
+		require.Equal(t, bankstmt.Matched, store.lines[0].Status)
+		require.Empty(t, store.lines[0].Reason)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		s, _, _ := newBankService(wallets)
+		_, err := s.Import(context.Background(), "", []byte("hello"))
+		require.EqualError(t, err, "unknown statement format")
+	})
+}
+
+func TestBankStatementResolve(t *testing.T) {
+	target := uuid.New()
+	wallets := map[uuid.UUID]wallet.Wallet{
+		target: {ID: target, Status: wallet.Active, Currency: "EUR"},
+	}
+	content := bankMT940(
+		":61:2510011001C5,00NTRFNONREF//A1", ":86:invoice 42",
+		":61:2510011001D1,00NCHGNONREF//A2", ":86:fee",
+	)
+
+	s, store, m := newBankService(wallets)
+	_, err := s.Import(context.Background(), "", content)
+	require.NoError(t, err)
+
+	exceptions, err := s.ListExceptions(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, exceptions, 2)
+
+	_, err = s.Resolve(context.Background(), 1, ResolveCredit, nil, "")
+	require.EqualError(t, err, "walletId is required")
+
+	_, err = s.Resolve(context.Background(), 2, ResolveCredit, &target, "")
+	require.EqualError(t, err, "only credit lines can be credited")
+
+	_, err = s.Resolve(context.Background(), 1, "refund", &target, "")
+	require.EqualError(t, err, "action must be credit or ignore")
+
+	line, err := s.Resolve(context.Background(), 1, ResolveCredit, &target, "customer called")
+	require.NoError(t, err)
+	require.Equal(t, bankstmt.Resolved, line.Status)
+	require.Equal(t, &target, line.WalletID)
+	require.Equal(t, 1, m.depositCalls)
+	require.Equal(t, "customer called", store.lines[0].Note)
+
+	_, err = s.Resolve(context.Background(), 1, ResolveIgnore, nil, "")
+	require.EqualError(t, err, "statement line already resolved")
+
+	line, err = s.Resolve(context.Background(), 2, ResolveIgnore, nil, "bank fee")
+	require.NoError(t, err)
+	require.Equal(t, bankstmt.Ignored, line.Status)
+
+	_, err = s.Resolve(context.Background(), 9, ResolveIgnore, nil, "")
+	require.EqualError(t, err, "statement line not found")
+
+	exceptions, err = s.ListExceptions(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, exceptions)
+
+	_, err = s.ListExceptions(context.Background(), 0, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"project/internal/ledger"
+	"project/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const basisPoints = 10000
+
+// FeeTier applies RateBps once the wallet's withdrawals and outgoing
+// transfers in the current calendar month (UTC) reach FromVolume.
+type FeeTier struct {
+	FromVolume int64 `json:"fromVolume"`
+	RateBps    int64 `json:"rateBps"`
+}
+
+// FeeRule prices an operation as Flat plus a percentage of its amount. The
+// percentage, in basis points, is RateBps or the rate of the highest tier
+// the monthly volume has reached, and the fee it yields is held between Min
+// and Max; a zero Max means no cap.
+type FeeRule struct {
+	Flat    int64     `json:"flat"`
+	RateBps int64     `json:"rateBps"`
+	Min     int64     `json:"min"`
+	Max     int64     `json:"max"`
+	Tiers   []FeeTier `json:"tiers"`
+}
+
+// FeeSchedule is the fee configuration. Fees are credited to RevenueWallet,
+// which is itself never charged. Withdraw covers the withdrawals of batches
+// and CSV imports, and payouts too, since they are withdrawals to a bank
+// account. Transfer prices transfers between wallets, paid by the sender.
+type FeeSchedule struct {
+	RevenueWallet uuid.UUID `json:"revenueWalletId"`
+	Withdraw      FeeRule   `json:"withdraw"`
+	Transfer      FeeRule   `json:"transfer"`
+}
+
+// FeeQuote is what an operation of Amount would cost right now.
+type FeeQuote struct {
+	Amount        int64 `json:"amount"`
+	Fee           int64 `json:"fee"`
+	Total         int64 `json:"total"`
+	RateBps       int64 `json:"rateBps"`
+	MonthlyVolume int64 `json:"monthlyVolume"`
+}
+
+func LoadFeeSchedule(path string) (FeeSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return ParseFeeSchedule(data)
+}
+
+func ParseFeeSchedule(data []byte) (FeeSchedule, error) {
+	var s FeeSchedule
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return FeeSchedule{}, fmt.Errorf("invalid fee schedule: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return FeeSchedule{}, err
+	}
+	return s, nil
+}
+
+func (s FeeSchedule) Validate() error {
+	if err := s.Withdraw.Validate(); err != nil {
+		return fmt.Errorf("withdraw: %w", err)
+	}
+	if err := s.Transfer.Validate(); err != nil {
+		return fmt.Errorf("transfer: %w", err)
+	}
+	if (!s.Withdraw.Free() || !s.Transfer.Free()) && s.RevenueWallet == uuid.Nil {
+		return errors.New("revenueWalletId is required")
+	}
+	return nil
+}
+
+func (r FeeRule) Validate() error {
+	if r.Flat < 0 || r.Min < 0 || r.Max < 0 {
+		return errors.New("fee amounts must not be negative")
+	}
+	if r.Max > 0 && r.Max < r.Min {
+		return errors.New("max must not be below min")
+	}
+	if !validRate(r.RateBps) {
+		return errors.New("rateBps must be between 0 and 10000")
+	}
+	for i, t := range r.Tiers {
+		if !validRate(t.RateBps) {
+			return fmt.Errorf("tiers[%d]: rateBps must be between 0 and 10000", i)
+		}
+		if t.FromVolume < 0 || (i > 0 && t.FromVolume <= r.Tiers[i-1].FromVolume) {
+			return fmt.Errorf("tiers[%d]: fromVolume must be ascending and not negative", i)
+		}
+	}
+	return nil
+}
+
+func validRate(bps int64) bool {
+	return bps >= 0 && bps <= basisPoints
+}
+
+// Free reports whether the rule never charges anything.
+func (r FeeRule) Free() bool {
+	if r.Flat > 0 || r.Min > 0 || r.RateBps > 0 {
+		return false
+	}
+	for _, t := range r.Tiers {
+		if t.RateBps > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Rate is the percentage, in basis points, charged at the given monthly
+// volume.
+func (r FeeRule) Rate(volume int64) int64 {
+	rate := r.RateBps
+	for _, t := range r.Tiers {
+		if volume < t.FromVolume {
+			break
+		}
+		rate = t.RateBps
+	}
+	return rate
+}
+
+// Calculate prices amount at the given monthly volume. The percentage is
+// rounded half up to the currency's minor unit.
+func (r FeeRule) Calculate(amount, volume int64) (int64, error) {
+	pct := new(big.Int).Mul(big.NewInt(amount), big.NewInt(r.Rate(volume)))
+	pct.Add(pct, big.NewInt(basisPoints/2))
+	pct.Quo(pct, big.NewInt(basisPoints))
+	if !pct.IsInt64() {
+		return 0, money.ErrOverflow
+	}
+
+	variable := pct.Int64()
+	if variable < r.Min {
+		variable = r.Min
+	}
+	if r.Max > 0 && variable > r.Max {
+		variable = r.Max
+	}
+
+	fee, err := money.Amount(r.Flat).Add(money.Amount(variable))
+	return int64(fee), err
+}
+
+// QuoteWithdraw previews the fee WithdrawFunds would charge for amount.
+func (ws *WalletService) QuoteWithdraw(ctx context.Context, walletId uuid.UUID, amount int64) (FeeQuote, error) {
+	if err := ws.Limits.CheckWithdraw(amount); err != nil {
+		return FeeQuote{}, err
+	}
+	return ws.quote(ctx, ws.Fees.Withdraw, walletId, amount)
+}
+
+// QuoteTransfer previews the fee TransferFunds would charge the sender for
+// amount.
+func (ws *WalletService) QuoteTransfer(ctx context.Context, walletId uuid.UUID, amount int64) (FeeQuote, error) {
+	if err := ws.Limits.CheckWithdraw(amount); err != nil {
+		return FeeQuote{}, err
+	}
+	return ws.quote(ctx, ws.Fees.Transfer, walletId, amount)
+}
+
+// WithdrawFee is the fee WithdrawFunds would charge for amount, for
+// withdrawals that are applied elsewhere, such as by the CSV importer.
+func (ws *WalletService) WithdrawFee(ctx context.Context, walletId uuid.UUID, amount int64) (ledger.FeeCharge, error) {
+	q, err := ws.quote(ctx, ws.Fees.Withdraw, walletId, amount)
+	if err != nil {
+		return ledger.FeeCharge{}, err
+	}
+	return ws.fee(q), nil
+}
+
+func (ws *WalletService) quote(ctx context.Context, rule FeeRule, walletId uuid.UUID, amount int64) (FeeQuote, error) {
+	q := FeeQuote{Amount: amount, Total: amount}
+	if rule.Free() || walletId == ws.Fees.RevenueWallet {
+		return q, nil
+	}
+
+	if len(rule.Tiers) > 0 {
+		volume, err := ws.Repo.GetWithdrawnSince(ctx, walletId, monthStart(time.Now()))
+		if err != nil {
+			return FeeQuote{}, err
+		}
+		q.MonthlyVolume = volume
+	}
+	q.RateBps = rule.Rate(q.MonthlyVolume)
+
+	fee, err := rule.Calculate(amount, q.MonthlyVolume)
+	if err != nil {
+		return FeeQuote{}, err
+	}
+	total, err := money.Amount(amount).Add(money.Amount(fee))
+	if err != nil {
+		return FeeQuote{}, err
+	}
+	q.Fee, q.Total = fee, int64(total)
+	return q, nil
+}
+
+func (ws *WalletService) fee(q FeeQuote) ledger.FeeCharge {
+	return ledger.FeeCharge{Amount: q.Fee, Revenue: ws.Fees.RevenueWallet}
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"project/internal/batch"
+	"project/internal/ledger"
+
+	"github.com/google/uuid"
+)
+
+func TestFeeRuleCalculate(t *testing.T) {
+	cases := []struct {
+		name   string
+		rule   FeeRule
+		amount int64
+		volume int64
+		want   int64
+	}{
+		{"flat", FeeRule{Flat: 25}, 1000, 0, 25},
+		{"percentage rounds half up", FeeRule{RateBps: 150}, 1030, 0, 15},
+		{"percentage below min", FeeRule{RateBps: 100, Min: 50}, 1000, 0, 50},
+		{"percentage above max", FeeRule{RateBps: 100, Max: 500}, 100000, 0, 500},
+		{"flat plus capped percentage", FeeRule{Flat: 10, RateBps: 100, Max: 500}, 100000, 0, 510},
+		{"below first tier", tiered(), 1000, 9999, 20},
+		{"first tier", tiered(), 1000, 10000, 10},
+		{"top tier", tiered(), 1000, 1000000, 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fee, err := c.rule.Calculate(c.amount, c.volume)
+			require.NoError(t, err)
+			require.Equal(t, c.want, fee)
+		})
+	}
+
+	_, err := FeeRule{Flat: 1 << 62, Min: 1 << 62}.Calculate(1, 0)
+	require.EqualError(t, err, "amount overflow")
+}
+
+func tiered() FeeRule {
+	return FeeRule{RateBps: 200, Tiers: []FeeTier{{FromVolume: 10000, RateBps: 100}, {FromVolume: 500000, RateBps: 50}}}
+}
+
+func TestParseFeeSchedule(t *testing.T) {
+	revenue := uuid.New()
+	s, err := ParseFeeSchedule([]byte(`{"revenueWalletId":"` + revenue.String() + `",
+		"withdraw":{"flat":25,"rateBps":100,"min":50,"max":500,"tiers":[{"fromVolume":100000,"rateBps":50}]}}`))
+	require.NoError(t, err)
+	require.Equal(t, revenue, s.RevenueWallet)
+	require.Equal(t, FeeRule{Flat: 25, RateBps: 100, Min: 50, Max: 500, Tiers: []FeeTier{{FromVolume: 100000, RateBps: 50}}}, s.Withdraw)
+
+	_, err = ParseFeeSchedule([]byte(`{"withdraw":{"flat":25}}`))
+	require.EqualError(t, err, "revenueWalletId is required")
+
+	_, err = ParseFeeSchedule([]byte(`{"revenueWalletId":"` + revenue.String() + `","withdraw":{"rateBps":10001}}`))
+	require.EqualError(t, err, "withdraw: rateBps must be between 0 and 10000")
+
+	_, err = ParseFeeSchedule([]byte(`{"revenueWalletId":"` + revenue.String() + `","withdraw":{"min":50,"max":10}}`))
+	require.EqualError(t, err, "withdraw: max must not be below min")
+
+	_, err = ParseFeeSchedule([]byte(`{"revenueWalletId":"` + revenue.String() + `",
+		"withdraw":{"tiers":[{"fromVolume":100,"rateBps":50},{"fromVolume":100,"rateBps":40}]}}`))
+	require.EqualError(t, err, "withdraw: tiers[1]: fromVolume must be ascending and not negative")
+
+	_, err = ParseFeeSchedule([]byte(`{"deposit":{}}`))
+	require.ErrorContains(t, err, "invalid fee schedule")
+
+	s, err = ParseFeeSchedule([]byte(`{}`))
+	require.NoError(t, err)
+	require.True(t, s.Withdraw.Free())
+}
+
+func TestWithdrawFunds_ChargesFee(t *testing.T) {
+	revenue := uuid.New()
+	m := &mockFacade{}
+	var since time.Time
+	m.OnWithdrawn = func(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+		since = at
+		return 20000, nil
+	}
+	ws := NewWalletService(m)
+	ws.Fees = FeeSchedule{RevenueWallet: revenue, Withdraw: FeeRule{Flat: 5, RateBps: 200, Tiers: []FeeTier{{FromVolume: 10000, RateBps: 100}}}}
+
+	q, err := ws.QuoteWithdraw(context.Background(), uuid.New(), 1000)
+	require.NoError(t, err)
+	require.Equal(t, FeeQuote{Amount: 1000, Fee: 15, Total: 1015, RateBps: 100, MonthlyVolume: 20000}, q)
+	require.Equal(t, 1, since.Day())
+	require.Zero(t, m.withdrawCalls)
+
+	fee, err := ws.WithdrawFunds(context.Background(), uuid.New(), 1000)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), fee)
+	require.Equal(t, ledger.FeeCharge{Amount: 15, Revenue: revenue}, m.lastFee)
+
+	// The revenue wallet pays no fees of its own.
+	fee, err = ws.WithdrawFunds(context.Background(), revenue, 1000)
+	require.NoError(t, err)
+	require.Zero(t, fee)
+	require.Equal(t, ledger.FeeCharge{Revenue: revenue}, m.lastFee)
+
+	_, err = ws.QuoteWithdraw(context.Background(), uuid.New(), 0)
+	require.EqualError(t, err, "amount must be positive")
+}
+
+func TestProcessBatch_ChargesWithdrawals(t *testing.T) {
+	revenue, id := uuid.New(), uuid.New()
+	m := &mockFacade{}
+	var applied []batch.Operation
+	m.OnBatch = func(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+		applied = ops
+		return batch.Batch{Mode: mode, Status: batch.Completed}, nil
+	}
+	ws := NewWalletService(m)
+	ws.Fees = FeeSchedule{RevenueWallet: revenue, Withdraw: FeeRule{Flat: 5}}
+
+	_, err := ws.ProcessBatch(context.Background(), batch.Atomic, []batch.Operation{
+		{WalletID: id, OperationType: ledger.Deposit, Amount: 100},
+		{WalletID: id, OperationType: ledger.Withdraw, Amount: 50},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ledger.FeeCharge{}, applied[0].Fee)
+	require.Equal(t, ledger.FeeCharge{Amount: 5, Revenue: revenue}, applied[1].Fee)
+}
+
+func TestTransferFunds_ChargesSender(t *testing.T) {
+	revenue, from, to := uuid.New(), uuid.New(), uuid.New()
+	m := &mockFacade{}
+	ws := NewWalletService(m)
+	ws.Fees = FeeSchedule{RevenueWallet: revenue, Transfer: FeeRule{RateBps: 100, Min: 3}}
+
+	q, err := ws.QuoteTransfer(context.Background(), from, 1000)
+	require.NoError(t, err)
+	require.Equal(t, FeeQuote{Amount: 1000, Fee: 10, Total: 1010, RateBps: 100}, q)
+
+	fee, err := ws.TransferFunds(context.Background(), from, to, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), fee)
+	require.Equal(t, to, m.lastTransferTo)
+	require.Equal(t, ledger.FeeCharge{Amount: 3, Revenue: revenue}, m.lastFee)
+
+	_, err = ws.TransferFunds(context.Background(), from, from, 100)
+	require.EqualError(t, err, "cannot transfer to the same wallet")
+	_, err = ws.TransferFunds(context.Background(), from, uuid.Nil, 100)
+	require.EqualError(t, err, "toWalletId parameter is required")
+	require.Equal(t, 1, m.withdrawCalls)
+
+	_, err = ParseFeeSchedule([]byte(`{"transfer":{"flat":1}}`))
+	require.EqualError(t, err, "revenueWalletId is required")
+}
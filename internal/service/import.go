@@ -0,0 +1,84 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"project/internal/importer"
+	"project/internal/money"
+
+	"github.com/google/uuid"
+)
+
+type ImportService struct {
+	Store  importer.Store
+	Runner *importer.Runner
+	Limits money.Limits
+}
+
+func NewImportService(store importer.Store, runner *importer.Runner) *ImportService {
+	return &ImportService{
+		Store:  store,
+		Runner: runner,
+	}
+}
+
+// Submit validates the whole file and schedules it for processing. Uploading
+// a file with the same content again returns the existing job instead of
+// creating a new one, unless that job failed: then it is run again from
+// where it stopped. The second result reports whether a job was scheduled.
+func (s *ImportService) Submit(ctx context.Context, content []byte) (importer.Job, bool, error) {
+	hash := importer.Hash(content)
+
+	existing, err := s.Store.GetImportJobByHash(ctx, hash)
+	if err != nil && err.Error() != "import job not found" {
+		return importer.Job{}, false, err
+	}
+	if err == nil && existing.Status != importer.Failed {
+		return existing, false, nil
+	}
+
+	rows, err := importer.Parse(bytes.NewReader(content), s.Limits)
+	if err != nil {
+		return importer.Job{}, false, err
+	}
+
+	if existing.Status == importer.Failed {
+		return s.restart(ctx, existing)
+	}
+
+	job := importer.NewJob(hash, len(rows))
+	if err := s.Store.InsertImportJob(ctx, job, content); err != nil {
+		if err.Error() == "import already exists" {
+			existing, err := s.Store.GetImportJobByHash(ctx, hash)
+			return existing, false, err
+		}
+		return importer.Job{}, false, err
+	}
+
+	if s.Runner != nil {
+		s.Runner.Enqueue(job.ID)
+	}
+	return job, true, nil
+}
+
+// restart reschedules a failed job. Rows it applied before failing are
+// skipped by their external_ref when it runs again.
+func (s *ImportService) restart(ctx context.Context, failed importer.Job) (importer.Job, bool, error) {
+	job, err := s.Store.RestartImportJob(ctx, failed.ID)
+	if err != nil {
+		if err.Error() == "import job not restartable" {
+			existing, err := s.Store.GetImportJob(ctx, failed.ID)
+			return existing, false, err
+		}
+		return importer.Job{}, false, err
+	}
+
+	if s.Runner != nil {
+		s.Runner.Enqueue(job.ID)
+	}
+	return job, true, nil
+}
+
+func (s *ImportService) Get(ctx context.Context, id uuid.UUID) (importer.Job, error) {
+	return s.Store.GetImportJob(ctx, id)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"project/internal/importer"
+	"project/internal/money"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type mockImportStore struct {
+	importer.Store
+
+	byHash    map[string]importer.Job
+	inserted  int
+	restarted int
+}
+
+func (m *mockImportStore) GetImportJobByHash(ctx context.Context, fileHash string) (importer.Job, error) {
+	if job, ok := m.byHash[fileHash]; ok {
+		return job, nil
+	}
+	return importer.Job{}, errAny("import job not found")
+}
+
+func (m *mockImportStore) InsertImportJob(ctx context.Context, job importer.Job, content []byte) error {
+	m.inserted++
+	m.byHash[job.FileHash] = job
+	return nil
+}
+
+func (m *mockImportStore) RestartImportJob(ctx context.Context, id uuid.UUID) (importer.Job, error) {
+	for hash, job := range m.byHash {
+		if job.ID == id && job.Status == importer.Failed {
+			m.restarted++
+			job.Status, job.Error = importer.Pending, ""
+			m.byHash[hash] = job
+			return job, nil
+		}
+	}
+	return importer.Job{}, errAny("import job not restartable")
+}
+
+func TestImportSubmit(t *testing.T) {
+	content := []byte("wallet_id,type,amount,external_ref\n" + uuid.New().String() + ",DEPOSIT,10,r-1\n")
+
+	t.Run("creates a job once per file", func(t *testing.T) {
+		store := &mockImportStore{byHash: map[string]importer.Job{}}
+		s := NewImportService(store, nil)
+
+		first, created, err := s.Submit(context.Background(), content)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, importer.Pending, first.Status)
+		require.Equal(t, 1, first.TotalRows)
+
+		again, created, err := s.Submit(context.Background(), content)
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, first.ID, again.ID)
+		require.Equal(t, 1, store.inserted)
+	})
+
+	t.Run("a failed job runs again", func(t *testing.T) {
+		store := &mockImportStore{byHash: map[string]importer.Job{}}
+		s := NewImportService(store, nil)
+
+		first, _, err := s.Submit(context.Background(), content)
+		require.NoError(t, err)
+		failed := store.byHash[first.FileHash]
+		failed.Status, failed.Error, failed.ProcessedRows = importer.Failed, "not enough balance on line 2", 0
+		store.byHash[first.FileHash] = failed
+
+		again, created, err := s.Submit(context.Background(), content)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, first.ID, again.ID)
+		require.Equal(t, importer.Pending, again.Status)
+		require.Equal(t, 1, store.inserted)
+		require.Equal(t, 1, store.restarted)
+	})
+
+	t.Run("invalid file creates no job", func(t *testing.T) {
+		store := &mockImportStore{byHash: map[string]importer.Job{}}
+		s := NewImportService(store, nil)
+
+		_, _, err := s.Submit(context.Background(), []byte("wallet_id,type,amount,external_ref\nx,DEPOSIT,1,r\n"))
+
+		var validationErr *importer.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Equal(t, 0, store.inserted)
+	})
+
+	t.Run("amounts are held to the limits", func(t *testing.T) {
+		store := &mockImportStore{byHash: map[string]importer.Job{}}
+		s := NewImportService(store, nil)
+		s.Limits = money.Limits{Deposit: money.Bounds{Max: 5}}
+
+		_, _, err := s.Submit(context.Background(), content)
+
+		var validationErr *importer.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Equal(t, "amount must be at most 5", validationErr.Errors[0].Message)
+		require.Equal(t, 0, store.inserted)
+	})
+}
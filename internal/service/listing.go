@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/wallet"
+)
+
+const MaxListWallets = 1000
+
+func (ws *WalletService) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	if q.Limit <= 0 || q.Limit > MaxListWallets {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxListWallets)
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = wallet.SortCreatedAt
+	case wallet.SortCreatedAt, wallet.SortBalance, wallet.SortWalletID:
+	default:
+		return nil, errors.New("invalid sort parameter")
+	}
+
+	f := q.Filter
+	switch f.Status {
+	case "", wallet.Active, wallet.Frozen, wallet.Closed:
+	default:
+		return nil, errors.New("invalid status parameter")
+	}
+	if f.Currency != "" && !validCurrency(f.Currency) {
+		return nil, errors.New("invalid currency parameter")
+	}
+	if f.MinBalance != nil && f.MaxBalance != nil && *f.MinBalance > *f.MaxBalance {
+		return nil, errors.New("minBalance must not exceed maxBalance")
+	}
+	if !f.CreatedFrom.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedFrom.Before(f.CreatedBefore) {
+		return nil, errors.New("createdFrom must be before createdBefore")
+	}
+
+	return ws.Repo.ListWallets(ctx, q)
+}
+
+func validCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
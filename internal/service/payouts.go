@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/ledger"
+	"project/internal/payout"
+	"project/internal/wallet"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxBeneficiaryName = 70
+	MaxPayoutReference = 140
+)
+
+type PayoutService struct {
+	Tx      payout.TxRunner
+	Store   payout.Store
+	Wallets *WalletService
+}
+
+func NewPayoutService(tx payout.TxRunner, store payout.Store, wallets *WalletService) *PayoutService {
+	return &PayoutService{
+		Tx:      tx,
+		Store:   store,
+		Wallets: wallets,
+	}
+}
+
+// Request withdraws amount from the wallet and holds it in a pending payout
+// to the beneficiary's account, in the wallet's currency. The withdrawal fee
+// is charged on top and recorded on the payout.
+func (s *PayoutService) Request(ctx context.Context, walletId uuid.UUID, amount int64, b payout.Beneficiary, reference string) (payout.Payout, error) {
+	b.Name = strings.TrimSpace(b.Name)
+	b.IBAN = payout.NormalizeIBAN(b.IBAN)
+	b.BIC = strings.ToUpper(strings.TrimSpace(b.BIC))
+
+	if b.Name == "" {
+		return payout.Payout{}, errors.New("beneficiary name is required")
+	}
+	if err := checkText("beneficiary name", b.Name, MaxBeneficiaryName); err != nil {
+		return payout.Payout{}, err
+	}
+	if !payout.ValidIBAN(b.IBAN) {
+		return payout.Payout{}, errors.New("beneficiary IBAN is invalid")
+	}
+	if b.BIC != "" && !payout.ValidBIC(b.BIC) {
+		return payout.Payout{}, errors.New("beneficiary BIC is invalid")
+	}
+	if err := checkText("reference", reference, MaxPayoutReference); err != nil {
+		return payout.Payout{}, err
+	}
+
+	var p payout.Payout
+	err := s.Tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+		wl, err := s.Wallets.GetWallet(ctxTx, walletId)
+		if err != nil {
+			return err
+		}
+		if wl.Status != wallet.Active {
+			return fmt.Errorf("wallet is %s", wl.Status)
+		}
+
+		p = payout.NewPayout(walletId, amount, wl.Currency, b, reference)
+		p.Fee, err = s.Wallets.WithdrawFundsRef(ctxTx, walletId, amount, p.WithdrawalRef())
+		if err != nil {
+			return err
+		}
+		return s.Store.InsertPayout(ctxTx, p)
+	})
+	if err != nil {
+		return payout.Payout{}, err
+	}
+	return p, nil
+}
+
+func (s *PayoutService) Get(ctx context.Context, id uuid.UUID) (payout.Payout, error) {
+	return s.Store.GetPayout(ctx, id)
+}
+
+// Export moves up to limit pending payouts into a new pain.001 file, to be
+// executed by the bank on the given day. The file is stored with the
+// payouts, so it can be fetched again if handing it over fails.
+func (s *PayoutService) Export(ctx context.Context, debtor payout.Debtor, limit int, execution time.Time) (payout.File, error) {
+	if err := debtor.Validate(); err != nil {
+		return payout.File{}, err
+	}
+	if limit < 1 {
+		return payout.File{}, errors.New("limit must be positive")
+	}
+
+	var file payout.File
+	err := s.Tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+		pending, err := s.Store.ListPendingPayouts(ctxTx, limit)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return errors.New("no pending payouts")
+		}
+
+		file = payout.File{ID: uuid.New(), Payouts: len(pending), CreatedAt: time.Now()}
+		file.MessageID = payout.MessageID(file.ID)
+		file.Content, file.ControlSum, err = payout.BuildPain001(file.MessageID, debtor, pending, execution, file.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.Store.InsertPayoutFile(ctxTx, file); err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(pending))
+		for i, p := range pending {
+			ids[i] = p.ID
+		}
+		return s.Store.MarkPayoutsExported(ctxTx, file.ID, ids)
+	})
+	if err != nil {
+		return payout.File{}, err
+	}
+	return file, nil
+}
+
+func (s *PayoutService) GetFile(ctx context.Context, id uuid.UUID) (payout.File, error) {
+	return s.Store.GetPayoutFile(ctx, id)
+}
+
+// StatusSummary counts what applying a status report did to the payouts
+// of the file it answers.
+type StatusSummary struct {
+	FileID    uuid.UUID `json:"fileId"`
+	Accepted  int       `json:"accepted"`
+	Confirmed int       `json:"confirmed"`
+	Rejected  int       `json:"rejected"`
+	Open      int       `json:"open"`
+	Unchanged int       `json:"unchanged"`
+}
+
+// ApplyStatusReport settles the exported payouts a pain.002 report gives a
+// final status: confirmed payouts are done, rejected ones are refunded to
+// their wallet. Payouts the bank has accepted but not settled are marked
+// accepted and can still be rejected by a later report. Payouts already
+// settled are left alone, so a report can be applied again.
+func (s *PayoutService) ApplyStatusReport(ctx context.Context, content []byte) (StatusSummary, error) {
+	report, err := payout.ParseStatusReport(content)
+	if err != nil {
+		return StatusSummary{}, err
+	}
+
+	var summary StatusSummary
+	err = s.Tx.RunSerializable(ctx, func(ctxTx context.Context) error {
+		file, err := s.Store.GetPayoutFileByMessageID(ctxTx, report.OriginalMessageID)
+		if err != nil {
+			return err
+		}
+		payouts, err := s.Store.ListFilePayoutsForUpdate(ctxTx, file.ID)
+		if err != nil {
+			return err
+		}
+
+		summary = StatusSummary{FileID: file.ID}
+		for _, p := range payouts {
+			if p.Status != payout.Exported && p.Status != payout.Accepted {
+				summary.Unchanged++
+				continue
+			}
+
+			status := report.StatusOf(p)
+			switch status.Outcome() {
+			case payout.Accepted:
+				if p.Status == payout.Accepted {
+					summary.Unchanged++
+					continue
+				}
+				if err := s.Store.UpdatePayoutStatus(ctxTx, p.ID, payout.Accepted, ""); err != nil {
+					return err
+				}
+				summary.Accepted++
+			case payout.Confirmed:
+				if err := s.Store.UpdatePayoutStatus(ctxTx, p.ID, payout.Confirmed, ""); err != nil {
+					return err
+				}
+				summary.Confirmed++
+			case payout.Rejected:
+				fee := ledger.FeeCharge{Amount: p.Fee, Revenue: s.Wallets.Fees.RevenueWallet}
+				if err := s.Wallets.Repo.Refund(ctxTx, p.WalletID, p.Amount, fee, p.RefundRef()); err != nil {
+					return fmt.Errorf("refund payout %s: %w", p.ID, err)
+				}
+				reason := status.Reason
+				if reason == "" {
+					reason = "rejected by bank"
+				}
+				if err := s.Store.UpdatePayoutStatus(ctxTx, p.ID, payout.Rejected, reason); err != nil {
+					return err
+				}
+				summary.Rejected++
+			default:
+				summary.Open++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return StatusSummary{}, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"project/internal/ledger"
+	"project/internal/payout"
+	"project/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakePayoutStore struct {
+	payouts []payout.Payout
+	files   []payout.File
+}
+
+func (f *fakePayoutStore) InsertPayout(ctx context.Context, p payout.Payout) error {
+	f.payouts = append(f.payouts, p)
+	return nil
+}
+
+func (f *fakePayoutStore) GetPayout(ctx context.Context, id uuid.UUID) (payout.Payout, error) {
+	for _, p := range f.payouts {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return payout.Payout{}, errAny("payout not found")
+}
+
+func (f *fakePayoutStore) ListPendingPayouts(ctx context.Context, limit int) ([]payout.Payout, error) {
+	var out []payout.Payout
+	for _, p := range f.payouts {
+		if p.Status == payout.Pending && len(out) < limit {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePayoutStore) InsertPayoutFile(ctx context.Context, file payout.File) error {
+	f.files = append(f.files, file)
+	return nil
+}
+
+func (f *fakePayoutStore) GetPayoutFile(ctx context.Context, id uuid.UUID) (payout.File, error) {
+	for _, file := range f.files {
+		if file.ID == id {
+			return file, nil
+		}
+	}
+	return payout.File{}, errAny("payout file not found")
+}
+
+func (f *fakePayoutStore) GetPayoutFileByMessageID(ctx context.Context, messageId string) (payout.File, error) {
+	for _, file := range f.files {
+		if file.MessageID == messageId {
+			return file, nil
+		}
+	}
+	return payout.File{}, errAny("payout file not found")
+}
+
+func (f *fakePayoutStore) MarkPayoutsExported(ctx context.Context, fileId uuid.UUID, ids []uuid.UUID) error {
+	for _, id := range ids {
+		for i := range f.payouts {
+			if f.payouts[i].ID == id {
+				f.payouts[i].Status, f.payouts[i].FileID = payout.Exported, &fileId
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakePayoutStore) ListFilePayoutsForUpdate(ctx context.Context, fileId uuid.UUID) ([]payout.Payout, error) {
+	var out []payout.Payout
+	for _, p := range f.payouts {
+		if p.FileID != nil && *p.FileID == fileId {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePayoutStore) UpdatePayoutStatus(ctx context.Context, id uuid.UUID, status payout.Status, reason string) error {
+	for i := range f.payouts {
+		if f.payouts[i].ID == id {
+			f.payouts[i].Status, f.payouts[i].Reason = status, reason
+			return nil
+		}
+	}
+	return errAny("payout not found")
+}
+
+var testDebtor = payout.Debtor{Name: "Wallets Ltd", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"}
+
+func newPayoutService(wl wallet.Wallet) (*PayoutService, *fakePayoutStore, *mockFacade) {
+	m := &mockFacade{
+		OnGetWallet: func(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+			if walletId != wl.ID {
+				return wallet.Wallet{}, errAny("wallet not found")
+			}
+			return wl, nil
+		},
+	}
+	store := &fakePayoutStore{}
+	return NewPayoutService(&fakeBankTx{}, store, NewWalletService(m)), store, m
+}
+
+func TestPayoutRequest(t *testing.T) {
+	wl := wallet.Wallet{ID: uuid.New(), Status: wallet.Active, Currency: "EUR"}
+	s, store, m := newPayoutService(wl)
+	b := payout.Beneficiary{Name: " Jane ", IBAN: "de89 3704 0044 0532 0130 00", BIC: "cobadeff"}
+
+	p, err := s.Request(context.Background(), wl.ID, 500, b, "rent")
+	require.NoError(t, err)
+	require.Equal(t, payout.Pending, p.Status)
+	require.Equal(t, "EUR", p.Currency)
+	require.Equal(t, payout.Beneficiary{Name: "Jane", IBAN: "DE89370400440532013000", BIC: "COBADEFF"}, p.Beneficiary)
+	require.Equal(t, 1, m.withdrawCalls)
+	require.Zero(t, p.Fee)
+	require.Len(t, store.payouts, 1)
+
+	_, err = s.Request(context.Background(), wl.ID, 500, payout.Beneficiary{Name: "Jane", IBAN: "DE89370400440532013001"}, "")
+	require.EqualError(t, err, "beneficiary IBAN is invalid")
+
+	_, err = s.Request(context.Background(), wl.ID, 500, payout.Beneficiary{IBAN: "DE89370400440532013000"}, "")
+	require.EqualError(t, err, "beneficiary name is required")
+
+	m.OnWithdraw = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+		return errAny("not enough balance: 0 < 500")
+	}
+	_, err = s.Request(context.Background(), wl.ID, 500, b, "")
+	require.EqualError(t, err, "not enough balance: 0 < 500")
+	require.Len(t, store.payouts, 1)
+
+	frozen, _, _ := newPayoutService(wallet.Wallet{ID: wl.ID, Status: wallet.Frozen})
+	_, err = frozen.Request(context.Background(), wl.ID, 500, b, "")
+	require.EqualError(t, err, "wallet is FROZEN")
+}
+
+func TestPayoutRequest_ChargesFee(t *testing.T) {
+	wl := wallet.Wallet{ID: uuid.New(), Status: wallet.Active, Currency: "EUR"}
+	s, store, m := newPayoutService(wl)
+	revenue := uuid.New()
+	s.Wallets.Fees = FeeSchedule{RevenueWallet: revenue, Withdraw: FeeRule{Flat: 30}}
+
+	p, err := s.Request(context.Background(), wl.ID, 500, payout.Beneficiary{Name: "Jane", IBAN: "DE89370400440532013000"}, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(30), p.Fee)
+	require.Equal(t, int64(30), store.payouts[0].Fee)
+	require.Equal(t, ledger.FeeCharge{Amount: 30, Revenue: revenue}, m.lastFee)
+	require.Equal(t, p.WithdrawalRef(), m.lastWithdrawRef)
+}
+
+func TestPayoutStatus_RejectedRefundsFee(t *testing.T) {
+	wl := wallet.Wallet{ID: uuid.New(), Status: wallet.Active, Currency: "EUR"}
+	s, _, m := newPayoutService(wl)
+	revenue := uuid.New()
+	s.Wallets.Fees = FeeSchedule{RevenueWallet: revenue, Withdraw: FeeRule{Flat: 30}}
+
+	p, err := s.Request(context.Background(), wl.ID, 500, payout.Beneficiary{Name: "Jane", IBAN: "DE89370400440532013000"}, "")
+	require.NoError(t, err)
+	file, err := s.Export(context.Background(), testDebtor, 10, time.Now())
+	require.NoError(t, err)
+
+	var refunds int
+	m.OnRefund = func(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+		refunds++
+		require.Equal(t, wl.ID, walletId)
+		require.Equal(t, int64(500), amount)
+		require.Equal(t, ledger.FeeCharge{Amount: 30, Revenue: revenue}, fee)
+		require.Equal(t, p.RefundRef(), externalRef)
+		return nil
+	}
+
+	summary, err := s.ApplyStatusReport(context.Background(), []byte(`<Document><CstmrPmtStsRpt>
+		<OrgnlGrpInfAndSts><OrgnlMsgId>`+file.MessageID+`</OrgnlMsgId><GrpSts>RJCT</GrpSts></OrgnlGrpInfAndSts>
+	</CstmrPmtStsRpt></Document>`))
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.Rejected)
+	require.Equal(t, 1, refunds)
+}
+
+func TestPayoutExportAndStatus(t *testing.T) {
+	wl := wallet.Wallet{ID: uuid.New(), Status: wallet.Active, Currency: "EUR"}
+	s, store, m := newPayoutService(wl)
+	b := payout.Beneficiary{Name: "Jane", IBAN: "DE89370400440532013000"}
+
+	ok, err := s.Request(context.Background(), wl.ID, 500, b, "")
+	require.NoError(t, err)
+	bad, err := s.Request(context.Background(), wl.ID, 700, b, "")
+	require.NoError(t, err)
+	open, err := s.Request(context.Background(), wl.ID, 900, b, "")
+	require.NoError(t, err)
+
+	_, err = s.Export(context.Background(), payout.Debtor{}, 10, time.Now())
+	require.EqualError(t, err, "payout debtor name is required")
+
+	file, err := s.Export(context.Background(), testDebtor, 10, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 3, file.Payouts)
+	require.Equal(t, int64(2100), file.ControlSum)
+	require.Contains(t, string(file.Content), "<CtrlSum>21.00</CtrlSum>")
+
+	_, err = s.Export(context.Background(), testDebtor, 10, time.Now())
+	require.EqualError(t, err, "no pending payouts")
+
+	report := []byte(`<Document><CstmrPmtStsRpt>
+		<OrgnlGrpInfAndSts><OrgnlMsgId>` + file.MessageID + `</OrgnlMsgId><GrpSts>PART</GrpSts></OrgnlGrpInfAndSts>
+		<OrgnlPmtInfAndSts>
+			<TxInfAndSts><OrgnlEndToEndId>` + ok.EndToEndID + `</OrgnlEndToEndId><TxSts>ACSC</TxSts></TxInfAndSts>
+			<TxInfAndSts><OrgnlEndToEndId>` + bad.EndToEndID + `</OrgnlEndToEndId><TxSts>RJCT</TxSts>
+				<StsRsnInf><Rsn><Cd>AC04</Cd></Rsn></StsRsnInf></TxInfAndSts>
+			<TxInfAndSts><OrgnlEndToEndId>` + open.EndToEndID + `</OrgnlEndToEndId><TxSts>PDNG</TxSts></TxInfAndSts>
+		</OrgnlPmtInfAndSts>
+	</CstmrPmtStsRpt></Document>`)
+
+	var (
+		refunded []int64
+		refs     []string
+	)
+	m.OnDeposit = func(ctx context.Context, walletId uuid.UUID, amount int64) error {
+		t.Fatal("refund went through the deposit limits")
+		return nil
+	}
+	m.OnRefund = func(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+		require.Equal(t, wl.ID, walletId)
+		require.Zero(t, fee.Amount)
+		refunded, refs = append(refunded, amount), append(refs, externalRef)
+		return nil
+	}
+
+	summary, err := s.ApplyStatusReport(context.Background(), report)
+	require.NoError(t, err)
+	require.Equal(t, StatusSummary{FileID: file.ID, Confirmed: 1, Rejected: 1, Open: 1}, summary)
+	require.Equal(t, []int64{700}, refunded)
+
+	got, err := s.Get(context.Background(), bad.ID)
+	require.NoError(t, err)
+	require.Equal(t, payout.Rejected, got.Status)
+	require.Equal(t, "AC04", got.Reason)
+
+	summary, err = s.ApplyStatusReport(context.Background(), report)
+	require.NoError(t, err)
+	require.Equal(t, StatusSummary{FileID: file.ID, Open: 1, Unchanged: 2}, summary)
+	require.Equal(t, []int64{700}, refunded)
+	require.Equal(t, payout.Exported, store.payouts[2].Status)
+
+	accepted := []byte(`<Document><CstmrPmtStsRpt>
+		<OrgnlGrpInfAndSts><OrgnlMsgId>` + file.MessageID + `</OrgnlMsgId><GrpSts>ACSP</GrpSts></OrgnlGrpInfAndSts>
+	</CstmrPmtStsRpt></Document>`)
+	summary, err = s.ApplyStatusReport(context.Background(), accepted)
+	require.NoError(t, err)
+	require.Equal(t, StatusSummary{FileID: file.ID, Accepted: 1, Unchanged: 2}, summary)
+	require.Equal(t, payout.Accepted, store.payouts[2].Status)
+
+	summary, err = s.ApplyStatusReport(context.Background(), accepted)
+	require.NoError(t, err)
+	require.Equal(t, StatusSummary{FileID: file.ID, Unchanged: 3}, summary)
+
+	summary, err = s.ApplyStatusReport(context.Background(), []byte(`<Document><CstmrPmtStsRpt>
+		<OrgnlGrpInfAndSts><OrgnlMsgId>`+file.MessageID+`</OrgnlMsgId><GrpSts>RJCT</GrpSts></OrgnlGrpInfAndSts>
+	</CstmrPmtStsRpt></Document>`))
+	require.NoError(t, err)
+	require.Equal(t, StatusSummary{FileID: file.ID, Rejected: 1, Unchanged: 2}, summary)
+	require.Equal(t, []int64{700, 900}, refunded)
+	require.Equal(t, []string{bad.RefundRef(), open.RefundRef()}, refs)
+	require.Equal(t, payout.Rejected, store.payouts[2].Status)
+
+	_, err = s.ApplyStatusReport(context.Background(), []byte(`<Document><CstmrPmtStsRpt>
+		<OrgnlGrpInfAndSts><OrgnlMsgId>PO-unknown</OrgnlMsgId><GrpSts>ACCP</GrpSts></OrgnlGrpInfAndSts>
+	</CstmrPmtStsRpt></Document>`))
+	require.EqualError(t, err, "payout file not found")
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/internal/wallet"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxOwnerIDLength     = 128
+	MaxDisplayNameLength = 256
+	MaxExternalRefLength = 256
+	MaxMetadataBytes     = 16 << 10
+	MaxOwnerWallets      = 1000
+	MaxInterestRateBps   = 10000
+)
+
+// CreateWalletWithProfile creates a wallet, generating its ID when the
+// caller did not supply one.
+func (ws *WalletService) CreateWalletWithProfile(ctx context.Context, wl wallet.Wallet) (wallet.Wallet, error) {
+	if err := checkText("ownerId", wl.OwnerID, MaxOwnerIDLength); err != nil {
+		return wallet.Wallet{}, err
+	}
+	if err := checkText("displayName", wl.DisplayName, MaxDisplayNameLength); err != nil {
+		return wallet.Wallet{}, err
+	}
+	if err := checkText("externalRef", wl.ExternalRef, MaxExternalRefLength); err != nil {
+		return wallet.Wallet{}, err
+	}
+	if wl.ExternalRef != "" && wl.OwnerID == "" {
+		return wallet.Wallet{}, errors.New("externalRef requires ownerId")
+	}
+	if err := checkMetadata(wl.Metadata); err != nil {
+		return wallet.Wallet{}, err
+	}
+	if wl.Currency == "" {
+		wl.Currency = wallet.DefaultCurrency
+	} else if !validCurrency(wl.Currency) {
+		return wallet.Wallet{}, errors.New("currency must be a three-letter ISO code")
+	}
+
+	switch wl.Type {
+	case "", wallet.Standard:
+		wl.Type, wl.InterestRateBps = wallet.Standard, 0
+	case wallet.Savings:
+		wl.InterestRateBps = ws.SavingsRateBps
+	default:
+		return wallet.Wallet{}, errors.New("type must be STANDARD or SAVINGS")
+	}
+
+	if wl.ID == uuid.Nil {
+		wl.ID = uuid.New()
+	}
+	if len(wl.Metadata) == 0 {
+		wl.Metadata = json.RawMessage("{}")
+	}
+
+	if err := ws.Repo.CreateWithProfile(ctx, wl); err != nil {
+		return wallet.Wallet{}, err
+	}
+	return wl, nil
+}
+
+// SetInterestRate changes the annual rate of a savings wallet from its
+// next accrual on.
+func (ws *WalletService) SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	if rateBps < 0 || rateBps > MaxInterestRateBps {
+		return fmt.Errorf("interestRateBps must be between 0 and %d", MaxInterestRateBps)
+	}
+	return ws.Repo.SetInterestRate(ctx, walletId, rateBps)
+}
+
+func (ws *WalletService) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	return ws.Repo.GetWallet(ctx, walletId)
+}
+
+func (ws *WalletService) UpdateWalletProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error) {
+	if upd.DisplayName == nil && upd.ExternalRef == nil && len(upd.Metadata) == 0 {
+		return wallet.Wallet{}, errors.New("nothing to update")
+	}
+	if upd.DisplayName != nil {
+		if err := checkText("displayName", *upd.DisplayName, MaxDisplayNameLength); err != nil {
+			return wallet.Wallet{}, err
+		}
+	}
+	if upd.ExternalRef != nil {
+		if err := checkText("externalRef", *upd.ExternalRef, MaxExternalRefLength); err != nil {
+			return wallet.Wallet{}, err
+		}
+	}
+	if err := checkMetadata(upd.Metadata); err != nil {
+		return wallet.Wallet{}, err
+	}
+
+	return ws.Repo.UpdateProfile(ctx, walletId, upd)
+}
+
+func (ws *WalletService) ListOwnerWallets(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	if ownerId == "" {
+		return nil, errors.New("ownerId parameter is required")
+	}
+	if limit <= 0 || limit > MaxOwnerWallets {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxOwnerWallets)
+	}
+	return ws.Repo.ListWalletsByOwner(ctx, ownerId, afterId, limit)
+}
+
+func (ws *WalletService) FindWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	if ownerId == "" {
+		return wallet.Wallet{}, errors.New("ownerId parameter is required")
+	}
+	if externalRef == "" {
+		return wallet.Wallet{}, errors.New("externalRef parameter is required")
+	}
+	return ws.Repo.GetWalletByExternalRef(ctx, ownerId, externalRef)
+}
+
+func checkText(name, value string, max int) error {
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%s must be valid UTF-8", name)
+	}
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s must be at most %d characters", name, max)
+	}
+	return nil
+}
+
+// checkMetadata accepts an absent value or a JSON object; arrays and scalars
+// are rejected so that metadata can always be merged key by key later.
+func checkMetadata(raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if len(raw) > MaxMetadataBytes {
+		return fmt.Errorf("metadata must be at most %d bytes", MaxMetadataBytes)
+	}
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return errors.New("metadata must be a JSON object")
+	}
+	return nil
+}
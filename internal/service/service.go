@@ -3,13 +3,26 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"project/internal/money"
 	"project/internal/storage"
 
 	"github.com/google/uuid"
 )
 
+const (
+	MaxBatchItems = 5000
+	MaxShards     = 64
+)
+
 type WalletService struct {
-	Repo storage.Facade
+	Repo   storage.Facade
+	Limits money.Limits
+	Fees   FeeSchedule
+	// SavingsRateBps is the annual rate new savings wallets start with.
+	SavingsRateBps int64
 }
 
 func NewWalletService(repo storage.Facade) *WalletService {
@@ -20,8 +33,8 @@ func NewWalletService(repo storage.Facade) *WalletService {
 
 func (ws *WalletService) DepositFunds(ctx context.Context, walletId uuid.UUID, amount int64) error {
 
-	if amount <= 0 {
-		return errors.New("amount must be positive")
+	if err := ws.Limits.CheckDeposit(amount); err != nil {
+		return err
 	}
 
 	if err := ws.Repo.Deposit(ctx, walletId, amount); err != nil {
@@ -31,23 +44,98 @@ func (ws *WalletService) DepositFunds(ctx context.Context, walletId uuid.UUID, a
 	return nil
 }
 
-func (ws *WalletService) WithdrawFunds(ctx context.Context, walletId uuid.UUID, amount int64) error {
+// WithdrawFunds debits amount plus the withdrawal fee, which it returns.
+func (ws *WalletService) WithdrawFunds(ctx context.Context, walletId uuid.UUID, amount int64) (int64, error) {
+	return ws.WithdrawFundsRef(ctx, walletId, amount, "")
+}
+
+// WithdrawFundsRef is WithdrawFunds with externalRef recorded on the
+// withdrawal's ledger entry.
+func (ws *WalletService) WithdrawFundsRef(ctx context.Context, walletId uuid.UUID, amount int64, externalRef string) (int64, error) {
+
+	q, err := ws.QuoteWithdraw(ctx, walletId, amount)
+	if err != nil {
+		return 0, err
+	}
+
+	if externalRef == "" {
+		err = ws.Repo.Withdraw(ctx, walletId, amount, ws.fee(q))
+	} else {
+		err = ws.Repo.WithdrawRef(ctx, walletId, amount, ws.fee(q), externalRef)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return q.Fee, nil
+}
+
+// TransferFunds moves amount from one wallet to another and charges the
+// sender the transfer fee, which it returns. The amount is held to the
+// withdrawal limits, since it leaves the sender's wallet.
+func (ws *WalletService) TransferFunds(ctx context.Context, from, to uuid.UUID, amount int64) (int64, error) {
+	if to == uuid.Nil {
+		return 0, errors.New("toWalletId parameter is required")
+	}
+	if from == to {
+		return 0, errors.New("cannot transfer to the same wallet")
+	}
+
+	q, err := ws.QuoteTransfer(ctx, from, amount)
+	if err != nil {
+		return 0, err
+	}
 
-	if amount <= 0 {
-		return errors.New("amount must be positive")
+	if err := ws.Repo.Transfer(ctx, from, to, amount, ws.fee(q)); err != nil {
+		return 0, err
 	}
 
-	if err := ws.Repo.Withdraw(ctx, walletId, amount); err != nil {
+	return q.Fee, nil
+}
+
+func (ws *WalletService) DepositFundsIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error {
+
+	if err := ws.Limits.CheckDeposit(amount); err != nil {
 		return err
 	}
 
-	return nil
+	return ws.Repo.DepositIfVersion(ctx, walletId, amount, version)
+}
+
+func (ws *WalletService) WithdrawFundsIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) (int64, error) {
+
+	q, err := ws.QuoteWithdraw(ctx, walletId, amount)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ws.Repo.WithdrawIfVersion(ctx, walletId, amount, version, ws.fee(q)); err != nil {
+		return 0, err
+	}
+
+	return q.Fee, nil
+}
+
+func (ws *WalletService) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	return ws.Repo.GetBalanceVersion(ctx, walletId)
 }
 
 func (ws *WalletService) GetBalance(ctx context.Context, walletId uuid.UUID) (int64, error) {
 	return ws.Repo.GetByID(ctx, walletId)
 }
 
+func (ws *WalletService) LedgerSince(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	if after.Floor < 0 {
+		return nil, errors.New("invalid last event id")
+	}
+	return ws.Repo.GetLedger(ctx, walletId, after, limit)
+}
+
+// LedgerHead is where a stream that replays nothing starts reading.
+func (ws *WalletService) LedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	return ws.Repo.GetLedgerHead(ctx, walletId)
+}
+
 func (ws *WalletService) CreateWallet(ctx context.Context, walletId uuid.UUID) error {
 	if walletId == uuid.Nil {
 		return errors.New("walletId parameter is required")
@@ -59,3 +147,71 @@ func (ws *WalletService) CreateWallet(ctx context.Context, walletId uuid.UUID) e
 
 	return nil
 }
+
+func (ws *WalletService) ProcessBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+	if mode != batch.Atomic && mode != batch.BestEffort {
+		return batch.Batch{}, errors.New("invalid mode parameter")
+	}
+
+	if len(ops) == 0 {
+		return batch.Batch{}, errors.New("operations must not be empty")
+	}
+	if len(ops) > MaxBatchItems {
+		return batch.Batch{}, fmt.Errorf("too many operations: %d > %d", len(ops), MaxBatchItems)
+	}
+
+	for i, op := range ops {
+		if op.WalletID == uuid.Nil {
+			return batch.Batch{}, fmt.Errorf("operations[%d]: walletId parameter is required", i)
+		}
+		if op.OperationType != ledger.Deposit && op.OperationType != ledger.Withdraw {
+			return batch.Batch{}, fmt.Errorf("operations[%d]: invalid operationType parameter", i)
+		}
+		check := ws.Limits.CheckDeposit
+		if op.OperationType == ledger.Withdraw {
+			check = ws.Limits.CheckWithdraw
+		}
+		if err := check(op.Amount); err != nil {
+			return batch.Batch{}, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+	}
+
+	// Withdrawals are priced like WithdrawFunds prices them, each against the
+	// monthly volume as it was before the batch.
+	for i, op := range ops {
+		if op.OperationType != ledger.Withdraw {
+			continue
+		}
+		q, err := ws.quote(ctx, ws.Fees.Withdraw, op.WalletID, op.Amount)
+		if err != nil {
+			return batch.Batch{}, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+		ops[i].Fee = ws.fee(q)
+	}
+
+	return ws.Repo.ApplyBatch(ctx, mode, ops)
+}
+
+func (ws *WalletService) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	return ws.Repo.GetBatch(ctx, batchId)
+}
+
+func (ws *WalletService) ConfigureSharding(ctx context.Context, walletId uuid.UUID, shards int) error {
+	if shards < 0 || shards > MaxShards {
+		return fmt.Errorf("shardCount must be between 0 and %d", MaxShards)
+	}
+	return ws.Repo.SetShardCount(ctx, walletId, shards)
+}
+
+// ReverseOperation undoes amount of the ledger entry entryId, or all of what
+// is left of it when amount is 0. Reversals bypass the deposit and
+// withdrawal limits: they correct an operation, they are not a new one.
+func (ws *WalletService) ReverseOperation(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error) {
+	if entryId <= 0 {
+		return ledger.Entry{}, errors.New("operation not found")
+	}
+	if amount < 0 {
+		return ledger.Entry{}, errors.New("amount must be positive")
+	}
+	return ws.Repo.Reverse(ctx, entryId, amount, force)
+}
@@ -5,26 +5,190 @@ import (
 	"errors"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 
+	"project/internal/batch"
+	"project/internal/ledger"
+	"project/internal/money"
+	"project/internal/statement"
 	"project/internal/storage"
+	"project/internal/wallet"
 
 	"github.com/google/uuid"
 )
 
 type mockFacade struct {
 	OnDeposit  func(ctx context.Context, walletId uuid.UUID, amount int64) error
+	OnRefund   func(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error
 	OnWithdraw func(ctx context.Context, walletId uuid.UUID, amount int64) error
 	OnGetByID  func(ctx context.Context, walletId uuid.UUID) (int64, error)
 	OnCreate   func(ctx context.Context, walletId uuid.UUID) error
+	OnLedger   func(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error)
+	OnBatch    func(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error)
+	OnShards   func(ctx context.Context, walletId uuid.UUID, shards int) error
+	OnProfile  func(ctx context.Context, wl wallet.Wallet) error
 
-	depositCalls  int
-	withdrawCalls int
-	getByIDCalls  int
-	createCalls   int
+	OnBalancesAt func(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error)
+	OnGetWallet  func(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error)
+	OnReverse    func(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error)
+	OnWithdrawn  func(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error)
+
+	depositCalls   int
+	withdrawCalls  int
+	getByIDCalls   int
+	createCalls    int
+	ledgerCalls    int
+	batchCalls     int
+	shardCalls     int
+	profileCalls   int
+	listCalls      int
+	balancesCalls  int
+	statementCalls int
+
+	lastFee         ledger.FeeCharge
+	lastWithdrawRef string
+	lastTransferTo  uuid.UUID
+}
+
+func (m *mockFacade) GetLedger(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	m.ledgerCalls++
+	if m.OnLedger != nil {
+		return m.OnLedger(ctx, walletId, after, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockFacade) GetLedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	return ledger.Cursor{}, nil
+}
+
+func (m *mockFacade) ApplyBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+	m.batchCalls++
+	if m.OnBatch != nil {
+		return m.OnBatch(ctx, mode, ops)
+	}
+	return batch.Batch{}, nil
+}
+
+func (m *mockFacade) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	return batch.Batch{}, nil
+}
+
+func (m *mockFacade) CreateWithProfile(ctx context.Context, wl wallet.Wallet) error {
+	m.profileCalls++
+	if m.OnProfile != nil {
+		return m.OnProfile(ctx, wl)
+	}
+	return nil
+}
+
+func (m *mockFacade) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	if m.OnGetWallet != nil {
+		return m.OnGetWallet(ctx, walletId)
+	}
+	return wallet.Wallet{}, nil
+}
+
+func (m *mockFacade) GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	return wallet.Wallet{}, nil
+}
+
+func (m *mockFacade) ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	return nil, nil
+}
+
+func (m *mockFacade) UpdateProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error) {
+	m.profileCalls++
+	return wallet.Wallet{}, nil
+}
+
+func (m *mockFacade) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	m.listCalls++
+	return nil, nil
+}
+
+func (m *mockFacade) GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockFacade) GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error) {
+	m.balancesCalls++
+	if m.OnBalancesAt != nil {
+		return m.OnBalancesAt(ctx, walletIds, at)
+	}
+	return nil, nil
+}
+
+func (m *mockFacade) GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time, maxEntries int) (statement.Statement, error) {
+	m.statementCalls++
+	return statement.Statement{WalletID: walletId, From: statement.Date(from), To: statement.Date(to)}, nil
+}
+
+func (m *mockFacade) SetShardCount(ctx context.Context, walletId uuid.UUID, shards int) error {
+	m.shardCalls++
+	if m.OnShards != nil {
+		return m.OnShards(ctx, walletId, shards)
+	}
+	return nil
+}
+
+func (m *mockFacade) Reverse(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error) {
+	if m.OnReverse != nil {
+		return m.OnReverse(ctx, entryId, amount, force)
+	}
+	return ledger.Entry{}, nil
+}
+
+func (m *mockFacade) GetWithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error) {
+	if m.OnWithdrawn != nil {
+		return m.OnWithdrawn(ctx, walletId, since)
+	}
+	return 0, nil
+}
+
+func (m *mockFacade) SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	return nil
+}
+
+func (m *mockFacade) SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error) {
+	return wallet.Wallet{ID: walletId, Status: status}, nil
+}
+
+func (m *mockFacade) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	m.getByIDCalls++
+	if m.OnGetByID != nil {
+		balance, err := m.OnGetByID(ctx, walletId)
+		return balance, 0, err
+	}
+	return 0, 0, nil
+}
+
+func (m *mockFacade) DepositIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error {
+	m.depositCalls++
+	if m.OnDeposit != nil {
+		return m.OnDeposit(ctx, walletId, amount)
+	}
+	return nil
+}
+
+func (m *mockFacade) WithdrawIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64, fee ledger.FeeCharge) error {
+	m.withdrawCalls++
+	m.lastFee = fee
+	if m.OnWithdraw != nil {
+		return m.OnWithdraw(ctx, walletId, amount)
+	}
+	return nil
 }
 
 var _ storage.Facade = (*mockFacade)(nil)
 
+func (m *mockFacade) Refund(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	if m.OnRefund != nil {
+		return m.OnRefund(ctx, walletId, amount, fee, externalRef)
+	}
+	return nil
+}
+
 func (m *mockFacade) Deposit(ctx context.Context, walletId uuid.UUID, amount int64) error {
 	m.depositCalls++
 	if m.OnDeposit != nil {
@@ -33,8 +197,19 @@ func (m *mockFacade) Deposit(ctx context.Context, walletId uuid.UUID, amount int
 	return nil
 }
 
-func (m *mockFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64) error {
+func (m *mockFacade) WithdrawRef(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	m.lastWithdrawRef = externalRef
+	return m.Withdraw(ctx, walletId, amount, fee)
+}
+
+func (m *mockFacade) Transfer(ctx context.Context, from, to uuid.UUID, amount int64, fee ledger.FeeCharge) error {
+	m.lastTransferTo = to
+	return m.Withdraw(ctx, from, amount, fee)
+}
+
+func (m *mockFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge) error {
 	m.withdrawCalls++
+	m.lastFee = fee
 	if m.OnWithdraw != nil {
 		return m.OnWithdraw(ctx, walletId, amount)
 	}
@@ -89,7 +264,7 @@ func TestWithdrawFunds(t *testing.T) {
 	ws := NewWalletService(&mockFacade{})
 
 	t.Run("amount must be positive", func(t *testing.T) {
-		err := ws.WithdrawFunds(context.Background(), uuid.New(), -10)
+		_, err := ws.WithdrawFunds(context.Background(), uuid.New(), -10)
 
 		require.Equalf(t, "amount must be positive", err.Error(), "want error 'amount must be positive', got %v", err)
 	})
@@ -105,11 +280,12 @@ func TestWithdrawFunds(t *testing.T) {
 			return nil
 		}
 		ws.Repo = m
-		err := ws.WithdrawFunds(context.Background(), uuid.New(), 50)
+		fee, err := ws.WithdrawFunds(context.Background(), uuid.New(), 50)
 
 		require.NoError(t, err, "unexpected error: %v", err)
 		require.Equalf(t, true, called, "repo.Withdraw wasn't called")
 		require.Equalf(t, 1, m.withdrawCalls, "repo.Withdraw wasn't called exactly once")
+		require.Zero(t, fee)
 	})
 }
 
@@ -147,3 +323,188 @@ func TestCreateWallet(t *testing.T) {
 		require.Equalf(t, 1, m.createCalls, "repo.Create wasn't called exactly once")
 	})
 }
+
+func TestProcessBatch(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("validation", func(t *testing.T) {
+		m := &mockFacade{}
+		ws := NewWalletService(m)
+
+		_, err := ws.ProcessBatch(context.Background(), "SOMETIMES", []batch.Operation{{WalletID: id, OperationType: ledger.Deposit, Amount: 1}})
+		require.EqualError(t, err, "invalid mode parameter")
+
+		_, err = ws.ProcessBatch(context.Background(), batch.Atomic, nil)
+		require.EqualError(t, err, "operations must not be empty")
+
+		_, err = ws.ProcessBatch(context.Background(), batch.Atomic, []batch.Operation{
+			{WalletID: id, OperationType: ledger.Deposit, Amount: 1},
+			{WalletID: id, OperationType: ledger.Withdraw, Amount: 0},
+		})
+		require.EqualError(t, err, "operations[1]: amount must be positive")
+
+		_, err = ws.ProcessBatch(context.Background(), batch.BestEffort, []batch.Operation{{WalletID: id, OperationType: "MOVE", Amount: 1}})
+		require.EqualError(t, err, "operations[0]: invalid operationType parameter")
+
+		require.Equal(t, 0, m.batchCalls)
+	})
+
+	t.Run("ok path calls repo", func(t *testing.T) {
+		m := &mockFacade{}
+		m.OnBatch = func(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+			return batch.Batch{Mode: mode, Status: batch.Completed}, nil
+		}
+		ws := NewWalletService(m)
+
+		b, err := ws.ProcessBatch(context.Background(), batch.BestEffort, []batch.Operation{{WalletID: id, OperationType: ledger.Deposit, Amount: 5}})
+
+		require.NoError(t, err)
+		require.Equal(t, batch.Completed, b.Status)
+		require.Equal(t, 1, m.batchCalls)
+	})
+}
+
+func TestConfigureSharding(t *testing.T) {
+	id := uuid.New()
+	m := &mockFacade{}
+	ws := NewWalletService(m)
+
+	require.EqualError(t, ws.ConfigureSharding(context.Background(), id, -1), "shardCount must be between 0 and 64")
+	require.EqualError(t, ws.ConfigureSharding(context.Background(), id, MaxShards+1), "shardCount must be between 0 and 64")
+	require.Equal(t, 0, m.shardCalls)
+
+	require.NoError(t, ws.ConfigureSharding(context.Background(), id, 0))
+	require.NoError(t, ws.ConfigureSharding(context.Background(), id, 16))
+	require.Equal(t, 2, m.shardCalls)
+}
+
+func TestDepositFunds_Limits(t *testing.T) {
+	m := &mockFacade{}
+	ws := NewWalletService(m)
+	ws.Limits = money.Limits{Deposit: money.Bounds{Min: 10, Max: 1000}, MaxBalance: 500}
+
+	require.EqualError(t, ws.DepositFunds(context.Background(), uuid.New(), 5), "amount must be at least 10")
+	require.EqualError(t, ws.DepositFunds(context.Background(), uuid.New(), 2000), "amount must be at most 1000")
+	require.EqualError(t, ws.DepositFunds(context.Background(), uuid.New(), 600), "balance limit exceeded")
+	require.Equal(t, 0, m.depositCalls)
+
+	require.NoError(t, ws.DepositFunds(context.Background(), uuid.New(), 100))
+	require.Equal(t, 1, m.depositCalls)
+}
+
+func TestCreateWalletWithProfile(t *testing.T) {
+	t.Run("generates id", func(t *testing.T) {
+		m := &mockFacade{}
+		ws := NewWalletService(m)
+		wl, err := ws.CreateWalletWithProfile(context.Background(), wallet.Wallet{OwnerID: "cust-1"})
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, wl.ID)
+		require.JSONEq(t, `{}`, string(wl.Metadata))
+		require.Equal(t, 1, m.profileCalls)
+	})
+
+	t.Run("keeps given id", func(t *testing.T) {
+		id := uuid.New()
+		ws := NewWalletService(&mockFacade{})
+		wl, err := ws.CreateWalletWithProfile(context.Background(), wallet.Wallet{ID: id})
+		require.NoError(t, err)
+		require.Equal(t, id, wl.ID)
+	})
+
+	t.Run("wallet type", func(t *testing.T) {
+		ws := NewWalletService(&mockFacade{})
+		ws.SavingsRateBps = 250
+
+		wl, err := ws.CreateWalletWithProfile(context.Background(), wallet.Wallet{InterestRateBps: 900})
+		require.NoError(t, err)
+		require.Equal(t, wallet.Standard, wl.Type)
+		require.Zero(t, wl.InterestRateBps)
+
+		wl, err = ws.CreateWalletWithProfile(context.Background(), wallet.Wallet{Type: wallet.Savings})
+		require.NoError(t, err)
+		require.Equal(t, wallet.Savings, wl.Type)
+		require.Equal(t, int64(250), wl.InterestRateBps)
+	})
+
+	for name, tc := range map[string]struct {
+		wl   wallet.Wallet
+		want string
+	}{
+		"unknown type":      {wallet.Wallet{Type: "CHECKING"}, "type must be STANDARD or SAVINGS"},
+		"ref without owner": {wallet.Wallet{ExternalRef: "r"}, "externalRef requires ownerId"},
+		"metadata array":    {wallet.Wallet{Metadata: []byte(`[1]`)}, "metadata must be a JSON object"},
+		"metadata invalid":  {wallet.Wallet{Metadata: []byte(`{"a":`)}, "metadata must be a JSON object"},
+		"metadata too big":  {wallet.Wallet{Metadata: make([]byte, MaxMetadataBytes+1)}, "metadata must be at most 16384 bytes"},
+		"name too long":     {wallet.Wallet{DisplayName: string(make([]rune, MaxDisplayNameLength+1))}, "displayName must be at most 256 characters"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := &mockFacade{}
+			_, err := NewWalletService(m).CreateWalletWithProfile(context.Background(), tc.wl)
+			require.EqualError(t, err, tc.want)
+			require.Equal(t, 0, m.profileCalls)
+		})
+	}
+}
+
+func TestSetInterestRate(t *testing.T) {
+	ws := NewWalletService(&mockFacade{})
+	require.NoError(t, ws.SetInterestRate(context.Background(), uuid.New(), 0))
+	require.NoError(t, ws.SetInterestRate(context.Background(), uuid.New(), MaxInterestRateBps))
+	require.EqualError(t, ws.SetInterestRate(context.Background(), uuid.New(), -1), "interestRateBps must be between 0 and 10000")
+	require.EqualError(t, ws.SetInterestRate(context.Background(), uuid.New(), MaxInterestRateBps+1), "interestRateBps must be between 0 and 10000")
+}
+
+func TestListWallets(t *testing.T) {
+	low, high := int64(10), int64(5)
+
+	for name, tc := range map[string]struct {
+		q    wallet.Query
+		want string
+	}{
+		"zero limit":    {wallet.Query{}, "limit must be between 1 and 1000"},
+		"limit too big": {wallet.Query{Limit: MaxListWallets + 1}, "limit must be between 1 and 1000"},
+		"bad sort":      {wallet.Query{Limit: 1, Sort: "owner"}, "invalid sort parameter"},
+		"bad status":    {wallet.Query{Limit: 1, Filter: wallet.Filter{Status: "GONE"}}, "invalid status parameter"},
+		"bad currency":  {wallet.Query{Limit: 1, Filter: wallet.Filter{Currency: "euro"}}, "invalid currency parameter"},
+		"balance range": {wallet.Query{Limit: 1, Filter: wallet.Filter{MinBalance: &low, MaxBalance: &high}}, "minBalance must not exceed maxBalance"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := &mockFacade{}
+			_, err := NewWalletService(m).ListWallets(context.Background(), tc.q)
+			require.EqualError(t, err, tc.want)
+			require.Equal(t, 0, m.listCalls)
+		})
+	}
+
+	m := &mockFacade{}
+	_, err := NewWalletService(m).ListWallets(context.Background(), wallet.Query{Limit: 10, Filter: wallet.Filter{Status: wallet.Frozen, Currency: "EUR"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, m.listCalls)
+}
+
+func TestGetBalancesAt(t *testing.T) {
+	at := time.Date(2025, 10, 31, 23, 59, 59, 0, time.UTC)
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	m := &mockFacade{OnBalancesAt: func(ctx context.Context, walletIds []uuid.UUID, got time.Time) ([]ledger.PointBalance, error) {
+		require.Equal(t, []uuid.UUID{a, b, c}, walletIds)
+		require.Equal(t, at, got)
+		return []ledger.PointBalance{{WalletID: c, Balance: 30, At: at}, {WalletID: a, Balance: 10, At: at}}, nil
+	}}
+	balances, missing, err := NewWalletService(m).GetBalancesAt(context.Background(), []uuid.UUID{a, b, a, c}, at)
+	require.NoError(t, err)
+	require.Equal(t, []ledger.PointBalance{{WalletID: a, Balance: 10, At: at}, {WalletID: c, Balance: 30, At: at}}, balances)
+	require.Equal(t, []uuid.UUID{b}, missing)
+
+	m = &mockFacade{}
+	ws := NewWalletService(m)
+	_, _, err = ws.GetBalancesAt(context.Background(), []uuid.UUID{a}, time.Time{})
+	require.EqualError(t, err, "at parameter is required")
+	_, _, err = ws.GetBalancesAt(context.Background(), nil, at)
+	require.EqualError(t, err, "walletIds must not be empty")
+	_, _, err = ws.GetBalancesAt(context.Background(), make([]uuid.UUID, MaxBulkBalances+1), at)
+	require.EqualError(t, err, "too many walletIds: 1001 > 1000")
+	_, _, err = ws.GetBalancesAt(context.Background(), []uuid.UUID{a, uuid.Nil}, at)
+	require.EqualError(t, err, "walletIds[1]: walletId parameter is required")
+	require.Equal(t, 0, m.balancesCalls)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/statement"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxStatementDays    = 366
+	MaxStatementEntries = 10000
+)
+
+// GetStatement covers the UTC days from through to, both inclusive.
+func (ws *WalletService) GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time) (statement.Statement, error) {
+	from, to = utcDay(from), utcDay(to)
+	if to.Before(from) {
+		return statement.Statement{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= MaxStatementDays*24*time.Hour {
+		return statement.Statement{}, fmt.Errorf("date range must be at most %d days", MaxStatementDays)
+	}
+	return ws.Repo.GetStatement(ctx, walletId, from, to, MaxStatementEntries)
+}
+
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
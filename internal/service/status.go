@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+)
+
+// SetStatus freezes, unfreezes or closes a wallet. A wallet that is not
+// ACTIVE refuses deposits, withdrawals and transfers.
+func (ws *WalletService) SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error) {
+	switch status {
+	case wallet.Active, wallet.Frozen, wallet.Closed:
+	default:
+		return wallet.Wallet{}, errors.New("invalid status parameter")
+	}
+	return ws.Repo.SetStatus(ctx, walletId, status)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/google/uuid"
+	"project/internal/wallet"
+)
+
+func TestSetStatus_Validates(t *testing.T) {
+	ws := NewWalletService(&mockFacade{})
+	wl, err := ws.SetStatus(context.Background(), uuid.New(), wallet.Frozen)
+	require.NoError(t, err)
+	require.Equal(t, wallet.Frozen, wl.Status)
+
+	_, err = ws.SetStatus(context.Background(), uuid.New(), "GONE")
+	require.EqualError(t, err, "invalid status parameter")
+}
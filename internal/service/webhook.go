@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"project/internal/outbox"
+	"project/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+const deliveriesPageSize = 100
+
+type WebhookService struct {
+	Store webhook.Store
+}
+
+func NewWebhookService(store webhook.Store) *WebhookService {
+	return &WebhookService{
+		Store: store,
+	}
+}
+
+func (s *WebhookService) Subscribe(ctx context.Context, sub webhook.Subscription) (webhook.Subscription, error) {
+	parsed, err := url.Parse(sub.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return webhook.Subscription{}, errors.New("invalid url")
+	}
+
+	if len(sub.EventTypes) == 0 {
+		return webhook.Subscription{}, errors.New("eventTypes must not be empty")
+	}
+	for _, eventType := range sub.EventTypes {
+		switch outbox.EventType(eventType) {
+		case outbox.WalletCreated, outbox.WalletCredited, outbox.WalletDebited:
+		default:
+			return webhook.Subscription{}, errors.New("unknown event type")
+		}
+	}
+
+	if sub.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return webhook.Subscription{}, err
+		}
+		sub.Secret = secret
+	}
+	sub.ID = uuid.New()
+
+	if err := s.Store.InsertSubscription(ctx, sub); err != nil {
+		return webhook.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]webhook.Subscription, error) {
+	subs, err := s.Store.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *WebhookService) Unsubscribe(ctx context.Context, id uuid.UUID) error {
+	return s.Store.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionId uuid.UUID) ([]webhook.Delivery, error) {
+	if _, err := s.Store.GetSubscription(ctx, subscriptionId); err != nil {
+		return nil, err
+	}
+	return s.Store.ListDeliveries(ctx, subscriptionId, deliveriesPageSize)
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionId uuid.UUID, deliveryId int64) error {
+	return s.Store.RedeliverDelivery(ctx, subscriptionId, deliveryId)
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"project/internal/webhook"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type mockWebhookStore struct {
+	webhook.Store
+
+	inserted []webhook.Subscription
+	listed   []webhook.Subscription
+}
+
+func (m *mockWebhookStore) InsertSubscription(ctx context.Context, sub webhook.Subscription) error {
+	m.inserted = append(m.inserted, sub)
+	return nil
+}
+
+func (m *mockWebhookStore) ListSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	return m.listed, nil
+}
+
+func (m *mockWebhookStore) GetSubscription(ctx context.Context, id uuid.UUID) (webhook.Subscription, error) {
+	return webhook.Subscription{}, errAny("subscription not found")
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		ws := NewWebhookService(&mockWebhookStore{})
+
+		_, err := ws.Subscribe(context.Background(), webhook.Subscription{URL: "ftp://x", EventTypes: []string{"WalletCreated"}})
+		require.EqualError(t, err, "invalid url")
+
+		_, err = ws.Subscribe(context.Background(), webhook.Subscription{URL: "https://example.com/hook"})
+		require.EqualError(t, err, "eventTypes must not be empty")
+
+		_, err = ws.Subscribe(context.Background(), webhook.Subscription{URL: "https://example.com/hook", EventTypes: []string{"WalletExploded"}})
+		require.EqualError(t, err, "unknown event type")
+	})
+
+	t.Run("generates id and secret", func(t *testing.T) {
+		m := &mockWebhookStore{}
+		ws := NewWebhookService(m)
+
+		sub, err := ws.Subscribe(context.Background(), webhook.Subscription{URL: "https://example.com/hook", EventTypes: []string{"WalletCredited"}})
+
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, sub.ID)
+		require.Len(t, sub.Secret, 64)
+		require.Len(t, m.inserted, 1)
+	})
+}
+
+func TestListSubscriptions_HidesSecrets(t *testing.T) {
+	m := &mockWebhookStore{listed: []webhook.Subscription{{ID: uuid.New(), Secret: "shh"}}}
+	ws := NewWebhookService(m)
+
+	subs, err := ws.List(context.Background())
+
+	require.NoError(t, err)
+	require.Empty(t, subs[0].Secret)
+}
+
+func TestDeliveries_UnknownSubscription(t *testing.T) {
+	ws := NewWebhookService(&mockWebhookStore{})
+
+	_, err := ws.Deliveries(context.Background(), uuid.New())
+
+	require.EqualError(t, err, "subscription not found")
+}
+
+type errAny string
+
+func (e errAny) Error() string { return string(e) }
@@ -0,0 +1,144 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func WriteCSV(w io.Writer, s Statement) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"entryId", "createdAt", "operationType", "amount", "balance", "externalRef"})
+	cw.Write([]string{"", s.Start().Format(time.RFC3339), "OPENING_BALANCE", "", strconv.FormatInt(s.Opening, 10), ""})
+	for _, e := range s.Entries {
+		cw.Write([]string{
+			strconv.FormatInt(e.ID, 10), e.CreatedAt.UTC().Format(time.RFC3339Nano), string(e.OperationType),
+			strconv.FormatInt(e.Amount, 10), strconv.FormatInt(e.Balance, 10), csvSafe(e.ExternalRef),
+		})
+	}
+	cw.Write([]string{"", s.End().Format(time.RFC3339), "CLOSING_BALANCE", "", strconv.FormatInt(s.Closing, 10), ""})
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe keeps spreadsheet applications from evaluating a reference as a
+// formula.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+const (
+	pdfPageWidth   = 595
+	pdfPageHeight  = 842
+	pdfMargin      = 50
+	pdfFontSize    = 9
+	pdfLineHeight  = 12
+	pdfLinesOnPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// WritePDF renders the statement as a plain, monospaced PDF. It needs no
+// fonts of its own: Courier is one of the standard fonts every reader has.
+func WritePDF(w io.Writer, s Statement) error {
+	lines := []string{
+		"Wallet statement",
+		"",
+		"Wallet:  " + s.WalletID.String(),
+		"Period:  " + s.From.String() + " to " + s.To.String() + " (UTC)",
+		"",
+		fmt.Sprintf("%-20s %-9s %15s %15s  %s", "Date", "Type", "Amount", "Balance", "Reference"),
+		fmt.Sprintf("%-20s %-9s %15s %15d", s.From.String(), "OPENING", "", s.Opening),
+	}
+	for _, e := range s.Entries {
+		lines = append(lines, fmt.Sprintf("%-20s %-9s %15d %15d  %s",
+			e.CreatedAt.UTC().Format("2006-01-02 15:04:05"), e.OperationType, e.Amount, e.Balance, e.ExternalRef))
+	}
+	lines = append(lines,
+		fmt.Sprintf("%-20s %-9s %15s %15d", s.To.String(), "CLOSING", "", s.Closing),
+		"",
+		fmt.Sprintf("Total credits: %d", s.Credits),
+		fmt.Sprintf("Total debits:  %d", s.Debits),
+		fmt.Sprintf("Entries:       %d", len(s.Entries)),
+	)
+
+	var pages [][]string
+	for len(lines) > pdfLinesOnPage {
+		pages = append(pages, lines[:pdfLinesOnPage])
+		lines = lines[pdfLinesOnPage:]
+	}
+	pages = append(pages, lines)
+
+	return writePDF(w, pages)
+}
+
+// writePDF lays out objects as: 1 catalog, 2 page tree, 3 font, then a page
+// and its content stream for every page.
+func writePDF(w io.Writer, pages [][]string) error {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", pdfText(line))
+		}
+		fmt.Fprintf(&content, "ET\nBT\n/F1 %d Tf\n%d %d Td\n(Page %d of %d) Tj\nET", pdfFontSize,
+			pdfPageWidth-pdfMargin-70, pdfMargin/2, i+1, len(pages))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfText escapes a line for a PDF string literal. Anything outside
+// printable ASCII is replaced, since the font is used with a single-byte
+// encoding.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"project/internal/ledger"
+)
+
+func sample(entries int) Statement {
+	s := Statement{
+		WalletID: uuid.New(),
+		From:     Date(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)),
+		To:       Date(time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)),
+		Opening:  100,
+	}
+	balance := s.Opening
+	for i := 0; i < entries; i++ {
+		amount := int64(10)
+		if i%2 == 1 {
+			amount = -4
+		}
+		balance += amount
+		s.Entries = append(s.Entries, ledger.Entry{
+			ID: int64(i + 1), WalletID: s.WalletID, OperationType: ledger.Deposit, Amount: amount, Balance: balance,
+			ExternalRef: "ref (" + strconv.Itoa(i) + ")", CreatedAt: time.Date(2025, 10, 2, 12, 0, i, 0, time.UTC),
+		})
+	}
+	s.Total()
+	return s
+}
+
+func TestTotal(t *testing.T) {
+	s := sample(3)
+	require.Equal(t, int64(20), s.Credits)
+	require.Equal(t, int64(4), s.Debits)
+	require.Equal(t, int64(116), s.Closing)
+	require.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), s.End())
+}
+
+func TestWriteCSV(t *testing.T) {
+	s := sample(2)
+	s.Entries[0].ExternalRef = "=cmd"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, s))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	require.Equal(t, []string{"", "2025-10-01T00:00:00Z", "OPENING_BALANCE", "", "100", ""}, records[1])
+	require.Equal(t, "'=cmd", records[2][5])
+	require.Equal(t, []string{"", "2025-11-01T00:00:00Z", "CLOSING_BALANCE", "", "106", ""}, records[4])
+}
+
+func TestWritePDF_Structure(t *testing.T) {
+	s := sample(150)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, s))
+	pdf := buf.Bytes()
+
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	require.Contains(t, string(pdf), `/Count 3`)
+	require.Contains(t, string(pdf), `ref \(0\)`)
+
+	// Every xref offset must point at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	require.NotNil(t, m)
+	xref, _ := strconv.Atoi(string(m[1]))
+	require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	for i, line := range lines[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		off, _ := strconv.Atoi(line[:10])
+		require.True(t, bytes.HasPrefix(pdf[off:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DateLayout = "2006-01-02"
+
+// Statement covers the UTC days From through To, both inclusive.
+type Statement struct {
+	WalletID uuid.UUID      `json:"walletId"`
+	From     Date           `json:"from"`
+	To       Date           `json:"to"`
+	Opening  int64          `json:"openingBalance"`
+	Credits  int64          `json:"totalCredits"`
+	Debits   int64          `json:"totalDebits"`
+	Closing  int64          `json:"closingBalance"`
+	Entries  []ledger.Entry `json:"entries"`
+}
+
+// Date is a calendar day that marshals as YYYY-MM-DD.
+type Date time.Time
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(d).Format(DateLayout) + `"`), nil
+}
+
+func (d Date) String() string {
+	return time.Time(d).Format(DateLayout)
+}
+
+// Start and End bound the days of a statement as a half-open range.
+func (s Statement) Start() time.Time {
+	return time.Time(s.From)
+}
+
+func (s Statement) End() time.Time {
+	return time.Time(s.To).AddDate(0, 0, 1)
+}
+
+// Total folds the entries into the statement's totals and closing balance.
+func (s *Statement) Total() {
+	s.Credits, s.Debits = 0, 0
+	for _, e := range s.Entries {
+		if e.Amount >= 0 {
+			s.Credits += e.Amount
+		} else {
+			s.Debits -= e.Amount
+		}
+	}
+	s.Closing = s.Opening + s.Credits - s.Debits
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// finalizeTimeout bounds the writes that close out a cancelled batch.
+const finalizeTimeout = 5 * time.Second
+
+func (f *StorageFacade) ApplyBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error) {
+	b := batch.New(mode, ops)
+	if mode == batch.Atomic {
+		return f.applyAtomic(ctx, b)
+	}
+	return f.applyBestEffort(ctx, b)
+}
+
+func (f *StorageFacade) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	return f.pgRepository.GetBatch(ctx, batchId)
+}
+
+func (f *StorageFacade) apply(ctxTx context.Context, op batch.Operation) (ledger.Entry, error) {
+	switch op.OperationType {
+	case ledger.Deposit:
+		return f.deposit(ctxTx, op.WalletID, op.Amount)
+	case ledger.Withdraw:
+		return f.withdraw(ctxTx, op.WalletID, op.Amount, op.Fee, "")
+	}
+	return ledger.Entry{}, errors.New("invalid operationType parameter")
+}
+
+func (f *StorageFacade) applyAtomic(ctx context.Context, b batch.Batch) (batch.Batch, error) {
+	failed := -1
+	var cause error
+
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		failed, cause = -1, nil
+
+		// Lock every wallet up front in a stable order so that two batches
+		// touching the same wallets cannot deadlock each other.
+		for _, walletId := range lockOrder(b.Items) {
+			if err := f.pgRepository.LockBalance(ctxTx, walletId); err != nil {
+				failed, cause = firstItemFor(b.Items, walletId), err
+				return err
+			}
+		}
+
+		for i := range b.Items {
+			entry, err := f.apply(ctxTx, b.Items[i].Operation)
+			if err != nil {
+				failed, cause = i, err
+				return err
+			}
+			b.Items[i].Status = batch.ItemSucceeded
+			b.Items[i].LedgerEntryID = entry.ID
+		}
+
+		b.Status = batch.Completed
+		return f.saveBatch(ctxTx, b)
+	})
+	if err == nil {
+		return b, nil
+	}
+	if failed < 0 || !errors.Is(err, cause) {
+		return batch.Batch{}, err
+	}
+
+	for i := range b.Items {
+		b.Items[i].Status = batch.ItemRolledBack
+		b.Items[i].LedgerEntryID = 0
+	}
+	b.Items[failed].Status = batch.ItemFailed
+	b.Items[failed].Error = cause.Error()
+	b.Status = batch.Failed
+
+	if err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.saveBatch(ctxTx, b)
+	}); err != nil {
+		return batch.Batch{}, err
+	}
+	return b, nil
+}
+
+func (f *StorageFacade) applyBestEffort(ctx context.Context, b batch.Batch) (batch.Batch, error) {
+	if err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.pgRepository.InsertBatch(ctxTx, b)
+	}); err != nil {
+		return batch.Batch{}, err
+	}
+
+	succeeded := 0
+	for i := range b.Items {
+		item := &b.Items[i]
+		if ctx.Err() != nil {
+			return f.cancelBatch(ctx, b, i, succeeded)
+		}
+
+		err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+			entry, err := f.apply(ctxTx, item.Operation)
+			if err != nil {
+				return err
+			}
+			item.Status = batch.ItemSucceeded
+			item.LedgerEntryID = entry.ID
+			return f.pgRepository.InsertBatchItem(ctxTx, b.ID, *item)
+		})
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if ctx.Err() != nil {
+			return f.cancelBatch(ctx, b, i, succeeded)
+		}
+
+		item.Status = batch.ItemFailed
+		item.Error = err.Error()
+		item.LedgerEntryID = 0
+		if err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+			return f.pgRepository.InsertBatchItem(ctxTx, b.ID, *item)
+		}); err != nil {
+			return batch.Batch{}, err
+		}
+	}
+
+	b.Status = bestEffortStatus(succeeded, len(b.Items))
+	if err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.pgRepository.UpdateBatchStatus(ctxTx, b.ID, b.Status)
+	}); err != nil {
+		return batch.Batch{}, err
+	}
+	return b, nil
+}
+
+// cancelBatch finalizes a best-effort batch whose context ended before item
+// from was applied: the remaining items are recorded as failed and the batch
+// leaves PROCESSING. The writes run on a detached context, since the caller's
+// is already done; the cancellation is still returned to the caller. An item
+// whose commit raced the cancellation keeps the row it wrote.
+func (f *StorageFacade) cancelBatch(ctx context.Context, b batch.Batch, from, succeeded int) (batch.Batch, error) {
+	cause := ctx.Err()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalizeTimeout)
+	defer cancel()
+
+	for i := from; i < len(b.Items); i++ {
+		b.Items[i].Status = batch.ItemFailed
+		b.Items[i].Error = cause.Error()
+		b.Items[i].LedgerEntryID = 0
+	}
+	b.Status = bestEffortStatus(succeeded, len(b.Items))
+
+	if err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		for _, item := range b.Items[from:] {
+			if err := f.pgRepository.InsertBatchItem(ctxTx, b.ID, item); err != nil {
+				return err
+			}
+		}
+		return f.pgRepository.UpdateBatchStatus(ctxTx, b.ID, b.Status)
+	}); err != nil {
+		return batch.Batch{}, err
+	}
+	return batch.Batch{}, cause
+}
+
+func bestEffortStatus(succeeded, total int) batch.Status {
+	switch succeeded {
+	case total:
+		return batch.Completed
+	case 0:
+		return batch.Failed
+	}
+	return batch.Partial
+}
+
+func (f *StorageFacade) saveBatch(ctxTx context.Context, b batch.Batch) error {
+	if err := f.pgRepository.InsertBatch(ctxTx, b); err != nil {
+		return err
+	}
+	for _, item := range b.Items {
+		if err := f.pgRepository.InsertBatchItem(ctxTx, b.ID, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lockOrder(items []batch.Item) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(items))
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.WalletID]; ok {
+			continue
+		}
+		seen[item.WalletID] = struct{}{}
+		ids = append(ids, item.WalletID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+	return ids
+}
+
+func firstItemFor(items []batch.Item, walletId uuid.UUID) int {
+	for i, item := range items {
+		if item.WalletID == walletId {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"project/internal/storage/mocks"
+)
+
+func passThroughTx(tm *mocks.MockTransactionManager) {
+	tm.
+		EXPECT().
+		RunSerializable(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+}
+
+func TestApplyBatch_Atomic_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a, b := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().LockBalance(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().LockActive(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	repo.EXPECT().GetById(gomock.Any(), b).Return(int64(100), nil)
+	repo.EXPECT().EnsureMainBalance(gomock.Any(), b, int64(30)).Return(nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), a, ledger.Deposit, int64(50)).Return(ledger.Entry{ID: 1}, nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), b, ledger.Withdraw, int64(-30)).Return(ledger.Entry{ID: 2}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	repo.EXPECT().InsertBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, got batch.Batch) error {
+		require.Equal(t, batch.Completed, got.Status)
+		return nil
+	})
+	repo.EXPECT().InsertBatchItem(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	f := NewStorageFacade(tm, repo)
+
+	res, err := f.ApplyBatch(context.Background(), batch.Atomic, []batch.Operation{
+		{WalletID: a, OperationType: ledger.Deposit, Amount: 50},
+		{WalletID: b, OperationType: ledger.Withdraw, Amount: 30},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, batch.Completed, res.Status)
+	require.Equal(t, int64(1), res.Items[0].LedgerEntryID)
+	require.Equal(t, int64(2), res.Items[1].LedgerEntryID)
+}
+
+func TestApplyBatch_Atomic_FailureRollsBackEverything(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a, b := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	// The real TxManager discards the first transaction; the failed batch is
+	// then recorded in a second one.
+	gomock.InOrder(
+		tm.EXPECT().RunSerializable(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+				return fn(ctx)
+			}),
+		tm.EXPECT().RunSerializable(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+				return fn(ctx)
+			}),
+	)
+
+	repo.EXPECT().LockBalance(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().LockActive(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().UpdateBalance(gomock.Any(), a, int64(50)).Return(nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), a, ledger.Deposit, int64(50)).Return(ledger.Entry{ID: 1}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().GetById(gomock.Any(), b).Return(int64(10), nil)
+	repo.EXPECT().InsertBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, got batch.Batch) error {
+		require.Equal(t, batch.Failed, got.Status)
+		return nil
+	})
+	repo.EXPECT().InsertBatchItem(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	f := NewStorageFacade(tm, repo)
+
+	res, err := f.ApplyBatch(context.Background(), batch.Atomic, []batch.Operation{
+		{WalletID: a, OperationType: ledger.Deposit, Amount: 50},
+		{WalletID: b, OperationType: ledger.Withdraw, Amount: 30},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, batch.Failed, res.Status)
+	require.Equal(t, batch.ItemRolledBack, res.Items[0].Status)
+	require.Zero(t, res.Items[0].LedgerEntryID)
+	require.Equal(t, batch.ItemFailed, res.Items[1].Status)
+	require.Equal(t, "not enough balance: 10 < 30", res.Items[1].Error)
+}
+
+func TestApplyBatch_Atomic_InfrastructureErrorIsReturned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	tm.EXPECT().RunSerializable(gomock.Any(), gomock.Any()).Return(errAny("tx-fail"))
+
+	f := NewStorageFacade(tm, repo)
+
+	_, err := f.ApplyBatch(context.Background(), batch.Atomic, []batch.Operation{
+		{WalletID: uuid.New(), OperationType: ledger.Deposit, Amount: 1},
+	})
+
+	require.EqualError(t, err, "tx-fail")
+}
+
+func TestApplyBatch_BestEffort_Partial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a, b := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().InsertBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, got batch.Batch) error {
+		require.Equal(t, batch.Processing, got.Status)
+		return nil
+	})
+	repo.EXPECT().LockActive(gomock.Any(), a).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), a, int64(50)).Return(nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), a, ledger.Deposit, int64(50)).Return(ledger.Entry{ID: 7}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().LockActive(gomock.Any(), b).Return(errAny("wallet not found"))
+
+	var items []batch.Item
+	repo.EXPECT().InsertBatchItem(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id uuid.UUID, item batch.Item) error {
+			items = append(items, item)
+			return nil
+		}).Times(2)
+	repo.EXPECT().UpdateBatchStatus(gomock.Any(), gomock.Any(), batch.Partial).Return(nil)
+
+	f := NewStorageFacade(tm, repo)
+
+	res, err := f.ApplyBatch(context.Background(), batch.BestEffort, []batch.Operation{
+		{WalletID: a, OperationType: ledger.Deposit, Amount: 50},
+		{WalletID: b, OperationType: ledger.Deposit, Amount: 30},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, batch.Partial, res.Status)
+	require.Equal(t, batch.ItemSucceeded, items[0].Status)
+	require.Equal(t, int64(7), items[0].LedgerEntryID)
+	require.Equal(t, batch.ItemFailed, items[1].Status)
+	require.Equal(t, "wallet not found", items[1].Error)
+}
+
+func TestApplyBatch_BestEffort_CancelledIsFinalized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a, b := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo.EXPECT().InsertBatch(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().LockActive(gomock.Any(), a).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), a, int64(50)).Return(nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), a, ledger.Deposit, int64(50)).Return(ledger.Entry{ID: 7}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+	var items []batch.Item
+	repo.EXPECT().InsertBatchItem(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctxTx context.Context, id uuid.UUID, item batch.Item) error {
+			items = append(items, item)
+			if len(items) == 1 {
+				cancel()
+			} else {
+				require.NoError(t, ctxTx.Err())
+			}
+			return nil
+		}).Times(2)
+	repo.EXPECT().UpdateBatchStatus(gomock.Any(), gomock.Any(), batch.Partial).
+		DoAndReturn(func(ctxTx context.Context, id uuid.UUID, status batch.Status) error {
+			require.NoError(t, ctxTx.Err())
+			return nil
+		})
+
+	f := NewStorageFacade(tm, repo)
+
+	_, err := f.ApplyBatch(ctx, batch.BestEffort, []batch.Operation{
+		{WalletID: a, OperationType: ledger.Deposit, Amount: 50},
+		{WalletID: b, OperationType: ledger.Deposit, Amount: 30},
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, batch.ItemSucceeded, items[0].Status)
+	require.Equal(t, batch.ItemFailed, items[1].Status)
+	require.Equal(t, b, items[1].WalletID)
+	require.Equal(t, "context canceled", items[1].Error)
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/storage/mocks"
+)
+
+func TestCheckpointAll_PagesThroughWallets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2025, 10, 31, 23, 0, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().InsertBalanceCheckpoints(gomock.Any(), uuid.Nil, at, 2).Return(first, 2, nil),
+		repo.EXPECT().InsertBalanceCheckpoints(gomock.Any(), first, at, 2).Return(second, 1, nil),
+		repo.EXPECT().InsertBalanceCheckpoints(gomock.Any(), second, at, 2).Return(uuid.Nil, 0, nil),
+	)
+
+	c := NewBalanceCheckpointer(tm, repo, time.Hour, 10*time.Minute, 2)
+	n, err := c.CheckpointAll(context.Background(), at)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+}
+
+func TestCheckpointTime_LagsAndAligns(t *testing.T) {
+	c := NewBalanceCheckpointer(nil, nil, time.Hour, 10*time.Minute, 1)
+
+	now := time.Date(2025, 11, 1, 0, 5, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2025, 10, 31, 23, 0, 0, 0, time.UTC), c.checkpointTime(now))
+
+	now = time.Date(2025, 11, 1, 0, 12, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), c.checkpointTime(now))
+}
@@ -2,74 +2,234 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/statement"
 	"project/internal/storage/postgres"
+	"project/internal/wallet"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Facade interface {
 	Deposit(ctx context.Context, walletId uuid.UUID, amount int64) error
-	Withdraw(ctx context.Context, walletId uuid.UUID, amount int64) error
+	Withdraw(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge) error
+	WithdrawRef(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error
+	Transfer(ctx context.Context, from, to uuid.UUID, amount int64, fee ledger.FeeCharge) error
 	GetByID(ctx context.Context, walletId uuid.UUID) (int64, error)
+	GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error)
+	DepositIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error
+	WithdrawIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64, fee ledger.FeeCharge) error
 	Create(ctx context.Context, walletId uuid.UUID) error
+	CreateWithProfile(ctx context.Context, wl wallet.Wallet) error
+	GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error)
+	GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error)
+	ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error)
+	UpdateProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error)
+	SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error)
+	ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error)
+	GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error)
+	GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error)
+	GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time, maxEntries int) (statement.Statement, error)
+	GetLedger(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error)
+	GetLedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error)
+	ApplyBatch(ctx context.Context, mode batch.Mode, ops []batch.Operation) (batch.Batch, error)
+	GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error)
+	SetShardCount(ctx context.Context, walletId uuid.UUID, shards int) error
+	Reverse(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error)
+	Refund(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error
+	GetWithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error)
+	SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error
 }
 
 type StorageFacade struct {
 	txManager    postgres.TransactionManager
 	pgRepository WalletRepo
+	groups       *groupCommitter
+	shardCounts  *shardCounts
+	maxBalance   int64
 }
 
-func NewStorageFacade(txManager postgres.TransactionManager, pgRepository WalletRepo) Facade {
-	return &StorageFacade{
+func NewStorageFacade(txManager postgres.TransactionManager, pgRepository WalletRepo, opts ...Option) Facade {
+	f := &StorageFacade{
 		txManager:    txManager,
 		pgRepository: pgRepository,
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 func (f *StorageFacade) Deposit(ctx context.Context, walletId uuid.UUID, amount int64) error {
-	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
-
-		if err := f.pgRepository.LockBalance(ctxTx, walletId); err != nil {
-			return err
-		}
+	shards, err := f.shardCount(ctx, walletId)
+	if err != nil {
+		return err
+	}
+	if shards > 0 {
+		return f.depositSharded(ctx, walletId, shards, amount)
+	}
+	// Group commit applies deposits in a transaction of its own, which would
+	// escape a caller's transaction and could not honour the caller's retry
+	// policy or timeouts.
+	if f.groups != nil && !postgres.InTransaction(ctx) && !postgres.HasOverrides(ctx) {
+		return f.groups.deposit(ctx, walletId, amount)
+	}
 
-		if err := f.pgRepository.UpdateBalance(ctxTx, walletId, amount); err != nil {
-			return err
-		}
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		_, err := f.deposit(ctxTx, walletId, amount)
+		return err
+	})
+}
 
-		return nil
+func (f *StorageFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		_, err := f.withdraw(ctxTx, walletId, amount, fee, "")
+		return err
 	})
 }
 
-func (f *StorageFacade) Withdraw(ctx context.Context, walletId uuid.UUID, amount int64) error {
+// WithdrawRef is Withdraw with externalRef recorded on the withdrawal's
+// entry, linking it to what it paid for.
+func (f *StorageFacade) WithdrawRef(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
 	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		_, err := f.withdraw(ctxTx, walletId, amount, fee, externalRef)
+		return err
+	})
+}
 
-		if err := f.pgRepository.LockBalance(ctxTx, walletId); err != nil {
-			return err
-		}
+func (f *StorageFacade) deposit(ctxTx context.Context, walletId uuid.UUID, amount int64) (ledger.Entry, error) {
 
-		balance, err := f.pgRepository.GetById(ctxTx, walletId)
-		if err != nil {
-			return err
-		}
+	if err := f.pgRepository.LockActive(ctxTx, walletId); err != nil {
+		return ledger.Entry{}, err
+	}
 
-		if balance < amount {
-			return fmt.Errorf("not enough balance: %d < %d", balance, amount)
-		}
+	if err := f.pgRepository.UpdateBalance(ctxTx, walletId, amount); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	entry, err := f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Deposit, amount)
+	if err != nil {
+		return ledger.Entry{}, err
+	}
+
+	if err := f.checkBalance(entry); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	return entry, f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(entry))
+}
+
+func (f *StorageFacade) withdraw(ctxTx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) (ledger.Entry, error) {
+
+	if err := f.pgRepository.LockActive(ctxTx, walletId); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	balance, err := f.pgRepository.GetById(ctxTx, walletId)
+	if err != nil {
+		return ledger.Entry{}, err
+	}
 
-		if err := f.pgRepository.UpdateBalance(ctxTx, walletId, -amount); err != nil {
+	if balance < amount+fee.Amount {
+		return ledger.Entry{}, fmt.Errorf("not enough balance: %d < %d", balance, amount+fee.Amount)
+	}
+
+	if err := f.pgRepository.EnsureMainBalance(ctxTx, walletId, amount+fee.Amount); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	if err := f.pgRepository.UpdateBalance(ctxTx, walletId, -amount); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	var entry ledger.Entry
+	if externalRef == "" {
+		entry, err = f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Withdraw, -amount)
+	} else {
+		entry, err = f.pgRepository.InsertRefEntry(ctxTx, walletId, ledger.Withdraw, -amount, externalRef)
+	}
+	if err != nil {
+		return ledger.Entry{}, err
+	}
+
+	if err := f.publish(ctxTx, outbox.WalletDebited, walletId, balanceChange(entry)); err != nil {
+		return ledger.Entry{}, err
+	}
+
+	return entry, f.chargeFee(ctxTx, walletId, fee)
+}
+
+func (f *StorageFacade) GetByID(ctx context.Context, walletId uuid.UUID) (int64, error) {
+	var balance int64
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		balance, err = f.pgRepository.GetById(ctxTx, walletId)
+		return err
+	})
+	return balance, err
+}
+
+func (f *StorageFacade) GetLedger(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	var entries []ledger.Entry
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		entries, err = f.pgRepository.ListLedgerEntries(ctxTx, walletId, after, limit)
+		return err
+	})
+	return entries, err
+}
+
+func (f *StorageFacade) GetLedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	var head ledger.Cursor
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		head, err = f.pgRepository.LedgerHead(ctxTx, walletId)
+		return err
+	})
+	return head, err
+}
+
+func (f *StorageFacade) Create(ctx context.Context, walletId uuid.UUID) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.InsertWallet(ctxTx, walletId); err != nil {
 			return err
 		}
 
-		return nil
+		return f.publish(ctxTx, outbox.WalletCreated, walletId, struct{}{})
 	})
 }
 
-func (f *StorageFacade) GetByID(ctx context.Context, walletId uuid.UUID) (int64, error) {
-	return f.pgRepository.GetById(ctx, walletId)
+func (f *StorageFacade) publish(ctx context.Context, eventType outbox.EventType, walletId uuid.UUID, payload any) error {
+	event, err := outbox.NewEvent(eventType, walletId, payload)
+	if err != nil {
+		return err
+	}
+	return f.pgRepository.InsertOutboxEvent(ctx, event)
 }
 
-func (f *StorageFacade) Create(ctx context.Context, walletId uuid.UUID) error {
-	return f.pgRepository.InsertWallet(ctx, walletId)
+// checkBalance runs after a credit has been written, so entry.Balance is the
+// wallet's new balance; failing here rolls the credit back.
+func (f *StorageFacade) checkBalance(entry ledger.Entry) error {
+	if f.maxBalance > 0 && entry.Balance > f.maxBalance {
+		return errors.New("balance limit exceeded")
+	}
+	return nil
+}
+
+func balanceChange(entry ledger.Entry) outbox.BalanceChange {
+	amount := entry.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	return outbox.BalanceChange{
+		Amount:        amount,
+		Balance:       entry.Balance,
+		LedgerEntryID: entry.ID,
+	}
 }
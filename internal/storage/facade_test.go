@@ -7,6 +7,8 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/outbox"
 	"project/internal/storage/mocks"
 )
 
@@ -28,8 +30,17 @@ func TestDeposit_Success(t *testing.T) {
 		})
 
 	gomock.InOrder(
-		repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil),
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
 		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(150)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Deposit, int64(150)).
+			Return(ledger.Entry{ID: 9, WalletID: id, Amount: 150, Balance: 350}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCredited, event.Type)
+			require.Equal(t, id, event.WalletID)
+			require.JSONEq(t, `{"amount":150,"balance":350,"ledgerEntryId":9}`, string(event.Payload))
+			return nil
+		}),
 	)
 
 	f := NewStorageFacade(tm, repo)
@@ -57,14 +68,22 @@ func TestWithdraw_Success(t *testing.T) {
 		})
 
 	gomock.InOrder(
-		repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
 		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(200), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), id, int64(150)).Return(nil),
 		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(-150)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Withdraw, int64(-150)).
+			Return(ledger.Entry{ID: 10, WalletID: id, Amount: -150, Balance: 50}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletDebited, event.Type)
+			require.JSONEq(t, `{"amount":150,"balance":50,"ledgerEntryId":10}`, string(event.Payload))
+			return nil
+		}),
 	)
 
 	f := NewStorageFacade(tm, repo)
 
-	require.NoError(t, f.Withdraw(ctx, id, 150))
+	require.NoError(t, f.Withdraw(ctx, id, 150, ledger.FeeCharge{}))
 }
 
 func TestWithdraw_NotEnoughBalance(t *testing.T) {
@@ -85,13 +104,13 @@ func TestWithdraw_NotEnoughBalance(t *testing.T) {
 		})
 
 	gomock.InOrder(
-		repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
 		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(100), nil),
 	)
 
 	f := NewStorageFacade(tm, repo)
 
-	require.EqualError(t, f.Withdraw(ctx, id, 150), "not enough balance: 100 < 150")
+	require.EqualError(t, f.Withdraw(ctx, id, 150, ledger.FeeCharge{}), "not enough balance: 100 < 150")
 }
 
 func TestWithdraw_LockError(t *testing.T) {
@@ -112,12 +131,12 @@ func TestWithdraw_LockError(t *testing.T) {
 		})
 
 	gomock.InOrder(
-		repo.EXPECT().LockBalance(gomock.Any(), id).Return(errAny("lock-fail")),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(errAny("lock-fail")),
 	)
 
 	f := NewStorageFacade(tm, repo)
 
-	require.EqualError(t, f.Withdraw(ctx, id, 150), "lock-fail")
+	require.EqualError(t, f.Withdraw(ctx, id, 150, ledger.FeeCharge{}), "lock-fail")
 }
 
 func TestDeposit_LockError(t *testing.T) {
@@ -138,7 +157,8 @@ func TestDeposit_LockError(t *testing.T) {
 		})
 
 	gomock.InOrder(
-		repo.EXPECT().LockBalance(gomock.Any(), id).Return(errAny("lock-fail")),
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(errAny("lock-fail")),
 	)
 
 	f := NewStorageFacade(tm, repo)
@@ -160,12 +180,150 @@ func TestDeposit_TxErrors(t *testing.T) {
 		EXPECT().
 		RunSerializable(gomock.Any(), gomock.Any()).
 		Return(errAny("tx-fail"))
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil)
 
 	f := NewStorageFacade(tm, repo)
 
 	require.EqualError(t, f.Deposit(ctx, id, 150), "tx-fail")
 }
 
+func TestCreate_WritesEventInSameTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	tm.
+		EXPECT().
+		RunSerializable(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		})
+
+	gomock.InOrder(
+		repo.EXPECT().InsertWallet(gomock.Any(), id).Return(nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCreated, event.Type)
+			require.Equal(t, id, event.WalletID)
+			return nil
+		}),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.Create(ctx, id))
+}
+
+func TestCreate_InsertError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	tm.
+		EXPECT().
+		RunSerializable(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		})
+
+	repo.EXPECT().InsertWallet(gomock.Any(), id).Return(errAny("wallet already exists"))
+
+	f := NewStorageFacade(tm, repo)
+
+	require.EqualError(t, f.Create(ctx, id), "wallet already exists")
+}
+
 type errAny string
 
 func (e errAny) Error() string { return string(e) }
+
+func TestGetByID_UsesReadOnlyTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	tm.
+		EXPECT().
+		RunReadOnly(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		})
+	repo.EXPECT().GetById(gomock.Any(), id).Return(int64(42), nil)
+
+	f := NewStorageFacade(tm, repo)
+
+	balance, err := f.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), balance)
+}
+
+func TestWithdrawIfVersion_SkipsLockBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), id, int64(40)).Return(nil),
+		repo.EXPECT().UpdateBalanceIfVersion(gomock.Any(), id, int64(-40), int64(3)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Withdraw, int64(-40)).Return(ledger.Entry{ID: 5}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.WithdrawIfVersion(context.Background(), id, 40, 3, ledger.FeeCharge{}))
+}
+
+func TestDepositIfVersion_Mismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().UpdateBalanceIfVersion(gomock.Any(), id, int64(40), int64(3)).Return(errAny("version mismatch"))
+
+	f := NewStorageFacade(tm, repo)
+
+	require.EqualError(t, f.DepositIfVersion(context.Background(), id, 40, 3), "version mismatch")
+}
+
+func TestDeposit_BalanceLimitRollsBack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(60)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Deposit, int64(60)).
+			Return(ledger.Entry{ID: 1, Amount: 60, Balance: 110}, nil),
+	)
+
+	f := NewStorageFacade(tm, repo, WithMaxBalance(100))
+
+	require.EqualError(t, f.Deposit(context.Background(), id, 60), "balance limit exceeded")
+}
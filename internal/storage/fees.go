@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// chargeFee moves fee from walletId, which the caller has locked, to the
+// revenue wallet as a pair of FEE entries. A sharded revenue wallet is
+// credited through one of its shards, so busy fee traffic does not queue on
+// its wallets row.
+func (f *StorageFacade) chargeFee(ctxTx context.Context, walletId uuid.UUID, fee ledger.FeeCharge) error {
+	if fee.Amount == 0 {
+		return nil
+	}
+
+	balance, err := f.pgRepository.GetById(ctxTx, walletId)
+	if err != nil {
+		return err
+	}
+	if balance < fee.Amount {
+		return fmt.Errorf("not enough balance: %d < %d", balance, fee.Amount)
+	}
+	if err := f.pgRepository.UpdateBalance(ctxTx, walletId, -fee.Amount); err != nil {
+		return err
+	}
+	debit, err := f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Fee, -fee.Amount)
+	if err != nil {
+		return err
+	}
+	if err := f.publish(ctxTx, outbox.WalletDebited, walletId, balanceChange(debit)); err != nil {
+		return err
+	}
+
+	shards, err := f.pgRepository.GetShardCount(ctxTx, fee.Revenue)
+	if err != nil {
+		return err
+	}
+	var credit ledger.Entry
+	credited := false
+	if shards > 0 {
+		shard := rand.Intn(shards)
+		credited, err = f.pgRepository.DepositToShard(ctxTx, fee.Revenue, shard, fee.Amount)
+		if err != nil {
+			return err
+		}
+		if credited {
+			credit, err = f.pgRepository.InsertShardEntry(ctxTx, fee.Revenue, shard, ledger.Fee, fee.Amount)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !credited {
+		if err := f.pgRepository.LockBalance(ctxTx, fee.Revenue); err != nil {
+			return err
+		}
+		if err := f.pgRepository.UpdateBalance(ctxTx, fee.Revenue, fee.Amount); err != nil {
+			return err
+		}
+		credit, err = f.pgRepository.InsertLedgerEntry(ctxTx, fee.Revenue, ledger.Fee, fee.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	if err := f.checkBalance(credit); err != nil {
+		return err
+	}
+	return f.publish(ctxTx, outbox.WalletCredited, fee.Revenue, balanceChange(credit))
+}
+
+// refundFee hands fee back from the revenue wallet to walletId, which the
+// caller has locked, as a pair of FEE entries mirroring chargeFee's. Like the
+// refund it goes with, the credit is not held to the balance cap. Whatever
+// the revenue wallet holds in shards is folded back first, so fees credited
+// through a shard can be returned.
+func (f *StorageFacade) refundFee(ctxTx context.Context, walletId uuid.UUID, fee ledger.FeeCharge) error {
+	if fee.Amount == 0 {
+		return nil
+	}
+
+	if err := f.pgRepository.LockBalance(ctxTx, fee.Revenue); err != nil {
+		return err
+	}
+	if err := f.pgRepository.EnsureMainBalance(ctxTx, fee.Revenue, fee.Amount); err != nil {
+		return err
+	}
+	balance, err := f.pgRepository.GetById(ctxTx, fee.Revenue)
+	if err != nil {
+		return err
+	}
+	if balance < fee.Amount {
+		return fmt.Errorf("revenue wallet: not enough balance: %d < %d", balance, fee.Amount)
+	}
+	if err := f.pgRepository.UpdateBalance(ctxTx, fee.Revenue, -fee.Amount); err != nil {
+		return err
+	}
+	debit, err := f.pgRepository.InsertLedgerEntry(ctxTx, fee.Revenue, ledger.Fee, -fee.Amount)
+	if err != nil {
+		return err
+	}
+	if err := f.publish(ctxTx, outbox.WalletDebited, fee.Revenue, balanceChange(debit)); err != nil {
+		return err
+	}
+
+	if err := f.pgRepository.UpdateBalance(ctxTx, walletId, fee.Amount); err != nil {
+		return err
+	}
+	credit, err := f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Fee, fee.Amount)
+	if err != nil {
+		return err
+	}
+	return f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(credit))
+}
+
+// GetWithdrawnSince sums the wallet's withdrawals from since onwards, the
+// volume fee tiers are priced on.
+func (f *StorageFacade) GetWithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error) {
+	var total int64
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		total, err = f.pgRepository.WithdrawnSince(ctxTx, walletId, since)
+		return err
+	})
+	return total, err
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/batch"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+)
+
+func TestWithdraw_WithFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, revenue := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(200), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), id, int64(160)).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(-150)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Withdraw, int64(-150)).
+			Return(ledger.Entry{ID: 10, WalletID: id, Amount: -150, Balance: 50}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(50), nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(-10)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Fee, int64(-10)).
+			Return(ledger.Entry{ID: 11, WalletID: id, Amount: -10, Balance: 40}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletDebited, event.Type)
+			require.JSONEq(t, `{"amount":10,"balance":40,"ledgerEntryId":11}`, string(event.Payload))
+			return nil
+		}),
+		repo.EXPECT().GetShardCount(gomock.Any(), revenue).Return(0, nil),
+		repo.EXPECT().LockBalance(gomock.Any(), revenue).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), revenue, int64(10)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), revenue, ledger.Fee, int64(10)).
+			Return(ledger.Entry{ID: 12, WalletID: revenue, Amount: 10, Balance: 1010}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCredited, event.Type)
+			require.Equal(t, revenue, event.WalletID)
+			return nil
+		}),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.Withdraw(context.Background(), id, 150, ledger.FeeCharge{Amount: 10, Revenue: revenue}))
+}
+
+func TestWithdraw_FeeNotCovered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(150), nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	err := f.Withdraw(context.Background(), id, 150, ledger.FeeCharge{Amount: 10, Revenue: uuid.New()})
+	require.EqualError(t, err, "not enough balance: 150 < 160")
+}
+
+func TestChargeFee_ShardedRevenue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, revenue := uuid.New(), uuid.New()
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	gomock.InOrder(
+		repo.EXPECT().GetById(gomock.Any(), id).Return(int64(50), nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(-10)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Fee, int64(-10)).Return(ledger.Entry{ID: 11}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().GetShardCount(gomock.Any(), revenue).Return(4, nil),
+		repo.EXPECT().DepositToShard(gomock.Any(), revenue, gomock.Any(), int64(10)).Return(true, nil),
+		repo.EXPECT().InsertShardEntry(gomock.Any(), revenue, gomock.Any(), ledger.Fee, int64(10)).Return(ledger.Entry{ID: 12}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := &StorageFacade{pgRepository: repo}
+
+	require.NoError(t, f.chargeFee(context.Background(), id, ledger.FeeCharge{Amount: 10, Revenue: revenue}))
+}
+
+func TestApplyBatch_WithdrawalPaysFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, revenue := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().LockBalance(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().LockActive(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().GetById(gomock.Any(), id).Return(int64(100), nil).Times(2)
+	repo.EXPECT().EnsureMainBalance(gomock.Any(), id, int64(35)).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Withdraw, int64(-30)).Return(ledger.Entry{ID: 1}, nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Fee, int64(-5)).Return(ledger.Entry{ID: 2}, nil)
+	repo.EXPECT().GetShardCount(gomock.Any(), revenue).Return(0, nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), revenue, ledger.Fee, int64(5)).Return(ledger.Entry{ID: 3}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	repo.EXPECT().InsertBatch(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().InsertBatchItem(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	f := NewStorageFacade(tm, repo)
+
+	res, err := f.ApplyBatch(context.Background(), batch.Atomic, []batch.Operation{
+		{WalletID: id, OperationType: ledger.Withdraw, Amount: 30, Fee: ledger.FeeCharge{Amount: 5, Revenue: revenue}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.Items[0].LedgerEntryID)
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"project/internal/ledger"
+	"project/internal/lsn"
+	"project/internal/money"
+	"project/internal/outbox"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const groupCommitTimeout = 5 * time.Second
+
+type Option func(*StorageFacade)
+
+// WithMaxBalance rejects credits that would take a wallet above max.
+func WithMaxBalance(max int64) Option {
+	return func(f *StorageFacade) {
+		f.maxBalance = max
+	}
+}
+
+// WithGroupCommit makes concurrent deposits to the same wallet that arrive
+// within window share one transaction and one balance update. Each deposit
+// still gets its own ledger entry and outbox event. A group is flushed early
+// once it holds maxBatch deposits.
+func WithGroupCommit(window time.Duration, maxBatch int) Option {
+	return func(f *StorageFacade) {
+		if window <= 0 {
+			return
+		}
+		if maxBatch <= 0 {
+			maxBatch = 100
+		}
+		f.groups = &groupCommitter{
+			f:        f,
+			window:   window,
+			maxBatch: maxBatch,
+			pending:  make(map[uuid.UUID]*depositGroup),
+		}
+	}
+}
+
+const (
+	requestQueued int32 = iota
+	requestTaken
+	requestAbandoned
+)
+
+type depositRequest struct {
+	ctx    context.Context
+	amount int64
+	done   chan error
+	// state moves from queued to taken when its group starts, or to
+	// abandoned when the caller gives up first.
+	state atomic.Int32
+}
+
+type depositGroup struct {
+	walletId uuid.UUID
+	reqs     []*depositRequest
+}
+
+type groupCommitter struct {
+	f        *StorageFacade
+	window   time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]*depositGroup
+}
+
+// deposit queues the amount and waits for its group to commit. A caller
+// whose context ends while the deposit is still queued leaves the group;
+// once the group has taken it, the caller always gets its real outcome.
+func (g *groupCommitter) deposit(ctx context.Context, walletId uuid.UUID, amount int64) error {
+	req := &depositRequest{ctx: ctx, amount: amount, done: make(chan error, 1)}
+
+	g.mu.Lock()
+	grp, ok := g.pending[walletId]
+	if !ok {
+		grp = &depositGroup{walletId: walletId}
+		g.pending[walletId] = grp
+		time.AfterFunc(g.window, func() { g.flush(grp) })
+	}
+	grp.reqs = append(grp.reqs, req)
+	full := len(grp.reqs) >= g.maxBatch
+	if full {
+		delete(g.pending, walletId)
+	}
+	g.mu.Unlock()
+
+	if full {
+		go g.commit(grp)
+	}
+
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		if req.state.CompareAndSwap(requestQueued, requestAbandoned) {
+			return ctx.Err()
+		}
+		return <-req.done
+	}
+}
+
+func (g *groupCommitter) flush(grp *depositGroup) {
+	g.mu.Lock()
+	if g.pending[grp.walletId] != grp {
+		g.mu.Unlock()
+		return
+	}
+	delete(g.pending, grp.walletId)
+	g.mu.Unlock()
+
+	g.commit(grp)
+}
+
+func (g *groupCommitter) commit(grp *depositGroup) {
+	var (
+		live  []*depositRequest
+		total money.Amount
+	)
+	for _, req := range grp.reqs {
+		if err := req.ctx.Err(); err != nil {
+			if req.state.CompareAndSwap(requestQueued, requestAbandoned) {
+				req.done <- err
+			}
+			continue
+		}
+		if !req.state.CompareAndSwap(requestQueued, requestTaken) {
+			continue
+		}
+		sum, err := total.Add(money.Amount(req.amount))
+		if err != nil {
+			g.apply(grp.walletId, live, int64(total))
+			live, sum = nil, money.Amount(req.amount)
+		}
+		live = append(live, req)
+		total = sum
+	}
+	if len(live) > 0 {
+		g.apply(grp.walletId, live, int64(total))
+	}
+}
+
+// apply commits reqs in one transaction. If that fails, each request is
+// retried in a transaction of its own, so a deposit the group cannot take,
+// such as one over the balance cap, fails only its own caller.
+func (g *groupCommitter) apply(walletId uuid.UUID, reqs []*depositRequest, total int64) {
+	// Grouped requests carry no retry or timeout overrides; the group runs
+	// with the first one's values, but not its cancellation.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(reqs[0].ctx), groupCommitTimeout)
+	defer cancel()
+	ctx, tracker := lsn.WithTracker(ctx)
+
+	amounts := make([]int64, len(reqs))
+	for i, req := range reqs {
+		amounts[i] = req.amount
+	}
+
+	err := g.f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := g.f.pgRepository.LockActive(ctxTx, walletId); err != nil {
+			return err
+		}
+
+		if err := g.f.pgRepository.UpdateBalance(ctxTx, walletId, total); err != nil {
+			return err
+		}
+
+		entries, err := g.f.pgRepository.InsertLedgerEntries(ctxTx, walletId, ledger.Deposit, amounts)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := g.f.checkBalance(entry); err != nil {
+				return err
+			}
+			if err := g.f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(entry)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && len(reqs) > 1 {
+		for _, req := range reqs {
+			req.done <- g.applyOne(walletId, req)
+		}
+		return
+	}
+
+	for _, req := range reqs {
+		if t := lsn.TrackerFrom(req.ctx); t != nil && err == nil {
+			t.Set(tracker.Get())
+		}
+		req.done <- err
+	}
+}
+
+// applyOne deposits a single request the way Deposit does without group
+// commit, under the caller's own context.
+func (g *groupCommitter) applyOne(walletId uuid.UUID, req *depositRequest) error {
+	return g.f.txManager.RunSerializable(req.ctx, func(ctxTx context.Context) error {
+		_, err := g.f.deposit(ctxTx, walletId, req.amount)
+		return err
+	})
+}
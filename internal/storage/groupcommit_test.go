@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+	"project/internal/storage/postgres"
+)
+
+func TestGroupCommit_CoalescesConcurrentDeposits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil).Times(3)
+	repo.EXPECT().LockActive(gomock.Any(), id).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(60)).Return(nil)
+	repo.EXPECT().InsertLedgerEntries(gomock.Any(), id, ledger.Deposit, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amounts []int64) ([]ledger.Entry, error) {
+			got := append([]int64(nil), amounts...)
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			require.Equal(t, []int64{10, 20, 30}, got)
+
+			entries := make([]ledger.Entry, len(amounts))
+			for i, amount := range amounts {
+				entries[i] = ledger.Entry{ID: int64(i + 1), WalletID: walletId, Amount: amount}
+			}
+			return entries, nil
+		})
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+		require.Equal(t, outbox.WalletCredited, event.Type)
+		return nil
+	}).Times(3)
+
+	f := NewStorageFacade(tm, repo, WithGroupCommit(time.Second, 3))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i, amount := range []int64{10, 20, 30} {
+		wg.Add(1)
+		go func(i int, amount int64) {
+			defer wg.Done()
+			errs[i] = f.Deposit(context.Background(), id, amount)
+		}(i, amount)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestGroupCommit_BadDepositFailsAlone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil).Times(3)
+	// The group transaction, then one per request.
+	repo.EXPECT().LockActive(gomock.Any(), id).Return(nil).Times(4)
+	repo.EXPECT().UpdateBalance(gomock.Any(), id, gomock.Any()).Return(nil).Times(4)
+	repo.EXPECT().InsertLedgerEntries(gomock.Any(), id, ledger.Deposit, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amounts []int64) ([]ledger.Entry, error) {
+			entries := make([]ledger.Entry, len(amounts))
+			var balance int64
+			for i, amount := range amounts {
+				balance += amount
+				entries[i] = ledger.Entry{ID: int64(i + 1), WalletID: walletId, Amount: amount, Balance: balance}
+			}
+			return entries, nil
+		})
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Deposit, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+			return ledger.Entry{WalletID: walletId, Amount: amount, Balance: amount}, nil
+		}).Times(3)
+
+	f := NewStorageFacade(tm, repo, WithMaxBalance(100), WithGroupCommit(time.Second, 3))
+
+	var wg sync.WaitGroup
+	amounts := []int64{10, 200, 20}
+	errs := make([]error, len(amounts))
+	for i, amount := range amounts {
+		wg.Add(1)
+		go func(i int, amount int64) {
+			defer wg.Done()
+			errs[i] = f.Deposit(context.Background(), id, amount)
+		}(i, amount)
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.EqualError(t, errs[1], "balance limit exceeded")
+	require.NoError(t, errs[2])
+}
+
+func TestGroupCommit_CallerLeavesQueueOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil)
+
+	f := NewStorageFacade(tm, repo, WithGroupCommit(100*time.Millisecond, 100))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	require.ErrorIs(t, f.Deposit(ctx, id, 5), context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// The flush finds nothing left to apply.
+	time.Sleep(150 * time.Millisecond)
+}
+
+func TestGroupCommit_OverridesRunAlone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	ctx := postgres.WithTimeouts(context.Background(), postgres.Timeouts{Lock: time.Second})
+	gomock.InOrder(
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil),
+		tm.EXPECT().RunSerializable(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+				return fn(ctx)
+			}),
+	)
+	repo.EXPECT().LockActive(gomock.Any(), id).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(5)).Return(nil)
+	repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Deposit, int64(5)).Return(ledger.Entry{ID: 1}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+	f := NewStorageFacade(tm, repo, WithGroupCommit(time.Second, 100))
+
+	require.NoError(t, f.Deposit(ctx, id, 5))
+}
+
+func TestGroupCommit_CancelledCallerIsDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil)
+
+	f := NewStorageFacade(tm, repo, WithGroupCommit(5*time.Millisecond, 100))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, f.Deposit(ctx, id, 5), context.Canceled)
+}
+
+// benchTx models row locks held until commit: LockActive takes the wallet's
+// mutex and RunSerializable releases it after a fixed commit latency.
+type benchTx struct {
+	postgres.TransactionManager
+	latency time.Duration
+	locks   sync.Map
+
+	// committing counts transactions between their last statement and
+	// commit; maxCommitting is the most seen at once.
+	committing    atomic.Int64
+	maxCommitting atomic.Int64
+}
+
+type benchTxKey struct{}
+
+func (tm *benchTx) run(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	var held []*sync.Mutex
+	err := fn(context.WithValue(ctx, benchTxKey{}, &held))
+	n := tm.committing.Add(1)
+	for {
+		max := tm.maxCommitting.Load()
+		if n <= max || tm.maxCommitting.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(tm.latency)
+	tm.committing.Add(-1)
+	for _, mu := range held {
+		mu.Unlock()
+	}
+	return err
+}
+
+func (tm *benchTx) RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	return tm.run(ctx, fn)
+}
+func (tm *benchTx) RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	return tm.run(ctx, fn)
+}
+
+type benchRepo struct {
+	WalletRepo
+	tx      *benchTx
+	shards  int
+	balance atomic.Int64
+	nextId  atomic.Int64
+}
+
+type benchShardKey struct {
+	walletId uuid.UUID
+	shard    int
+}
+
+func (r *benchRepo) lock(ctx context.Context, key any) {
+	v, _ := r.tx.locks.LoadOrStore(key, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	held := ctx.Value(benchTxKey{}).(*[]*sync.Mutex)
+	*held = append(*held, mu)
+}
+
+func (r *benchRepo) GetShardCount(ctx context.Context, walletId uuid.UUID) (int, error) {
+	return r.shards, nil
+}
+func (r *benchRepo) LockActive(ctx context.Context, walletId uuid.UUID) error {
+	r.lock(ctx, walletId)
+	return nil
+}
+func (r *benchRepo) DepositToShard(ctx context.Context, walletId uuid.UUID, shard int, amount int64) (bool, error) {
+	r.lock(ctx, benchShardKey{walletId, shard})
+	r.balance.Add(amount)
+	return true, nil
+}
+func (r *benchRepo) InsertShardEntry(ctx context.Context, walletId uuid.UUID, shard int, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+	return ledger.Entry{ID: r.nextId.Add(1), WalletID: walletId, Amount: amount, Balance: r.balance.Load(), Shard: &shard}, nil
+}
+func (r *benchRepo) UpdateBalance(ctx context.Context, walletId uuid.UUID, diff int64) error {
+	r.balance.Add(diff)
+	return nil
+}
+func (r *benchRepo) InsertLedgerEntry(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+	return ledger.Entry{ID: r.nextId.Add(1), WalletID: walletId, Amount: amount, Balance: r.balance.Load()}, nil
+}
+func (r *benchRepo) InsertLedgerEntries(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amounts []int64) ([]ledger.Entry, error) {
+	entries := make([]ledger.Entry, len(amounts))
+	for i, amount := range amounts {
+		entries[i] = ledger.Entry{ID: r.nextId.Add(1), WalletID: walletId, Amount: amount}
+	}
+	return entries, nil
+}
+func (r *benchRepo) InsertOutboxEvent(ctx context.Context, event outbox.Event) error {
+	return nil
+}
+
+func benchmarkHotWalletDeposits(b *testing.B, shards int, opts ...Option) {
+	tx := &benchTx{latency: 200 * time.Microsecond}
+	repo := &benchRepo{tx: tx, shards: shards}
+	f := NewStorageFacade(tx, repo, opts...)
+	id := uuid.New()
+
+	b.SetParallelism(64)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := f.Deposit(context.Background(), id, 1); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.StopTimer()
+
+	if repo.balance.Load() != int64(b.N) {
+		b.Fatalf("balance %d, want %d", repo.balance.Load(), b.N)
+	}
+}
+
+func BenchmarkDeposit_OneTxPerRequest(b *testing.B) {
+	benchmarkHotWalletDeposits(b, 0)
+}
+
+func BenchmarkDeposit_GroupCommit(b *testing.B) {
+	benchmarkHotWalletDeposits(b, 0, WithGroupCommit(2*time.Millisecond, 256))
+}
+
+func BenchmarkDeposit_Sharded(b *testing.B) {
+	benchmarkHotWalletDeposits(b, 16)
+}
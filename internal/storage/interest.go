@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"project/internal/interest"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/postgres"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetInterestRate changes a savings wallet's annual rate for the days not
+// accrued yet.
+func (f *StorageFacade) SetInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.pgRepository.UpdateInterestRate(ctxTx, walletId, rateBps)
+	})
+}
+
+// InterestJob accrues interest on savings wallets for every UTC day that
+// has closed at least lag ago, and once the last day of a month is accrued
+// pays the month's interest out of the treasury wallet. Days and months are
+// done in order and recorded when complete, so after a crash the job picks
+// up where it stopped; rows already written for a period are left alone.
+type InterestJob struct {
+	f         *StorageFacade
+	treasury  uuid.UUID
+	interval  time.Duration
+	lag       time.Duration
+	batchSize int
+}
+
+func NewInterestJob(txManager postgres.TransactionManager, pgRepository WalletRepo, treasury uuid.UUID, interval, lag time.Duration, batchSize int) *InterestJob {
+	return &InterestJob{
+		f:         &StorageFacade{txManager: txManager, pgRepository: pgRepository},
+		treasury:  treasury,
+		interval:  interval,
+		lag:       lag,
+		batchSize: batchSize,
+	}
+}
+
+func (j *InterestJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := j.CatchUp(ctx, now); err != nil {
+				log.Printf("interest job: %v", err)
+			}
+		}
+	}
+}
+
+func (j *InterestJob) CatchUp(ctx context.Context, now time.Time) error {
+	last := interest.Day(now.Add(-j.lag)).AddDate(0, 0, -1)
+
+	_, accrued, err := j.runRange(ctx, interest.AccrualJob)
+	if err != nil {
+		return err
+	}
+	day := accrued.AddDate(0, 0, 1)
+	if accrued.IsZero() {
+		day = last
+	}
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if _, err := j.AccrueDay(ctx, day); err != nil {
+			return err
+		}
+	}
+
+	first, accrued, err := j.runRange(ctx, interest.AccrualJob)
+	if err != nil || accrued.IsZero() {
+		return err
+	}
+	_, posted, err := j.runRange(ctx, interest.PostingJob)
+	if err != nil {
+		return err
+	}
+	month := interest.Month(first)
+	if !posted.IsZero() {
+		month = posted.AddDate(0, 1, 0)
+	}
+	for ; !interest.LastDay(month).After(accrued); month = month.AddDate(0, 1, 0) {
+		if _, err := j.PostMonth(ctx, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *InterestJob) runRange(ctx context.Context, job interest.Job) (first, last time.Time, err error) {
+	err = j.f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		first, last, err = j.f.pgRepository.InterestRunRange(ctxTx, job)
+		return err
+	})
+	return first, last, err
+}
+
+// AccrueDay accrues day for every savings wallet and returns how many
+// accruals it wrote; wallets accrued by an earlier run are skipped. Days
+// must be accrued in order, since each carries its remainder into the next.
+func (j *InterestJob) AccrueDay(ctx context.Context, day time.Time) (int, error) {
+	day = interest.Day(day)
+	_, accrued, err := j.runRange(ctx, interest.AccrualJob)
+	if err != nil {
+		return 0, err
+	}
+	if !accrued.IsZero() && day.After(accrued.AddDate(0, 0, 1)) {
+		return 0, fmt.Errorf("accrue %s first", accrued.AddDate(0, 0, 1).Format("2006-01-02"))
+	}
+
+	written, wallets := 0, 0
+	after := uuid.Nil
+	for {
+		var inputs []interest.Input
+		if err := j.f.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+			var err error
+			inputs, err = j.f.pgRepository.ListAccrualInputs(ctxTx, after, day, j.batchSize)
+			if err != nil {
+				return err
+			}
+
+			accruals := make([]interest.Accrual, len(inputs))
+			for i, in := range inputs {
+				accruals[i] = interest.Accrue(in, day)
+			}
+			n, err := j.f.pgRepository.InsertInterestAccruals(ctxTx, accruals)
+			written += n
+			return err
+		}); err != nil {
+			return written, err
+		}
+
+		if len(inputs) == 0 {
+			break
+		}
+		wallets += len(inputs)
+		after = inputs[len(inputs)-1].WalletID
+	}
+
+	return written, j.f.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+		return j.f.pgRepository.InsertInterestRun(ctxTx, interest.AccrualJob, day, wallets)
+	})
+}
+
+// PostMonth pays every savings wallet what it accrued over month, each in
+// its own transaction with the posting that keeps it from being paid twice,
+// and returns how many wallets it paid. Every day of the month must have
+// been accrued: a wallet is only ever paid once per month. A wallet that is
+// not ACTIVE is skipped and goes without that month's interest; a treasury
+// that is not ACTIVE stops the run.
+func (j *InterestJob) PostMonth(ctx context.Context, month time.Time) (int, error) {
+	month = interest.Month(month)
+	_, accrued, err := j.runRange(ctx, interest.AccrualJob)
+	if err != nil {
+		return 0, err
+	}
+	if accrued.Before(interest.LastDay(month)) {
+		return 0, fmt.Errorf("%s is not fully accrued", month.Format("2006-01"))
+	}
+
+	posted := 0
+	after := uuid.Nil
+	for {
+		var due []interest.Due
+		if err := j.f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+			var err error
+			due, err = j.f.pgRepository.ListInterestDue(ctxTx, month, after, j.batchSize)
+			return err
+		}); err != nil {
+			return posted, err
+		}
+		if len(due) == 0 {
+			break
+		}
+
+		for _, d := range due {
+			err := j.post(ctx, month, d)
+			if err != nil && (err.Error() == "interest already posted" || strings.HasPrefix(err.Error(), "wallet is ")) {
+				continue
+			}
+			if err != nil {
+				return posted, err
+			}
+			posted++
+		}
+		after = due[len(due)-1].WalletID
+	}
+
+	return posted, j.f.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+		return j.f.pgRepository.InsertInterestRun(ctxTx, interest.PostingJob, month, posted)
+	})
+}
+
+func (j *InterestJob) post(ctx context.Context, month time.Time, d interest.Due) error {
+	f := j.f
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.LockActive(ctxTx, j.treasury); err != nil {
+			return fmt.Errorf("treasury: %w", err)
+		}
+		balance, err := f.pgRepository.GetById(ctxTx, j.treasury)
+		if err != nil {
+			return err
+		}
+		if balance < d.Amount {
+			return fmt.Errorf("treasury: not enough balance: %d < %d", balance, d.Amount)
+		}
+		if err := f.pgRepository.EnsureMainBalance(ctxTx, j.treasury, d.Amount); err != nil {
+			return err
+		}
+		if err := f.pgRepository.UpdateBalance(ctxTx, j.treasury, -d.Amount); err != nil {
+			return err
+		}
+		debit, err := f.pgRepository.InsertLedgerEntry(ctxTx, j.treasury, ledger.Interest, -d.Amount)
+		if err != nil {
+			return err
+		}
+		if err := f.publish(ctxTx, outbox.WalletDebited, j.treasury, balanceChange(debit)); err != nil {
+			return err
+		}
+
+		if err := f.pgRepository.LockActive(ctxTx, d.WalletID); err != nil {
+			return err
+		}
+		if err := f.pgRepository.UpdateBalance(ctxTx, d.WalletID, d.Amount); err != nil {
+			return err
+		}
+		credit, err := f.pgRepository.InsertLedgerEntry(ctxTx, d.WalletID, ledger.Interest, d.Amount)
+		if err != nil {
+			return err
+		}
+		if err := f.publish(ctxTx, outbox.WalletCredited, d.WalletID, balanceChange(credit)); err != nil {
+			return err
+		}
+
+		return f.pgRepository.InsertInterestPosting(ctxTx, interest.Posting{
+			WalletID:      d.WalletID,
+			Month:         month,
+			Amount:        d.Amount,
+			LedgerEntryID: credit.ID,
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/interest"
+	"project/internal/ledger"
+	"project/internal/storage/mocks"
+)
+
+func interestJob(ctrl *gomock.Controller, treasury uuid.UUID) (*InterestJob, *mocks.MockWalletRepo) {
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+	passThroughReadOnly(tm)
+	passThroughReadCommitted(tm)
+	return NewInterestJob(tm, repo, treasury, time.Hour, 0, 2), repo
+}
+
+func TestInterestJob_AccrueDayPagesAndRecordsRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j, repo := interestJob(ctrl, uuid.New())
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(day.AddDate(0, 0, -3), day.AddDate(0, 0, -1), nil)
+	gomock.InOrder(
+		repo.EXPECT().ListAccrualInputs(gomock.Any(), uuid.Nil, day, 2).Return([]interest.Input{
+			{WalletID: a, Balance: 3650000, RateBps: 500},
+			{WalletID: b, Balance: 100, RateBps: 500, Carry: 7},
+		}, nil),
+		repo.EXPECT().InsertInterestAccruals(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, accruals []interest.Accrual) (int, error) {
+			require.Equal(t, []interest.Accrual{
+				{WalletID: a, Day: day, Balance: 3650000, RateBps: 500, Amount: 500},
+				{WalletID: b, Day: day, Balance: 100, RateBps: 500, Remainder: 50007},
+			}, accruals)
+			return 1, nil
+		}),
+		repo.EXPECT().ListAccrualInputs(gomock.Any(), b, day, 2).Return([]interest.Input{{WalletID: c}}, nil),
+		repo.EXPECT().InsertInterestAccruals(gomock.Any(), gomock.Len(1)).Return(1, nil),
+		repo.EXPECT().ListAccrualInputs(gomock.Any(), c, day, 2).Return(nil, nil),
+		repo.EXPECT().InsertInterestAccruals(gomock.Any(), gomock.Len(0)).Return(0, nil),
+		repo.EXPECT().InsertInterestRun(gomock.Any(), interest.AccrualJob, day, 3).Return(nil),
+	)
+
+	written, err := j.AccrueDay(context.Background(), day.Add(5*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+}
+
+func TestInterestJob_AccrueDayOutOfOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j, repo := interestJob(ctrl, uuid.New())
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).
+		Return(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC), nil)
+
+	_, err := j.AccrueDay(context.Background(), time.Date(2025, 11, 5, 0, 0, 0, 0, time.UTC))
+	require.EqualError(t, err, "accrue 2025-11-03 first")
+}
+
+func TestInterestJob_PostMonthMovesInterestFromTreasury(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	treasury, a, b := uuid.New(), uuid.New(), uuid.New()
+	j, repo := interestJob(ctrl, treasury)
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(month, interest.LastDay(month), nil)
+	gomock.InOrder(
+		repo.EXPECT().ListInterestDue(gomock.Any(), month, uuid.Nil, 2).
+			Return([]interest.Due{{WalletID: a, Amount: 40}, {WalletID: b, Amount: 5}}, nil),
+
+		repo.EXPECT().LockActive(gomock.Any(), treasury).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), treasury).Return(int64(1000), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), treasury, int64(40)).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), treasury, int64(-40)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), treasury, ledger.Interest, int64(-40)).
+			Return(ledger.Entry{ID: 1, WalletID: treasury, Amount: -40, Balance: 960}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), a).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), a, int64(40)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), a, ledger.Interest, int64(40)).
+			Return(ledger.Entry{ID: 2, WalletID: a, Amount: 40, Balance: 140}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().InsertInterestPosting(gomock.Any(), interest.Posting{WalletID: a, Month: month, Amount: 40, LedgerEntryID: 2}).
+			Return(nil),
+
+		repo.EXPECT().LockActive(gomock.Any(), treasury).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), treasury).Return(int64(960), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), treasury, int64(5)).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), treasury, int64(-5)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), treasury, ledger.Interest, int64(-5)).
+			Return(ledger.Entry{ID: 3, WalletID: treasury, Amount: -5, Balance: 955}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), b).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), b, int64(5)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), b, ledger.Interest, int64(5)).
+			Return(ledger.Entry{ID: 4, WalletID: b, Amount: 5, Balance: 5}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().InsertInterestPosting(gomock.Any(), gomock.Any()).Return(errors.New("interest already posted")),
+
+		repo.EXPECT().ListInterestDue(gomock.Any(), month, b, 2).Return(nil, nil),
+		repo.EXPECT().InsertInterestRun(gomock.Any(), interest.PostingJob, month, 1).Return(nil),
+	)
+
+	posted, err := j.PostMonth(context.Background(), month)
+	require.NoError(t, err)
+	require.Equal(t, 1, posted)
+}
+
+func TestInterestJob_PostMonthNotFullyAccrued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j, repo := interestJob(ctrl, uuid.New())
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(month, month.AddDate(0, 0, 28), nil)
+
+	_, err := j.PostMonth(context.Background(), month)
+	require.EqualError(t, err, "2025-11 is not fully accrued")
+}
+
+func TestInterestJob_TreasuryShort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	treasury := uuid.New()
+	j, repo := interestJob(ctrl, treasury)
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(month, interest.LastDay(month), nil)
+	repo.EXPECT().ListInterestDue(gomock.Any(), month, uuid.Nil, 2).Return([]interest.Due{{WalletID: uuid.New(), Amount: 40}}, nil)
+	repo.EXPECT().LockActive(gomock.Any(), treasury).Return(nil)
+	repo.EXPECT().GetById(gomock.Any(), treasury).Return(int64(30), nil)
+
+	_, err := j.PostMonth(context.Background(), month)
+	require.EqualError(t, err, "treasury: not enough balance: 30 < 40")
+}
+
+func TestInterestJob_CatchUpPostsClosedMonths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j, repo := interestJob(ctrl, uuid.New())
+	oct31 := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+	nov1 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 11, 2, 3, 0, 0, 0, time.UTC)
+
+	gomock.InOrder(
+		repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(start, oct31, nil),
+		repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(start, oct31, nil),
+		repo.EXPECT().ListAccrualInputs(gomock.Any(), uuid.Nil, nov1, 2).Return(nil, nil),
+		repo.EXPECT().InsertInterestAccruals(gomock.Any(), gomock.Len(0)).Return(0, nil),
+		repo.EXPECT().InsertInterestRun(gomock.Any(), interest.AccrualJob, nov1, 0).Return(nil),
+		repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(start, nov1, nil),
+		repo.EXPECT().InterestRunRange(gomock.Any(), interest.PostingJob).Return(time.Time{}, time.Time{}, nil),
+		repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(start, nov1, nil),
+		repo.EXPECT().ListInterestDue(gomock.Any(), time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), uuid.Nil, 2).Return(nil, nil),
+		repo.EXPECT().InsertInterestRun(gomock.Any(), interest.PostingJob, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), 0).Return(nil),
+	)
+
+	require.NoError(t, j.CatchUp(context.Background(), now))
+}
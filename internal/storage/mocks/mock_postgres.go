@@ -49,18 +49,46 @@ func (mr *MockTransactionManagerMockRecorder) GetQueryEngine(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueryEngine", reflect.TypeOf((*MockTransactionManager)(nil).GetQueryEngine), arg0)
 }
 
-// RunReadUncommitted mocks base method.
-func (m *MockTransactionManager) RunReadUncommitted(arg0 context.Context, arg1 func(context.Context) error) error {
+// RunReadCommitted mocks base method.
+func (m *MockTransactionManager) RunReadCommitted(arg0 context.Context, arg1 func(context.Context) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunReadUncommitted", arg0, arg1)
+	ret := m.ctrl.Call(m, "RunReadCommitted", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RunReadUncommitted indicates an expected call of RunReadUncommitted.
-func (mr *MockTransactionManagerMockRecorder) RunReadUncommitted(arg0, arg1 interface{}) *gomock.Call {
+// RunReadCommitted indicates an expected call of RunReadCommitted.
+func (mr *MockTransactionManagerMockRecorder) RunReadCommitted(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunReadUncommitted", reflect.TypeOf((*MockTransactionManager)(nil).RunReadUncommitted), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunReadCommitted", reflect.TypeOf((*MockTransactionManager)(nil).RunReadCommitted), arg0, arg1)
+}
+
+// RunReadOnly mocks base method.
+func (m *MockTransactionManager) RunReadOnly(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunReadOnly", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunReadOnly indicates an expected call of RunReadOnly.
+func (mr *MockTransactionManagerMockRecorder) RunReadOnly(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunReadOnly", reflect.TypeOf((*MockTransactionManager)(nil).RunReadOnly), arg0, arg1)
+}
+
+// RunReadOnlyDeferrable mocks base method.
+func (m *MockTransactionManager) RunReadOnlyDeferrable(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunReadOnlyDeferrable", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunReadOnlyDeferrable indicates an expected call of RunReadOnlyDeferrable.
+func (mr *MockTransactionManagerMockRecorder) RunReadOnlyDeferrable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunReadOnlyDeferrable", reflect.TypeOf((*MockTransactionManager)(nil).RunReadOnlyDeferrable), arg0, arg1)
 }
 
 // RunSerializable mocks base method.
@@ -6,7 +6,14 @@ package mocks
 
 import (
 	context "context"
+	batch "project/internal/batch"
+	interest "project/internal/interest"
+	ledger "project/internal/ledger"
+	outbox "project/internal/outbox"
+	statement "project/internal/statement"
+	wallet "project/internal/wallet"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -35,61 +42,769 @@ func (m *MockWalletRepo) EXPECT() *MockWalletRepoMockRecorder {
 	return m.recorder
 }
 
+// CompactShards mocks base method.
+func (m *MockWalletRepo) CompactShards(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompactShards", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompactShards indicates an expected call of CompactShards.
+func (mr *MockWalletRepoMockRecorder) CompactShards(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactShards", reflect.TypeOf((*MockWalletRepo)(nil).CompactShards), arg0, arg1)
+}
+
+// DepositToShard mocks base method.
+func (m *MockWalletRepo) DepositToShard(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositToShard", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositToShard indicates an expected call of DepositToShard.
+func (mr *MockWalletRepoMockRecorder) DepositToShard(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositToShard", reflect.TypeOf((*MockWalletRepo)(nil).DepositToShard), arg0, arg1, arg2, arg3)
+}
+
+// EnsureMainBalance mocks base method.
+func (m *MockWalletRepo) EnsureMainBalance(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureMainBalance", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureMainBalance indicates an expected call of EnsureMainBalance.
+func (mr *MockWalletRepoMockRecorder) EnsureMainBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureMainBalance", reflect.TypeOf((*MockWalletRepo)(nil).EnsureMainBalance), arg0, arg1, arg2)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockWalletRepo) GetBalanceAt(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockWalletRepoMockRecorder) GetBalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockWalletRepo)(nil).GetBalanceAt), arg0, arg1, arg2)
+}
+
+// GetBalanceVersion mocks base method.
+func (m *MockWalletRepo) GetBalanceVersion(arg0 context.Context, arg1 uuid.UUID) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceVersion", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBalanceVersion indicates an expected call of GetBalanceVersion.
+func (mr *MockWalletRepoMockRecorder) GetBalanceVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceVersion", reflect.TypeOf((*MockWalletRepo)(nil).GetBalanceVersion), arg0, arg1)
+}
+
+// GetBalancesAt mocks base method.
+func (m *MockWalletRepo) GetBalancesAt(arg0 context.Context, arg1 []uuid.UUID, arg2 time.Time) ([]ledger.PointBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancesAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]ledger.PointBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancesAt indicates an expected call of GetBalancesAt.
+func (mr *MockWalletRepoMockRecorder) GetBalancesAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancesAt", reflect.TypeOf((*MockWalletRepo)(nil).GetBalancesAt), arg0, arg1, arg2)
+}
+
+// GetBatch mocks base method.
+func (m *MockWalletRepo) GetBatch(arg0 context.Context, arg1 uuid.UUID) (batch.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", arg0, arg1)
+	ret0, _ := ret[0].(batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockWalletRepoMockRecorder) GetBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockWalletRepo)(nil).GetBatch), arg0, arg1)
+}
+
 // GetById mocks base method.
 func (m *MockWalletRepo) GetById(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetById", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockWalletRepoMockRecorder) GetById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockWalletRepo)(nil).GetById), arg0, arg1)
+}
+
+// GetLedgerEntryForUpdate mocks base method.
+func (m *MockWalletRepo) GetLedgerEntryForUpdate(arg0 context.Context, arg1 int64) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerEntryForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerEntryForUpdate indicates an expected call of GetLedgerEntryForUpdate.
+func (mr *MockWalletRepoMockRecorder) GetLedgerEntryForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerEntryForUpdate", reflect.TypeOf((*MockWalletRepo)(nil).GetLedgerEntryForUpdate), arg0, arg1)
+}
+
+// GetOpeningBalance mocks base method.
+func (m *MockWalletRepo) GetOpeningBalance(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpeningBalance", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpeningBalance indicates an expected call of GetOpeningBalance.
+func (mr *MockWalletRepoMockRecorder) GetOpeningBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpeningBalance", reflect.TypeOf((*MockWalletRepo)(nil).GetOpeningBalance), arg0, arg1, arg2)
+}
+
+// GetShardCount mocks base method.
+func (m *MockWalletRepo) GetShardCount(arg0 context.Context, arg1 uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShardCount", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShardCount indicates an expected call of GetShardCount.
+func (mr *MockWalletRepoMockRecorder) GetShardCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardCount", reflect.TypeOf((*MockWalletRepo)(nil).GetShardCount), arg0, arg1)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletRepo) GetWallet(arg0 context.Context, arg1 uuid.UUID) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", arg0, arg1)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletRepoMockRecorder) GetWallet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletRepo)(nil).GetWallet), arg0, arg1)
+}
+
+// GetWalletByExternalRef mocks base method.
+func (m *MockWalletRepo) GetWalletByExternalRef(arg0 context.Context, arg1, arg2 string) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByExternalRef", arg0, arg1, arg2)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByExternalRef indicates an expected call of GetWalletByExternalRef.
+func (mr *MockWalletRepoMockRecorder) GetWalletByExternalRef(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByExternalRef", reflect.TypeOf((*MockWalletRepo)(nil).GetWalletByExternalRef), arg0, arg1, arg2)
+}
+
+// HasOpenPayouts mocks base method.
+func (m *MockWalletRepo) HasOpenPayouts(arg0 context.Context, arg1 uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasOpenPayouts", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasOpenPayouts indicates an expected call of HasOpenPayouts.
+func (mr *MockWalletRepoMockRecorder) HasOpenPayouts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasOpenPayouts", reflect.TypeOf((*MockWalletRepo)(nil).HasOpenPayouts), arg0, arg1)
+}
+
+// InsertBalanceCheckpoints mocks base method.
+func (m *MockWalletRepo) InsertBalanceCheckpoints(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 int) (uuid.UUID, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBalanceCheckpoints", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InsertBalanceCheckpoints indicates an expected call of InsertBalanceCheckpoints.
+func (mr *MockWalletRepoMockRecorder) InsertBalanceCheckpoints(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBalanceCheckpoints", reflect.TypeOf((*MockWalletRepo)(nil).InsertBalanceCheckpoints), arg0, arg1, arg2, arg3)
+}
+
+// InsertBatch mocks base method.
+func (m *MockWalletRepo) InsertBatch(arg0 context.Context, arg1 batch.Batch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBatch indicates an expected call of InsertBatch.
+func (mr *MockWalletRepoMockRecorder) InsertBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBatch", reflect.TypeOf((*MockWalletRepo)(nil).InsertBatch), arg0, arg1)
+}
+
+// InsertBatchItem mocks base method.
+func (m *MockWalletRepo) InsertBatchItem(arg0 context.Context, arg1 uuid.UUID, arg2 batch.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBatchItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBatchItem indicates an expected call of InsertBatchItem.
+func (mr *MockWalletRepoMockRecorder) InsertBatchItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBatchItem", reflect.TypeOf((*MockWalletRepo)(nil).InsertBatchItem), arg0, arg1, arg2)
+}
+
+// InsertDailySnapshots mocks base method.
+func (m *MockWalletRepo) InsertDailySnapshots(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 int) (uuid.UUID, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDailySnapshots", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InsertDailySnapshots indicates an expected call of InsertDailySnapshots.
+func (mr *MockWalletRepoMockRecorder) InsertDailySnapshots(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDailySnapshots", reflect.TypeOf((*MockWalletRepo)(nil).InsertDailySnapshots), arg0, arg1, arg2, arg3)
+}
+
+// InsertInterestAccruals mocks base method.
+func (m *MockWalletRepo) InsertInterestAccruals(arg0 context.Context, arg1 []interest.Accrual) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertInterestAccruals indicates an expected call of InsertInterestAccruals.
+func (mr *MockWalletRepoMockRecorder) InsertInterestAccruals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInterestAccruals", reflect.TypeOf((*MockWalletRepo)(nil).InsertInterestAccruals), arg0, arg1)
+}
+
+// InsertInterestPosting mocks base method.
+func (m *MockWalletRepo) InsertInterestPosting(arg0 context.Context, arg1 interest.Posting) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInterestPosting", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInterestPosting indicates an expected call of InsertInterestPosting.
+func (mr *MockWalletRepoMockRecorder) InsertInterestPosting(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInterestPosting", reflect.TypeOf((*MockWalletRepo)(nil).InsertInterestPosting), arg0, arg1)
+}
+
+// InsertInterestRun mocks base method.
+func (m *MockWalletRepo) InsertInterestRun(arg0 context.Context, arg1 interest.Job, arg2 time.Time, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInterestRun", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInterestRun indicates an expected call of InsertInterestRun.
+func (mr *MockWalletRepoMockRecorder) InsertInterestRun(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInterestRun", reflect.TypeOf((*MockWalletRepo)(nil).InsertInterestRun), arg0, arg1, arg2, arg3)
+}
+
+// InsertLedgerEntries mocks base method.
+func (m *MockWalletRepo) InsertLedgerEntries(arg0 context.Context, arg1 uuid.UUID, arg2 ledger.OperationType, arg3 []int64) ([]ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLedgerEntries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLedgerEntries indicates an expected call of InsertLedgerEntries.
+func (mr *MockWalletRepoMockRecorder) InsertLedgerEntries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLedgerEntries", reflect.TypeOf((*MockWalletRepo)(nil).InsertLedgerEntries), arg0, arg1, arg2, arg3)
+}
+
+// InsertLedgerEntry mocks base method.
+func (m *MockWalletRepo) InsertLedgerEntry(arg0 context.Context, arg1 uuid.UUID, arg2 ledger.OperationType, arg3 int64) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLedgerEntry", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLedgerEntry indicates an expected call of InsertLedgerEntry.
+func (mr *MockWalletRepoMockRecorder) InsertLedgerEntry(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLedgerEntry", reflect.TypeOf((*MockWalletRepo)(nil).InsertLedgerEntry), arg0, arg1, arg2, arg3)
+}
+
+// InsertOutboxEvent mocks base method.
+func (m *MockWalletRepo) InsertOutboxEvent(arg0 context.Context, arg1 outbox.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertOutboxEvent indicates an expected call of InsertOutboxEvent.
+func (mr *MockWalletRepoMockRecorder) InsertOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOutboxEvent", reflect.TypeOf((*MockWalletRepo)(nil).InsertOutboxEvent), arg0, arg1)
+}
+
+// InsertRefEntry mocks base method.
+func (m *MockWalletRepo) InsertRefEntry(arg0 context.Context, arg1 uuid.UUID, arg2 ledger.OperationType, arg3 int64, arg4 string) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRefEntry", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRefEntry indicates an expected call of InsertRefEntry.
+func (mr *MockWalletRepoMockRecorder) InsertRefEntry(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRefEntry", reflect.TypeOf((*MockWalletRepo)(nil).InsertRefEntry), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InsertReversalEntry mocks base method.
+func (m *MockWalletRepo) InsertReversalEntry(arg0 context.Context, arg1 ledger.Entry, arg2 int64) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReversalEntry", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReversalEntry indicates an expected call of InsertReversalEntry.
+func (mr *MockWalletRepoMockRecorder) InsertReversalEntry(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReversalEntry", reflect.TypeOf((*MockWalletRepo)(nil).InsertReversalEntry), arg0, arg1, arg2)
+}
+
+// InsertShardEntry mocks base method.
+func (m *MockWalletRepo) InsertShardEntry(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 ledger.OperationType, arg4 int64) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertShardEntry", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertShardEntry indicates an expected call of InsertShardEntry.
+func (mr *MockWalletRepoMockRecorder) InsertShardEntry(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertShardEntry", reflect.TypeOf((*MockWalletRepo)(nil).InsertShardEntry), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InsertWallet mocks base method.
+func (m *MockWalletRepo) InsertWallet(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWallet", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWallet indicates an expected call of InsertWallet.
+func (mr *MockWalletRepoMockRecorder) InsertWallet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWallet", reflect.TypeOf((*MockWalletRepo)(nil).InsertWallet), arg0, arg1)
+}
+
+// InsertWalletWithProfile mocks base method.
+func (m *MockWalletRepo) InsertWalletWithProfile(arg0 context.Context, arg1 wallet.Wallet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWalletWithProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWalletWithProfile indicates an expected call of InsertWalletWithProfile.
+func (mr *MockWalletRepoMockRecorder) InsertWalletWithProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWalletWithProfile", reflect.TypeOf((*MockWalletRepo)(nil).InsertWalletWithProfile), arg0, arg1)
+}
+
+// InterestRunRange mocks base method.
+func (m *MockWalletRepo) InterestRunRange(arg0 context.Context, arg1 interest.Job) (time.Time, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InterestRunRange", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InterestRunRange indicates an expected call of InterestRunRange.
+func (mr *MockWalletRepoMockRecorder) InterestRunRange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InterestRunRange", reflect.TypeOf((*MockWalletRepo)(nil).InterestRunRange), arg0, arg1)
+}
+
+// LatestSnapshotDay mocks base method.
+func (m *MockWalletRepo) LatestSnapshotDay(arg0 context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestSnapshotDay", arg0)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestSnapshotDay indicates an expected call of LatestSnapshotDay.
+func (mr *MockWalletRepoMockRecorder) LatestSnapshotDay(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestSnapshotDay", reflect.TypeOf((*MockWalletRepo)(nil).LatestSnapshotDay), arg0)
+}
+
+// LedgerHead mocks base method.
+func (m *MockWalletRepo) LedgerHead(arg0 context.Context, arg1 uuid.UUID) (ledger.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LedgerHead", arg0, arg1)
+	ret0, _ := ret[0].(ledger.Cursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LedgerHead indicates an expected call of LedgerHead.
+func (mr *MockWalletRepoMockRecorder) LedgerHead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LedgerHead", reflect.TypeOf((*MockWalletRepo)(nil).LedgerHead), arg0, arg1)
+}
+
+// ListAccrualInputs mocks base method.
+func (m *MockWalletRepo) ListAccrualInputs(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 int) ([]interest.Input, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccrualInputs", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]interest.Input)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccrualInputs indicates an expected call of ListAccrualInputs.
+func (mr *MockWalletRepoMockRecorder) ListAccrualInputs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccrualInputs", reflect.TypeOf((*MockWalletRepo)(nil).ListAccrualInputs), arg0, arg1, arg2, arg3)
+}
+
+// ListEntriesBetween mocks base method.
+func (m *MockWalletRepo) ListEntriesBetween(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 time.Time, arg4 int) ([]ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntriesBetween", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntriesBetween indicates an expected call of ListEntriesBetween.
+func (mr *MockWalletRepoMockRecorder) ListEntriesBetween(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesBetween", reflect.TypeOf((*MockWalletRepo)(nil).ListEntriesBetween), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListInterestDue mocks base method.
+func (m *MockWalletRepo) ListInterestDue(arg0 context.Context, arg1 time.Time, arg2 uuid.UUID, arg3 int) ([]interest.Due, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestDue", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]interest.Due)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestDue indicates an expected call of ListInterestDue.
+func (mr *MockWalletRepoMockRecorder) ListInterestDue(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestDue", reflect.TypeOf((*MockWalletRepo)(nil).ListInterestDue), arg0, arg1, arg2, arg3)
+}
+
+// ListLedgerEntries mocks base method.
+func (m *MockWalletRepo) ListLedgerEntries(arg0 context.Context, arg1 uuid.UUID, arg2 ledger.Cursor, arg3 int) ([]ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLedgerEntries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLedgerEntries indicates an expected call of ListLedgerEntries.
+func (mr *MockWalletRepoMockRecorder) ListLedgerEntries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLedgerEntries", reflect.TypeOf((*MockWalletRepo)(nil).ListLedgerEntries), arg0, arg1, arg2, arg3)
+}
+
+// ListShardedWallets mocks base method.
+func (m *MockWalletRepo) ListShardedWallets(arg0 context.Context, arg1 uuid.UUID, arg2 int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShardedWallets", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShardedWallets indicates an expected call of ListShardedWallets.
+func (mr *MockWalletRepoMockRecorder) ListShardedWallets(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShardedWallets", reflect.TypeOf((*MockWalletRepo)(nil).ListShardedWallets), arg0, arg1, arg2)
+}
+
+// ListWallets mocks base method.
+func (m *MockWalletRepo) ListWallets(arg0 context.Context, arg1 wallet.Query) ([]wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", arg0, arg1)
+	ret0, _ := ret[0].([]wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockWalletRepoMockRecorder) ListWallets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockWalletRepo)(nil).ListWallets), arg0, arg1)
+}
+
+// ListWalletsByOwner mocks base method.
+func (m *MockWalletRepo) ListWalletsByOwner(arg0 context.Context, arg1 string, arg2 uuid.UUID, arg3 int) ([]wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletsByOwner", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletsByOwner indicates an expected call of ListWalletsByOwner.
+func (mr *MockWalletRepoMockRecorder) ListWalletsByOwner(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletsByOwner", reflect.TypeOf((*MockWalletRepo)(nil).ListWalletsByOwner), arg0, arg1, arg2, arg3)
+}
+
+// LockActive mocks base method.
+func (m *MockWalletRepo) LockActive(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockActive", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockActive indicates an expected call of LockActive.
+func (mr *MockWalletRepoMockRecorder) LockActive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockActive", reflect.TypeOf((*MockWalletRepo)(nil).LockActive), arg0, arg1)
+}
+
+// LockBalance mocks base method.
+func (m *MockWalletRepo) LockBalance(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBalance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockBalance indicates an expected call of LockBalance.
+func (mr *MockWalletRepoMockRecorder) LockBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBalance", reflect.TypeOf((*MockWalletRepo)(nil).LockBalance), arg0, arg1)
+}
+
+// LockOpen mocks base method.
+func (m *MockWalletRepo) LockOpen(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOpen", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockOpen indicates an expected call of LockOpen.
+func (mr *MockWalletRepoMockRecorder) LockOpen(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOpen", reflect.TypeOf((*MockWalletRepo)(nil).LockOpen), arg0, arg1)
+}
+
+// OverdrawBalance mocks base method.
+func (m *MockWalletRepo) OverdrawBalance(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OverdrawBalance", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OverdrawBalance indicates an expected call of OverdrawBalance.
+func (mr *MockWalletRepoMockRecorder) OverdrawBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OverdrawBalance", reflect.TypeOf((*MockWalletRepo)(nil).OverdrawBalance), arg0, arg1, arg2)
+}
+
+// ResizeShards mocks base method.
+func (m *MockWalletRepo) ResizeShards(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResizeShards", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResizeShards indicates an expected call of ResizeShards.
+func (mr *MockWalletRepoMockRecorder) ResizeShards(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizeShards", reflect.TypeOf((*MockWalletRepo)(nil).ResizeShards), arg0, arg1, arg2)
+}
+
+// ReversedAmount mocks base method.
+func (m *MockWalletRepo) ReversedAmount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReversedAmount", arg0, arg1)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetById indicates an expected call of GetById.
-func (mr *MockWalletRepoMockRecorder) GetById(arg0, arg1 interface{}) *gomock.Call {
+// ReversedAmount indicates an expected call of ReversedAmount.
+func (mr *MockWalletRepoMockRecorder) ReversedAmount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockWalletRepo)(nil).GetById), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReversedAmount", reflect.TypeOf((*MockWalletRepo)(nil).ReversedAmount), arg0, arg1)
 }
 
-// InsertWallet mocks base method.
-func (m *MockWalletRepo) InsertWallet(arg0 context.Context, arg1 uuid.UUID) error {
+// UpdateBalance mocks base method.
+func (m *MockWalletRepo) UpdateBalance(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertWallet", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateBalance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// InsertWallet indicates an expected call of InsertWallet.
-func (mr *MockWalletRepoMockRecorder) InsertWallet(arg0, arg1 interface{}) *gomock.Call {
+// UpdateBalance indicates an expected call of UpdateBalance.
+func (mr *MockWalletRepoMockRecorder) UpdateBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWallet", reflect.TypeOf((*MockWalletRepo)(nil).InsertWallet), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWalletRepo)(nil).UpdateBalance), arg0, arg1, arg2)
 }
 
-// LockBalance mocks base method.
-func (m *MockWalletRepo) LockBalance(arg0 context.Context, arg1 uuid.UUID) error {
+// UpdateBalanceIfVersion mocks base method.
+func (m *MockWalletRepo) UpdateBalanceIfVersion(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LockBalance", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateBalanceIfVersion", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// LockBalance indicates an expected call of LockBalance.
-func (mr *MockWalletRepoMockRecorder) LockBalance(arg0, arg1 interface{}) *gomock.Call {
+// UpdateBalanceIfVersion indicates an expected call of UpdateBalanceIfVersion.
+func (mr *MockWalletRepoMockRecorder) UpdateBalanceIfVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBalance", reflect.TypeOf((*MockWalletRepo)(nil).LockBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceIfVersion", reflect.TypeOf((*MockWalletRepo)(nil).UpdateBalanceIfVersion), arg0, arg1, arg2, arg3)
 }
 
-// UpdateBalance mocks base method.
-func (m *MockWalletRepo) UpdateBalance(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+// UpdateBatchStatus mocks base method.
+func (m *MockWalletRepo) UpdateBatchStatus(arg0 context.Context, arg1 uuid.UUID, arg2 batch.Status) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateBatchStatus", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateBalance indicates an expected call of UpdateBalance.
-func (mr *MockWalletRepoMockRecorder) UpdateBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+// UpdateBatchStatus indicates an expected call of UpdateBatchStatus.
+func (mr *MockWalletRepoMockRecorder) UpdateBatchStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWalletRepo)(nil).UpdateBalance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBatchStatus", reflect.TypeOf((*MockWalletRepo)(nil).UpdateBatchStatus), arg0, arg1, arg2)
+}
+
+// UpdateInterestRate mocks base method.
+func (m *MockWalletRepo) UpdateInterestRate(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInterestRate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInterestRate indicates an expected call of UpdateInterestRate.
+func (mr *MockWalletRepoMockRecorder) UpdateInterestRate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInterestRate", reflect.TypeOf((*MockWalletRepo)(nil).UpdateInterestRate), arg0, arg1, arg2)
+}
+
+// UpdateWalletProfile mocks base method.
+func (m *MockWalletRepo) UpdateWalletProfile(arg0 context.Context, arg1 uuid.UUID, arg2 wallet.ProfileUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletProfile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletProfile indicates an expected call of UpdateWalletProfile.
+func (mr *MockWalletRepoMockRecorder) UpdateWalletProfile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletProfile", reflect.TypeOf((*MockWalletRepo)(nil).UpdateWalletProfile), arg0, arg1, arg2)
+}
+
+// UpdateWalletStatus mocks base method.
+func (m *MockWalletRepo) UpdateWalletStatus(arg0 context.Context, arg1 uuid.UUID, arg2 wallet.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletStatus indicates an expected call of UpdateWalletStatus.
+func (mr *MockWalletRepoMockRecorder) UpdateWalletStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletStatus", reflect.TypeOf((*MockWalletRepo)(nil).UpdateWalletStatus), arg0, arg1, arg2)
+}
+
+// WithdrawnSince mocks base method.
+func (m *MockWalletRepo) WithdrawnSince(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawnSince", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawnSince indicates an expected call of WithdrawnSince.
+func (mr *MockWalletRepoMockRecorder) WithdrawnSince(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawnSince", reflect.TypeOf((*MockWalletRepo)(nil).WithdrawnSince), arg0, arg1, arg2)
 }
 
 // MockFacade is a mock of Facade interface.
@@ -115,6 +830,21 @@ func (m *MockFacade) EXPECT() *MockFacadeMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockFacade) ApplyBatch(arg0 context.Context, arg1 batch.Mode, arg2 []batch.Operation) (batch.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockFacadeMockRecorder) ApplyBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockFacade)(nil).ApplyBatch), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockFacade) Create(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -129,6 +859,20 @@ func (mr *MockFacadeMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFacade)(nil).Create), arg0, arg1)
 }
 
+// CreateWithProfile mocks base method.
+func (m *MockFacade) CreateWithProfile(arg0 context.Context, arg1 wallet.Wallet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithProfile indicates an expected call of CreateWithProfile.
+func (mr *MockFacadeMockRecorder) CreateWithProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithProfile", reflect.TypeOf((*MockFacade)(nil).CreateWithProfile), arg0, arg1)
+}
+
 // Deposit mocks base method.
 func (m *MockFacade) Deposit(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
 	m.ctrl.T.Helper()
@@ -143,6 +887,81 @@ func (mr *MockFacadeMockRecorder) Deposit(arg0, arg1, arg2 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockFacade)(nil).Deposit), arg0, arg1, arg2)
 }
 
+// DepositIfVersion mocks base method.
+func (m *MockFacade) DepositIfVersion(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositIfVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DepositIfVersion indicates an expected call of DepositIfVersion.
+func (mr *MockFacadeMockRecorder) DepositIfVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositIfVersion", reflect.TypeOf((*MockFacade)(nil).DepositIfVersion), arg0, arg1, arg2, arg3)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockFacade) GetBalanceAt(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockFacadeMockRecorder) GetBalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockFacade)(nil).GetBalanceAt), arg0, arg1, arg2)
+}
+
+// GetBalanceVersion mocks base method.
+func (m *MockFacade) GetBalanceVersion(arg0 context.Context, arg1 uuid.UUID) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceVersion", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBalanceVersion indicates an expected call of GetBalanceVersion.
+func (mr *MockFacadeMockRecorder) GetBalanceVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceVersion", reflect.TypeOf((*MockFacade)(nil).GetBalanceVersion), arg0, arg1)
+}
+
+// GetBalancesAt mocks base method.
+func (m *MockFacade) GetBalancesAt(arg0 context.Context, arg1 []uuid.UUID, arg2 time.Time) ([]ledger.PointBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancesAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]ledger.PointBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancesAt indicates an expected call of GetBalancesAt.
+func (mr *MockFacadeMockRecorder) GetBalancesAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancesAt", reflect.TypeOf((*MockFacade)(nil).GetBalancesAt), arg0, arg1, arg2)
+}
+
+// GetBatch mocks base method.
+func (m *MockFacade) GetBatch(arg0 context.Context, arg1 uuid.UUID) (batch.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", arg0, arg1)
+	ret0, _ := ret[0].(batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockFacadeMockRecorder) GetBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockFacade)(nil).GetBatch), arg0, arg1)
+}
+
 // GetByID mocks base method.
 func (m *MockFacade) GetByID(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
@@ -158,16 +977,265 @@ func (mr *MockFacadeMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFacade)(nil).GetByID), arg0, arg1)
 }
 
+// GetLedger mocks base method.
+func (m *MockFacade) GetLedger(arg0 context.Context, arg1 uuid.UUID, arg2 ledger.Cursor, arg3 int) ([]ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedger", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedger indicates an expected call of GetLedger.
+func (mr *MockFacadeMockRecorder) GetLedger(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedger", reflect.TypeOf((*MockFacade)(nil).GetLedger), arg0, arg1, arg2, arg3)
+}
+
+// GetLedgerHead mocks base method.
+func (m *MockFacade) GetLedgerHead(arg0 context.Context, arg1 uuid.UUID) (ledger.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerHead", arg0, arg1)
+	ret0, _ := ret[0].(ledger.Cursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerHead indicates an expected call of GetLedgerHead.
+func (mr *MockFacadeMockRecorder) GetLedgerHead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerHead", reflect.TypeOf((*MockFacade)(nil).GetLedgerHead), arg0, arg1)
+}
+
+// GetStatement mocks base method.
+func (m *MockFacade) GetStatement(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 time.Time, arg4 int) (statement.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(statement.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockFacadeMockRecorder) GetStatement(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockFacade)(nil).GetStatement), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetWallet mocks base method.
+func (m *MockFacade) GetWallet(arg0 context.Context, arg1 uuid.UUID) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", arg0, arg1)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockFacadeMockRecorder) GetWallet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockFacade)(nil).GetWallet), arg0, arg1)
+}
+
+// GetWalletByExternalRef mocks base method.
+func (m *MockFacade) GetWalletByExternalRef(arg0 context.Context, arg1, arg2 string) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByExternalRef", arg0, arg1, arg2)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByExternalRef indicates an expected call of GetWalletByExternalRef.
+func (mr *MockFacadeMockRecorder) GetWalletByExternalRef(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByExternalRef", reflect.TypeOf((*MockFacade)(nil).GetWalletByExternalRef), arg0, arg1, arg2)
+}
+
+// GetWithdrawnSince mocks base method.
+func (m *MockFacade) GetWithdrawnSince(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawnSince", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawnSince indicates an expected call of GetWithdrawnSince.
+func (mr *MockFacadeMockRecorder) GetWithdrawnSince(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawnSince", reflect.TypeOf((*MockFacade)(nil).GetWithdrawnSince), arg0, arg1, arg2)
+}
+
+// ListWallets mocks base method.
+func (m *MockFacade) ListWallets(arg0 context.Context, arg1 wallet.Query) ([]wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", arg0, arg1)
+	ret0, _ := ret[0].([]wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockFacadeMockRecorder) ListWallets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockFacade)(nil).ListWallets), arg0, arg1)
+}
+
+// ListWalletsByOwner mocks base method.
+func (m *MockFacade) ListWalletsByOwner(arg0 context.Context, arg1 string, arg2 uuid.UUID, arg3 int) ([]wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletsByOwner", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletsByOwner indicates an expected call of ListWalletsByOwner.
+func (mr *MockFacadeMockRecorder) ListWalletsByOwner(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletsByOwner", reflect.TypeOf((*MockFacade)(nil).ListWalletsByOwner), arg0, arg1, arg2, arg3)
+}
+
+// Refund mocks base method.
+func (m *MockFacade) Refund(arg0 context.Context, arg1 uuid.UUID, arg2 int64, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockFacadeMockRecorder) Refund(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockFacade)(nil).Refund), arg0, arg1, arg2, arg3)
+}
+
+// Reverse mocks base method.
+func (m *MockFacade) Reverse(arg0 context.Context, arg1, arg2 int64, arg3 bool) (ledger.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reverse", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(ledger.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reverse indicates an expected call of Reverse.
+func (mr *MockFacadeMockRecorder) Reverse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reverse", reflect.TypeOf((*MockFacade)(nil).Reverse), arg0, arg1, arg2, arg3)
+}
+
+// SetInterestRate mocks base method.
+func (m *MockFacade) SetInterestRate(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInterestRate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInterestRate indicates an expected call of SetInterestRate.
+func (mr *MockFacadeMockRecorder) SetInterestRate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInterestRate", reflect.TypeOf((*MockFacade)(nil).SetInterestRate), arg0, arg1, arg2)
+}
+
+// SetShardCount mocks base method.
+func (m *MockFacade) SetShardCount(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShardCount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShardCount indicates an expected call of SetShardCount.
+func (mr *MockFacadeMockRecorder) SetShardCount(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShardCount", reflect.TypeOf((*MockFacade)(nil).SetShardCount), arg0, arg1, arg2)
+}
+
+// SetStatus mocks base method.
+func (m *MockFacade) SetStatus(arg0 context.Context, arg1 uuid.UUID, arg2 wallet.Status) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockFacadeMockRecorder) SetStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockFacade)(nil).SetStatus), arg0, arg1, arg2)
+}
+
+// Transfer mocks base method.
+func (m *MockFacade) Transfer(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 int64, arg4 ledger.FeeCharge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockFacadeMockRecorder) Transfer(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockFacade)(nil).Transfer), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockFacade) UpdateProfile(arg0 context.Context, arg1 uuid.UUID, arg2 wallet.ProfileUpdate) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockFacadeMockRecorder) UpdateProfile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockFacade)(nil).UpdateProfile), arg0, arg1, arg2)
+}
+
 // Withdraw mocks base method.
-func (m *MockFacade) Withdraw(arg0 context.Context, arg1 uuid.UUID, arg2 int64) error {
+func (m *MockFacade) Withdraw(arg0 context.Context, arg1 uuid.UUID, arg2 int64, arg3 ledger.FeeCharge) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Withdraw", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Withdraw", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Withdraw indicates an expected call of Withdraw.
-func (mr *MockFacadeMockRecorder) Withdraw(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockFacadeMockRecorder) Withdraw(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockFacade)(nil).Withdraw), arg0, arg1, arg2, arg3)
+}
+
+// WithdrawIfVersion mocks base method.
+func (m *MockFacade) WithdrawIfVersion(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int64, arg4 ledger.FeeCharge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawIfVersion", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithdrawIfVersion indicates an expected call of WithdrawIfVersion.
+func (mr *MockFacadeMockRecorder) WithdrawIfVersion(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawIfVersion", reflect.TypeOf((*MockFacade)(nil).WithdrawIfVersion), arg0, arg1, arg2, arg3, arg4)
+}
+
+// WithdrawRef mocks base method.
+func (m *MockFacade) WithdrawRef(arg0 context.Context, arg1 uuid.UUID, arg2 int64, arg3 ledger.FeeCharge, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawRef", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithdrawRef indicates an expected call of WithdrawRef.
+func (mr *MockFacadeMockRecorder) WithdrawRef(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockFacade)(nil).Withdraw), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawRef", reflect.TypeOf((*MockFacade)(nil).WithdrawRef), arg0, arg1, arg2, arg3, arg4)
 }
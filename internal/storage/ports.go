@@ -2,6 +2,12 @@ package storage
 
 import (
 	"context"
+	"project/internal/batch"
+	"project/internal/interest"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/wallet"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,7 +17,55 @@ import (
 
 type WalletRepo interface {
 	LockBalance(ctx context.Context, walletId uuid.UUID) error
+	LockActive(ctx context.Context, walletId uuid.UUID) error
+	LockOpen(ctx context.Context, walletId uuid.UUID) error
 	UpdateBalance(ctx context.Context, walletId uuid.UUID, balanceDiff int64) error
 	GetById(ctx context.Context, walletId uuid.UUID) (int64, error)
+	GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error)
+	UpdateBalanceIfVersion(ctx context.Context, walletId uuid.UUID, balanceDiff int64, version int64) error
 	InsertWallet(ctx context.Context, walletId uuid.UUID) error
+	InsertWalletWithProfile(ctx context.Context, wl wallet.Wallet) error
+	GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error)
+	GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error)
+	ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error)
+	UpdateWalletProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) error
+	UpdateWalletStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) error
+	HasOpenPayouts(ctx context.Context, walletId uuid.UUID) (bool, error)
+	ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error)
+	InsertOutboxEvent(ctx context.Context, event outbox.Event) error
+	InsertLedgerEntry(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64) (ledger.Entry, error)
+	InsertShardEntry(ctx context.Context, walletId uuid.UUID, shard int, opType ledger.OperationType, amount int64) (ledger.Entry, error)
+	InsertRefEntry(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64, externalRef string) (ledger.Entry, error)
+	InsertLedgerEntries(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amounts []int64) ([]ledger.Entry, error)
+	GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error)
+	GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error)
+	InsertBalanceCheckpoints(ctx context.Context, afterId uuid.UUID, at time.Time, limit int) (uuid.UUID, int, error)
+	GetOpeningBalance(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error)
+	ListEntriesBetween(ctx context.Context, walletId uuid.UUID, from, to time.Time, limit int) ([]ledger.Entry, error)
+	LatestSnapshotDay(ctx context.Context) (time.Time, error)
+	InsertDailySnapshots(ctx context.Context, afterId uuid.UUID, day time.Time, limit int) (uuid.UUID, int, error)
+	ListLedgerEntries(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error)
+	LedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error)
+	InsertBatch(ctx context.Context, b batch.Batch) error
+	InsertBatchItem(ctx context.Context, batchId uuid.UUID, item batch.Item) error
+	UpdateBatchStatus(ctx context.Context, batchId uuid.UUID, status batch.Status) error
+	GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error)
+	GetShardCount(ctx context.Context, walletId uuid.UUID) (int, error)
+	DepositToShard(ctx context.Context, walletId uuid.UUID, shard int, amount int64) (bool, error)
+	EnsureMainBalance(ctx context.Context, walletId uuid.UUID, need int64) error
+	CompactShards(ctx context.Context, walletId uuid.UUID) (int64, error)
+	ResizeShards(ctx context.Context, walletId uuid.UUID, shards int) error
+	ListShardedWallets(ctx context.Context, afterId uuid.UUID, limit int) ([]uuid.UUID, error)
+	OverdrawBalance(ctx context.Context, walletId uuid.UUID, amount int64) error
+	GetLedgerEntryForUpdate(ctx context.Context, id int64) (ledger.Entry, error)
+	ReversedAmount(ctx context.Context, id int64) (int64, error)
+	InsertReversalEntry(ctx context.Context, original ledger.Entry, amount int64) (ledger.Entry, error)
+	WithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error)
+	UpdateInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error
+	ListAccrualInputs(ctx context.Context, afterId uuid.UUID, day time.Time, limit int) ([]interest.Input, error)
+	InsertInterestAccruals(ctx context.Context, accruals []interest.Accrual) (int, error)
+	InterestRunRange(ctx context.Context, job interest.Job) (time.Time, time.Time, error)
+	InsertInterestRun(ctx context.Context, job interest.Job, period time.Time, wallets int) error
+	ListInterestDue(ctx context.Context, month time.Time, afterId uuid.UUID, limit int) ([]interest.Due, error)
+	InsertInterestPosting(ctx context.Context, p interest.Posting) error
 }
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/bankstmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+const statementLineColumns = `id, entry_ref, account, booking_date, amount, currency, credit, reference, counterparty,
+	status, wallet_id, reason, note`
+
+func scanStatementLine(row pgx.Row) (bankstmt.Line, error) {
+	var (
+		line   bankstmt.Line
+		status string
+	)
+	err := row.Scan(&line.ID, &line.EntryRef, &line.Account, &line.BookingDate, &line.Amount, &line.Currency,
+		&line.Credit, &line.Reference, &line.Counterparty, &status, &line.WalletID, &line.Reason, &line.Note)
+	line.Status = bankstmt.LineStatus(status)
+	return line, err
+}
+
+func (r *PgRepository) InsertBankStatement(ctx context.Context, st bankstmt.Statement) (uuid.UUID, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO bank_statements (id, format, file_hash, line_count, imported_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (file_hash) DO UPDATE SET file_hash = EXCLUDED.file_hash
+		RETURNING id`
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, query, st.ID, string(st.Format), st.FileHash, len(st.Lines), st.ImportedAt).Scan(&id)
+	return id, err
+}
+
+// InsertStatementLine stores a line unless one with the same entry
+// reference exists already; the second result reports whether it did.
+func (r *PgRepository) InsertStatementLine(ctx context.Context, statementId uuid.UUID, line bankstmt.Line) (int64, bool, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO bank_statement_lines (statement_id, entry_ref, account, booking_date, amount, currency, credit,
+			reference, counterparty, status, wallet_id, reason, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (entry_ref) DO NOTHING
+		RETURNING id`
+
+	var id int64
+	err := tx.QueryRow(ctx, query, statementId, line.EntryRef, line.Account, line.BookingDate, line.Amount,
+		line.Currency, line.Credit, line.Reference, line.Counterparty, string(line.Status), line.WalletID,
+		line.Reason, line.Note).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+func (r *PgRepository) GetStatementLineForUpdate(ctx context.Context, id int64) (bankstmt.Line, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + statementLineColumns + " FROM bank_statement_lines WHERE id = $1 FOR UPDATE"
+	line, err := scanStatementLine(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return bankstmt.Line{}, errors.New("statement line not found")
+		}
+		return bankstmt.Line{}, err
+	}
+	return line, nil
+}
+
+func (r *PgRepository) UpdateStatementLine(ctx context.Context, id int64, status bankstmt.LineStatus, walletId *uuid.UUID, reason, note string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE bank_statement_lines
+		SET status = $2, wallet_id = $3, reason = $4, note = $5,
+			resolved_at = CASE WHEN $2 IN ('RESOLVED', 'IGNORED') THEN now() ELSE resolved_at END
+		WHERE id = $1`
+	tag, err := tx.Exec(ctx, query, id, string(status), walletId, reason, note)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("statement line not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) ListStatementExceptions(ctx context.Context, afterId int64, limit int) ([]bankstmt.Line, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + statementLineColumns + ` FROM bank_statement_lines
+		WHERE status = 'EXCEPTION' AND id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := tx.Query(ctx, query, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]bankstmt.Line, 0)
+	for rows.Next() {
+		line, err := scanStatementLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
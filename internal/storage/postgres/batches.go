@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/batch"
+	"project/internal/ledger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+func (r *PgRepository) InsertBatch(ctx context.Context, b batch.Batch) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "INSERT INTO batches (id, mode, status, item_count, created_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err := tx.Exec(ctx, query, b.ID, string(b.Mode), string(b.Status), len(b.Items), b.CreatedAt)
+	return err
+}
+
+func (r *PgRepository) InsertBatchItem(ctx context.Context, batchId uuid.UUID, item batch.Item) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO batch_items (batch_id, item_index, wallet_id, operation_type, amount, status, error, ledger_entry_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8::bigint, 0))
+		ON CONFLICT (batch_id, item_index) DO NOTHING`
+	_, err := tx.Exec(ctx, query, batchId, item.Index, item.WalletID, string(item.OperationType), item.Amount,
+		string(item.Status), item.Error, item.LedgerEntryID)
+	return err
+}
+
+func (r *PgRepository) UpdateBatchStatus(ctx context.Context, batchId uuid.UUID, status batch.Status) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	_, err := tx.Exec(ctx, "UPDATE batches SET status = $2 WHERE id = $1", batchId, string(status))
+	return err
+}
+
+func (r *PgRepository) GetBatch(ctx context.Context, batchId uuid.UUID) (batch.Batch, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var (
+		b      batch.Batch
+		mode   string
+		status string
+	)
+	query := "SELECT id, mode, status, created_at FROM batches WHERE id = $1"
+	if err := tx.QueryRow(ctx, query, batchId).Scan(&b.ID, &mode, &status, &b.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return batch.Batch{}, errors.New("batch not found")
+		}
+		return batch.Batch{}, err
+	}
+	b.Mode = batch.Mode(mode)
+	b.Status = batch.Status(status)
+
+	query = `SELECT item_index, wallet_id, operation_type, amount, status, COALESCE(error, ''), COALESCE(ledger_entry_id, 0)
+		FROM batch_items
+		WHERE batch_id = $1
+		ORDER BY item_index`
+	rows, err := tx.Query(ctx, query, batchId)
+	if err != nil {
+		return batch.Batch{}, err
+	}
+	defer rows.Close()
+
+	b.Items = []batch.Item{}
+	for rows.Next() {
+		var (
+			item       batch.Item
+			opType     string
+			itemStatus string
+		)
+		if err := rows.Scan(&item.Index, &item.WalletID, &opType, &item.Amount, &itemStatus, &item.Error, &item.LedgerEntryID); err != nil {
+			return batch.Batch{}, err
+		}
+		item.OperationType = ledger.OperationType(opType)
+		item.Status = batch.ItemStatus(itemStatus)
+		b.Items = append(b.Items, item)
+	}
+	return b, rows.Err()
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// pointBalanceExpr is the balance of wallet w at $at: the latest checkpoint
+// not after $at plus the entries between the two. Entries are ordered by
+// created_at, not id, because created_at is what callers ask about.
+const pointBalanceExpr = `COALESCE(c.balance, 0) + COALESCE((SELECT SUM(l.amount) FROM ledger_entries l
+		WHERE l.wallet_id = w.wallet_id AND l.created_at > COALESCE(c.at, '-infinity') AND l.created_at <= $2), 0)`
+
+const latestCheckpoint = `LEFT JOIN LATERAL (SELECT at, balance FROM balance_checkpoints
+		WHERE wallet_id = w.wallet_id AND at <= $2
+		ORDER BY at DESC LIMIT 1) c ON true`
+
+func (r *PgRepository) GetBalanceAt(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + pointBalanceExpr + " FROM wallets w " + latestCheckpoint + " WHERE w.wallet_id = $1"
+
+	var balance int64
+	if err := tx.QueryRow(ctx, query, walletId, at).Scan(&balance); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New("wallet not found")
+		}
+		return 0, err
+	}
+	return balance, nil
+}
+
+// GetBalancesAt returns balances for the wallets that exist, in no
+// particular order; unknown IDs are left out.
+func (r *PgRepository) GetBalancesAt(ctx context.Context, walletIds []uuid.UUID, at time.Time) ([]ledger.PointBalance, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT w.wallet_id, " + pointBalanceExpr + " FROM wallets w " + latestCheckpoint +
+		" WHERE w.wallet_id = ANY($1)"
+
+	rows, err := tx.Query(ctx, query, walletIds, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []ledger.PointBalance
+	for rows.Next() {
+		b := ledger.PointBalance{At: at}
+		if err := rows.Scan(&b.WalletID, &b.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// InsertBalanceCheckpoints writes a checkpoint at $2 for up to limit wallets
+// after afterId that have entries since their previous checkpoint, and
+// returns the last wallet it looked at. Wallets without activity keep using
+// their older checkpoint.
+func (r *PgRepository) InsertBalanceCheckpoints(ctx context.Context, afterId uuid.UUID, at time.Time, limit int) (uuid.UUID, int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var last uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(wallet_id), '00000000-0000-0000-0000-000000000000')
+		FROM (SELECT wallet_id FROM wallets WHERE wallet_id > $1 ORDER BY wallet_id LIMIT $2) page`,
+		afterId, limit).Scan(&last); err != nil {
+		return uuid.Nil, 0, err
+	}
+	if last == uuid.Nil {
+		return uuid.Nil, 0, nil
+	}
+
+	query := `INSERT INTO balance_checkpoints (wallet_id, at, balance)
+		SELECT w.wallet_id, $2, ` + pointBalanceExpr + `
+		FROM wallets w ` + latestCheckpoint + `
+		WHERE w.wallet_id > $1 AND w.wallet_id <= $3
+			AND EXISTS (SELECT 1 FROM ledger_entries l
+				WHERE l.wallet_id = w.wallet_id AND l.created_at > COALESCE(c.at, '-infinity') AND l.created_at <= $2)
+		ON CONFLICT DO NOTHING`
+
+	tag, err := tx.Exec(ctx, query, afterId, at, last)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+	return last, int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"context"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithdrawnSince sums the wallet's withdrawals and outgoing transfers
+// created at or after since, as a positive amount. It uses the
+// (wallet_id, created_at) index.
+func (r *PgRepository) WithdrawnSince(ctx context.Context, walletId uuid.UUID, since time.Time) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT COALESCE(-SUM(amount), 0) FROM ledger_entries
+		WHERE wallet_id = $1 AND operation_type IN ($2, $3) AND amount < 0 AND created_at >= $4`
+	var total int64
+	err := tx.QueryRow(ctx, query, walletId, string(ledger.Withdraw), string(ledger.Transfer), since).Scan(&total)
+	return total, err
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import "context"
+
+type txHooks struct {
+	onCommit   []func()
+	onRollback []func(error)
+}
+
+// OnCommit registers fn to run once the transaction in ctx has committed.
+// Outside a transaction there is nothing to wait for and fn runs at once.
+func OnCommit(ctx context.Context, fn func()) {
+	state := currentTx(ctx)
+	if state == nil {
+		fn()
+		return
+	}
+	state.hooks.onCommit = append(state.hooks.onCommit, fn)
+}
+
+// OnRollback registers fn to run if the transaction in ctx finally fails,
+// with the error it failed with. Inside a savepoint, fn also runs when just
+// the savepoint is rolled back.
+func OnRollback(ctx context.Context, fn func(error)) {
+	state := currentTx(ctx)
+	if state == nil {
+		return
+	}
+	state.hooks.onRollback = append(state.hooks.onRollback, fn)
+}
+
+func (h *txHooks) committed() {
+	for _, fn := range h.onCommit {
+		fn()
+	}
+}
+
+func (h *txHooks) rolledBack(err error) {
+	for _, fn := range h.onRollback {
+		fn(err)
+	}
+}
+
+// adopt hands the hooks of a released savepoint to its parent, whose
+// outcome now decides theirs.
+func (h *txHooks) adopt(child *txHooks) {
+	h.onCommit = append(h.onCommit, child.onCommit...)
+	h.onRollback = append(h.onRollback, child.onRollback...)
+}
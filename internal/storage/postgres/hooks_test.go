@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func TestHooks_OutsideTransaction(t *testing.T) {
+	ran := false
+	OnCommit(context.Background(), func() { ran = true })
+	require.True(t, ran, "OnCommit runs at once without a transaction")
+
+	OnRollback(context.Background(), func(error) { t.Fatal("OnRollback must not run without a transaction") })
+}
+
+func TestHooks_RunOnOutcome(t *testing.T) {
+	state := &txState{tx: &fakeTx{}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, state)
+
+	var (
+		commits int
+		cause   error
+	)
+	OnCommit(ctx, func() { commits++ })
+	OnRollback(ctx, func(err error) { cause = err })
+	require.Zero(t, commits, "OnCommit waits for the commit")
+
+	state.hooks.committed()
+	require.Equal(t, 1, commits)
+	require.NoError(t, cause)
+
+	fail := errors.New("serialization failure")
+	state.hooks.rolledBack(fail)
+	require.Equal(t, 1, commits)
+	require.Equal(t, fail, cause)
+}
+
+func TestHooks_SavepointHandsHooksToParent(t *testing.T) {
+	outer := &txState{tx: &fakeTx{}, options: pgx.TxOptions{IsoLevel: pgx.Serializable}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, outer)
+	tm := &TxManager{}
+
+	var events []string
+	require.NoError(t, tm.savepoint(ctx, outer, pgx.TxOptions{}, func(ctxTx context.Context) error {
+		OnCommit(ctxTx, func() { events = append(events, "released:commit") })
+		OnRollback(ctxTx, func(error) { events = append(events, "released:rollback") })
+		return nil
+	}))
+	require.Empty(t, events, "a released savepoint's hooks wait for the outer transaction")
+
+	fail := errors.New("duplicate key")
+	require.ErrorIs(t, tm.savepoint(ctx, outer, pgx.TxOptions{}, func(ctxTx context.Context) error {
+		OnCommit(ctxTx, func() { events = append(events, "failed:commit") })
+		OnRollback(ctxTx, func(err error) { events = append(events, "failed:rollback:"+err.Error()) })
+		return fail
+	}), fail)
+	require.Equal(t, []string{"failed:rollback:duplicate key"}, events)
+
+	outer.hooks.committed()
+	require.Equal(t, []string{"failed:rollback:duplicate key", "released:commit"}, events)
+}
+
+func TestHooks_SavepointCommitFailure(t *testing.T) {
+	outerTx := &fakeTx{}
+	outer := &txState{tx: outerTx, options: pgx.TxOptions{IsoLevel: pgx.Serializable}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, outer)
+
+	var cause error
+	releaseErr := errors.New("release failed")
+	err := (&TxManager{}).savepoint(ctx, outer, pgx.TxOptions{}, func(ctxTx context.Context) error {
+		currentTx(ctxTx).tx.(*fakeTx).commitErr = releaseErr
+		OnCommit(ctxTx, func() { t.Fatal("OnCommit must not run for a savepoint that failed to release") })
+		OnRollback(ctxTx, func(err error) { cause = err })
+		return nil
+	})
+	require.ErrorIs(t, err, releaseErr)
+	require.Equal(t, releaseErr, cause)
+
+	outer.hooks.committed()
+}
@@ -0,0 +1,321 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/importer"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const importJobColumns = "id, file_hash, status, total_rows, processed_rows, skipped_rows, COALESCE(error, ''), created_at, updated_at"
+
+func scanImportJob(row pgx.Row) (importer.Job, error) {
+	var (
+		job    importer.Job
+		status string
+	)
+	err := row.Scan(&job.ID, &job.FileHash, &status, &job.TotalRows, &job.ProcessedRows, &job.SkippedRows,
+		&job.Error, &job.CreatedAt, &job.UpdatedAt)
+	job.Status = importer.Status(status)
+	return job, err
+}
+
+func (r *PgRepository) InsertImportJob(ctx context.Context, job importer.Job, content []byte) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO import_jobs (id, file_hash, status, total_rows, content, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := tx.Exec(ctx, query, job.ID, job.FileHash, string(job.Status), job.TotalRows, content, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return errors.New("import already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgRepository) GetImportJob(ctx context.Context, id uuid.UUID) (importer.Job, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + importJobColumns + " FROM import_jobs WHERE id = $1"
+	job, err := scanImportJob(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return importer.Job{}, errors.New("import job not found")
+		}
+		return importer.Job{}, err
+	}
+	return job, nil
+}
+
+func (r *PgRepository) GetImportJobByHash(ctx context.Context, fileHash string) (importer.Job, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + importJobColumns + " FROM import_jobs WHERE file_hash = $1"
+	job, err := scanImportJob(tx.QueryRow(ctx, query, fileHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return importer.Job{}, errors.New("import job not found")
+		}
+		return importer.Job{}, err
+	}
+	return job, nil
+}
+
+func (r *PgRepository) ClaimImportJob(ctx context.Context, id uuid.UUID, staleAfter time.Duration) (importer.Job, []byte, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE import_jobs SET status = 'RUNNING', updated_at = now()
+		WHERE id = $1 AND (status = 'PENDING' OR (status = 'RUNNING' AND updated_at < now() - $2::interval))
+		RETURNING ` + importJobColumns + ", content"
+
+	var (
+		job     importer.Job
+		status  string
+		content []byte
+	)
+	err := tx.QueryRow(ctx, query, id, staleAfter).Scan(&job.ID, &job.FileHash, &status, &job.TotalRows,
+		&job.ProcessedRows, &job.SkippedRows, &job.Error, &job.CreatedAt, &job.UpdatedAt, &content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return importer.Job{}, nil, errors.New("import job not claimable")
+		}
+		return importer.Job{}, nil, err
+	}
+	job.Status = importer.Status(status)
+	return job, content, nil
+}
+
+// RestartImportJob makes a FAILED job PENDING again. It resumes from its
+// processed rows, since the chunks before them were committed.
+func (r *PgRepository) RestartImportJob(ctx context.Context, id uuid.UUID) (importer.Job, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE import_jobs SET status = 'PENDING', error = NULL, updated_at = now()
+		WHERE id = $1 AND status = 'FAILED'
+		RETURNING ` + importJobColumns
+	job, err := scanImportJob(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return importer.Job{}, errors.New("import job not restartable")
+		}
+		return importer.Job{}, err
+	}
+	return job, nil
+}
+
+func (r *PgRepository) ListRunnableImportJobs(ctx context.Context, staleAfter time.Duration, limit int) ([]uuid.UUID, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT id FROM import_jobs
+		WHERE status = 'PENDING' OR (status = 'RUNNING' AND updated_at < now() - $1::interval)
+		ORDER BY created_at
+		LIMIT $2`
+
+	rows, err := tx.Query(ctx, query, staleAfter, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ApplyImportChunk copies the chunk into the staging table and applies it
+// with set-based statements. Rows whose external_ref is already in the ledger
+// are skipped, which makes re-running a chunk after a crash harmless. The
+// progress update is guarded by the expected offset so two runners cannot
+// apply the same chunk.
+//
+// A row with a fee is staged as three legs: the row itself (leg 0), the FEE
+// debit of its wallet (leg 1) and the FEE credit of the revenue wallet
+// (leg 2). From then on the legs are applied like rows of their own.
+//
+// A positive maxBalance caps every wallet's balance, as WithMaxBalance does
+// for the API.
+func (r *PgRepository) ApplyImportChunk(ctx context.Context, jobId uuid.UUID, offset int, rows []importer.Row, maxBalance int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var legs [][]interface{}
+	for i, row := range rows {
+		amount := row.Amount
+		if row.OperationType == ledger.Withdraw {
+			amount = -amount
+		}
+		legs = append(legs, []interface{}{jobId, offset + i, 0, row.WalletID, string(row.OperationType), amount, row.ExternalRef})
+		if row.Fee.Amount > 0 {
+			legs = append(legs,
+				[]interface{}{jobId, offset + i, 1, row.WalletID, string(ledger.Fee), -row.Fee.Amount, nil},
+				[]interface{}{jobId, offset + i, 2, row.Fee.Revenue, string(ledger.Fee), row.Fee.Amount, nil})
+		}
+	}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"import_staging"},
+		[]string{"job_id", "row_no", "leg", "wallet_id", "operation_type", "amount", "external_ref"},
+		pgx.CopyFromRows(legs))
+	if err != nil {
+		return err
+	}
+
+	var skipped int
+	if err := tx.QueryRow(ctx, `WITH skipped AS (
+			DELETE FROM import_staging s
+			WHERE s.job_id = $1 AND s.row_no IN (
+				SELECT st.row_no FROM import_staging st
+				JOIN ledger_entries l ON l.external_ref = st.external_ref
+				WHERE st.job_id = $1)
+			RETURNING s.leg
+		)
+		SELECT count(*) FROM skipped WHERE leg = 0`, jobId).Scan(&skipped); err != nil {
+		return err
+	}
+
+	var missing int
+	if err := tx.QueryRow(ctx, `SELECT count(DISTINCT s.wallet_id)
+		FROM import_staging s
+		LEFT JOIN wallets w ON w.wallet_id = s.wallet_id
+		WHERE s.job_id = $1 AND w.wallet_id IS NULL`, jobId).Scan(&missing); err != nil {
+		return err
+	}
+	if missing > 0 {
+		return fmt.Errorf("wallet not found: %d unknown wallets in rows %d-%d", missing, offset+1, offset+len(rows))
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM wallets
+		WHERE wallet_id IN (SELECT wallet_id FROM import_staging WHERE job_id = $1)
+		ORDER BY wallet_id
+		FOR UPDATE`, jobId); err != nil {
+		return err
+	}
+
+	var (
+		inactiveRow int
+		status      string
+	)
+	err = tx.QueryRow(ctx, `SELECT s.row_no, w.status
+		FROM import_staging s
+		JOIN wallets w ON w.wallet_id = s.wallet_id
+		WHERE s.job_id = $1 AND w.status <> 'ACTIVE'
+		ORDER BY s.row_no, s.leg
+		LIMIT 1`, jobId).Scan(&inactiveRow, &status)
+	switch {
+	case err == nil:
+		return fmt.Errorf("wallet is %s on line %d", status, rows[inactiveRow-offset].Line)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	// Fold sharded wallets back into their main row first, so that the
+	// set-based update below sees each wallet's whole balance.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM wallet_shards
+		WHERE wallet_id IN (SELECT wallet_id FROM import_staging WHERE job_id = $1)
+		ORDER BY wallet_id, shard_no
+		FOR UPDATE`, jobId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE wallets w
+		SET balance = w.balance + sh.total
+		FROM (SELECT wallet_id, SUM(balance) AS total FROM wallet_shards
+			WHERE wallet_id IN (SELECT wallet_id FROM import_staging WHERE job_id = $1) AND balance <> 0
+			GROUP BY wallet_id) sh
+		WHERE w.wallet_id = sh.wallet_id`, jobId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE wallet_shards SET balance = 0
+		WHERE wallet_id IN (SELECT wallet_id FROM import_staging WHERE job_id = $1) AND balance <> 0`, jobId); err != nil {
+		return err
+	}
+
+	// Each row must fit the balance its wallet has at that point of the file,
+	// not just the chunk's net total: a withdrawal cannot be funded by a
+	// deposit that comes after it.
+	var (
+		rowNo  int
+		amount int64
+	)
+	err = tx.QueryRow(ctx, `SELECT row_no, amount FROM (
+			SELECT s.row_no, s.leg, s.amount,
+				w.balance + SUM(s.amount) OVER (
+					PARTITION BY s.wallet_id ORDER BY s.row_no, s.leg
+					ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS running
+			FROM import_staging s
+			JOIN wallets w ON w.wallet_id = s.wallet_id
+			WHERE s.job_id = $1
+		) r
+		WHERE (amount < 0 AND running < 0) OR ($2::bigint > 0 AND amount > 0 AND running > $2::bigint)
+		ORDER BY row_no, leg
+		LIMIT 1`, jobId, maxBalance).Scan(&rowNo, &amount)
+	switch {
+	case err == nil && amount < 0:
+		return fmt.Errorf("not enough balance on line %d", rows[rowNo-offset].Line)
+	case err == nil:
+		return fmt.Errorf("balance limit exceeded on line %d", rows[rowNo-offset].Line)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets w
+		SET balance = w.balance + s.total, min_balance = `+minBalanceAfter("w.balance", "s.total")+`,
+			version = w.version + 1
+		FROM (SELECT wallet_id, SUM(amount) AS total FROM import_staging WHERE job_id = $1 GROUP BY wallet_id) s
+		WHERE w.wallet_id = s.wallet_id`, jobId); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23514" {
+			return fmt.Errorf("not enough balance in rows %d-%d", offset+1, offset+len(rows))
+		}
+		return err
+	}
+
+	// balance_after of each row is the wallet's new balance minus every later
+	// row of the same wallet in this chunk.
+	if _, err := tx.Exec(ctx, `WITH entries AS (
+			INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after, external_ref)
+			SELECT s.wallet_id, s.operation_type, s.amount,
+				w.balance - COALESCE(SUM(s.amount) OVER (
+					PARTITION BY s.wallet_id ORDER BY s.row_no, s.leg
+					ROWS BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING), 0),
+				s.external_ref
+			FROM import_staging s
+			JOIN wallets w ON w.wallet_id = s.wallet_id
+			WHERE s.job_id = $1
+			ORDER BY s.row_no, s.leg
+			RETURNING id, wallet_id, amount, balance_after
+		)
+		INSERT INTO outbox (event_type, wallet_id, payload)
+		SELECT CASE WHEN amount >= 0 THEN $2 ELSE $3 END, wallet_id,
+			json_build_object('amount', abs(amount), 'balance', balance_after, 'ledgerEntryId', id)
+		FROM entries
+		ORDER BY id`, jobId, string(outbox.WalletCredited), string(outbox.WalletDebited)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM import_staging WHERE job_id = $1", jobId); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE import_jobs
+		SET processed_rows = $2 + $3, skipped_rows = skipped_rows + $4, updated_at = now()
+		WHERE id = $1 AND processed_rows = $2`, jobId, offset, len(rows), skipped)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("import job progressed concurrently")
+	}
+	return nil
+}
+
+func (r *PgRepository) FinishImportJob(ctx context.Context, jobId uuid.UUID, status importer.Status, cause string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "UPDATE import_jobs SET status = $2, error = NULLIF($3, ''), updated_at = now() WHERE id = $1"
+	_, err := tx.Exec(ctx, query, jobId, string(status), cause)
+	return err
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/interest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+)
+
+// ListAccrualInputs reads up to limit savings wallets after afterId that
+// existed by the end of day, with their balance at the end of it and the
+// remainder of their latest earlier accrual.
+func (r *PgRepository) ListAccrualInputs(ctx context.Context, afterId uuid.UUID, day time.Time, limit int) ([]interest.Input, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT w.wallet_id, ` + openingBalanceExpr + `, w.interest_rate_bps,
+			COALESCE((SELECT a.remainder FROM interest_accruals a
+				WHERE a.wallet_id = w.wallet_id AND a.day < $4
+				ORDER BY a.day DESC LIMIT 1), 0)
+		FROM wallets w
+		WHERE w.wallet_type = 'SAVINGS' AND w.wallet_id > $1 AND w.created_at < $2
+		ORDER BY w.wallet_id
+		LIMIT $3`
+
+	rows, err := tx.Query(ctx, query, afterId, day.AddDate(0, 0, 1), limit, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inputs []interest.Input
+	for rows.Next() {
+		var in interest.Input
+		if err := rows.Scan(&in.WalletID, &in.Balance, &in.RateBps, &in.Carry); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, rows.Err()
+}
+
+// InsertInterestAccruals skips wallets already accrued for the day, so a
+// day can be run again after a crash.
+func (r *PgRepository) InsertInterestAccruals(ctx context.Context, accruals []interest.Accrual) (int, error) {
+	if len(accruals) == 0 {
+		return 0, nil
+	}
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	n := len(accruals)
+	ids, days := make([]uuid.UUID, n), make([]time.Time, n)
+	balances, rates, amounts, remainders := make([]int64, n), make([]int64, n), make([]int64, n), make([]int64, n)
+	for i, a := range accruals {
+		ids[i], days[i] = a.WalletID, a.Day
+		balances[i], rates[i], amounts[i], remainders[i] = a.Balance, a.RateBps, a.Amount, a.Remainder
+	}
+
+	query := `INSERT INTO interest_accruals (wallet_id, day, balance, rate_bps, amount, remainder)
+		SELECT * FROM unnest($1::uuid[], $2::date[], $3::bigint[], $4::int[], $5::bigint[], $6::bigint[])
+		ON CONFLICT DO NOTHING`
+	tag, err := tx.Exec(ctx, query, ids, days, balances, rates, amounts, remainders)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// InterestRunRange is the first and last period job has completed, both
+// zero if it never has.
+func (r *PgRepository) InterestRunRange(ctx context.Context, job interest.Job) (time.Time, time.Time, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var first, last *time.Time
+	if err := tx.QueryRow(ctx, "SELECT MIN(period), MAX(period) FROM interest_runs WHERE job = $1",
+		string(job)).Scan(&first, &last); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if first == nil || last == nil {
+		return time.Time{}, time.Time{}, nil
+	}
+	return *first, *last, nil
+}
+
+func (r *PgRepository) InsertInterestRun(ctx context.Context, job interest.Job, period time.Time, wallets int) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	_, err := tx.Exec(ctx, "INSERT INTO interest_runs (job, period, wallets) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		string(job), period, wallets)
+	return err
+}
+
+// ListInterestDue sums the month's accruals of up to limit wallets after
+// afterId that earned something and have not been paid for it yet.
+func (r *PgRepository) ListInterestDue(ctx context.Context, month time.Time, afterId uuid.UUID, limit int) ([]interest.Due, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT a.wallet_id, SUM(a.amount)
+		FROM interest_accruals a
+		WHERE a.day >= $1 AND a.day < $2 AND a.wallet_id > $3
+			AND NOT EXISTS (SELECT 1 FROM interest_postings p WHERE p.wallet_id = a.wallet_id AND p.month = $1)
+		GROUP BY a.wallet_id
+		HAVING SUM(a.amount) > 0
+		ORDER BY a.wallet_id
+		LIMIT $4`
+
+	rows, err := tx.Query(ctx, query, month, month.AddDate(0, 1, 0), afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []interest.Due
+	for rows.Next() {
+		var d interest.Due
+		if err := rows.Scan(&d.WalletID, &d.Amount); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+func (r *PgRepository) InsertInterestPosting(ctx context.Context, p interest.Posting) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	_, err := tx.Exec(ctx, `INSERT INTO interest_postings (wallet_id, month, amount, ledger_entry_id)
+		VALUES ($1, $2, $3, $4)`, p.WalletID, p.Month, p.Amount, p.LedgerEntryID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return errors.New("interest already posted")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgRepository) UpdateInterestRate(ctx context.Context, walletId uuid.UUID, rateBps int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	tag, err := tx.Exec(ctx, "UPDATE wallets SET interest_rate_bps = $2 WHERE wallet_id = $1 AND wallet_type = 'SAVINGS'",
+		walletId, rateBps)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM wallets WHERE wallet_id = $1)", walletId).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("wallet not found")
+	}
+	return errors.New("wallet is not a savings wallet")
+}
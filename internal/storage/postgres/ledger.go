@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/ledger"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const ledgerColumns = "id, wallet_id, operation_type, amount, balance_after, COALESCE(external_ref, ''), reversal_of, NULLIF(shard_no, -1), created_at"
+
+func scanLedgerEntry(row pgx.Row) (ledger.Entry, error) {
+	var (
+		entry  ledger.Entry
+		opType string
+	)
+	if err := row.Scan(&entry.ID, &entry.WalletID, &opType, &entry.Amount, &entry.Balance, &entry.ExternalRef, &entry.ReversalOf, &entry.Shard, &entry.CreatedAt); err != nil {
+		return ledger.Entry{}, err
+	}
+	entry.OperationType = ledger.OperationType(opType)
+	return entry, nil
+}
+
+// InsertLedgerEntry must run after the balance update in the same transaction:
+// balance_after is read back from the already-updated wallet row and shards.
+// The caller holds the wallets row lock, which keeps the wallet's main lane
+// in commit order.
+func (r *PgRepository) InsertLedgerEntry(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after)
+		SELECT w.wallet_id, $2, $3,
+			w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)
+		FROM wallets w
+		WHERE w.wallet_id = $1
+		RETURNING ` + ledgerColumns
+
+	entry, err := scanLedgerEntry(tx.QueryRow(ctx, query, walletId, string(opType), amount))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ledger.Entry{}, errors.New("wallet not found")
+		}
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
+
+// InsertShardEntry is InsertLedgerEntry for a credit made by DepositToShard,
+// written in the lane of that shard. The caller holds only the shard row
+// lock, so balance_after misses deposits still in flight on other shards.
+func (r *PgRepository) InsertShardEntry(ctx context.Context, walletId uuid.UUID, shard int, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after, shard_no)
+		SELECT w.wallet_id, $2, $3,
+			w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0),
+			$4
+		FROM wallets w
+		WHERE w.wallet_id = $1
+		RETURNING ` + ledgerColumns
+
+	entry, err := scanLedgerEntry(tx.QueryRow(ctx, query, walletId, string(opType), amount, shard))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ledger.Entry{}, errors.New("wallet not found")
+		}
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
+
+// InsertRefEntry is InsertLedgerEntry for an entry carrying externalRef,
+// which the ledger holds at most once.
+func (r *PgRepository) InsertRefEntry(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amount int64, externalRef string) (ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after, external_ref)
+		SELECT w.wallet_id, $2, $3,
+			w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0),
+			$4
+		FROM wallets w
+		WHERE w.wallet_id = $1
+		RETURNING ` + ledgerColumns
+
+	entry, err := scanLedgerEntry(tx.QueryRow(ctx, query, walletId, string(opType), amount, externalRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ledger.Entry{}, errors.New("wallet not found")
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return ledger.Entry{}, errors.New("external reference already used")
+		}
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
+
+// InsertLedgerEntries writes one entry per amount after a single combined
+// balance update. balance_after of each entry is the updated balance minus
+// every later amount, as if the amounts had been applied one by one.
+func (r *PgRepository) InsertLedgerEntries(ctx context.Context, walletId uuid.UUID, opType ledger.OperationType, amounts []int64) ([]ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after)
+		SELECT w.wallet_id, $2, a.amount,
+			w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)
+				- COALESCE(SUM(a.amount) OVER (ORDER BY a.ord ROWS BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING), 0)
+		FROM wallets w, unnest($3::bigint[]) WITH ORDINALITY AS a(amount, ord)
+		WHERE w.wallet_id = $1
+		ORDER BY a.ord
+		RETURNING ` + ledgerColumns
+
+	rows, err := tx.Query(ctx, query, walletId, string(opType), amounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ledger.Entry
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("wallet not found")
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// ListLedgerEntries pages through the wallet's entries past after, lane by
+// lane, in id order.
+func (r *PgRepository) ListLedgerEntries(ctx context.Context, walletId uuid.UUID, after ledger.Cursor, limit int) ([]ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	lanes := make([]int32, 0, len(after.Lanes))
+	ids := make([]int64, 0, len(after.Lanes))
+	for lane := range after.Lanes {
+		lanes = append(lanes, int32(lane))
+		ids = append(ids, after.After(lane))
+	}
+	query := "SELECT " + ledgerColumns + ` FROM ledger_entries e
+		WHERE e.wallet_id = $1 AND e.id > COALESCE(
+			(SELECT c.after FROM unnest($2::int[], $3::bigint[]) AS c(shard_no, after) WHERE c.shard_no = e.shard_no), $4)
+		ORDER BY e.id
+		LIMIT $5`
+
+	rows, err := tx.Query(ctx, query, walletId, lanes, ids, after.Floor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ledger.Entry
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// LedgerHead is the cursor just past every entry the wallet has committed:
+// the last id of each of its lanes. A lane with no entries yet is left out,
+// as its first entry may hold an id lower than other lanes' last ones.
+func (r *PgRepository) LedgerHead(ctx context.Context, walletId uuid.UUID) (ledger.Cursor, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	// Walks the distinct lanes on the (wallet_id, shard_no, id) index.
+	query := `WITH RECURSIVE lanes AS (
+			SELECT MIN(shard_no) AS shard_no FROM ledger_entries WHERE wallet_id = $1
+			UNION ALL
+			SELECT (SELECT MIN(e.shard_no) FROM ledger_entries e WHERE e.wallet_id = $1 AND e.shard_no > l.shard_no)
+			FROM lanes l WHERE l.shard_no IS NOT NULL
+		)
+		SELECT l.shard_no, (SELECT MAX(e.id) FROM ledger_entries e WHERE e.wallet_id = $1 AND e.shard_no = l.shard_no)
+		FROM lanes l
+		WHERE l.shard_no IS NOT NULL`
+
+	rows, err := tx.Query(ctx, query, walletId)
+	if err != nil {
+		return ledger.Cursor{}, err
+	}
+	defer rows.Close()
+
+	head := ledger.Cursor{Lanes: make(map[int]int64)}
+	for rows.Next() {
+		var (
+			lane int
+			id   int64
+		)
+		if err := rows.Scan(&lane, &id); err != nil {
+			return ledger.Cursor{}, err
+		}
+		head.Lanes[lane] = id
+	}
+	return head, rows.Err()
+}
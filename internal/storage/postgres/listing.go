@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"project/internal/wallet"
+	"strings"
+)
+
+// ListWallets builds the listing query from the filter and orders by the
+// sort key with wallet_id as tie-breaker, so the cursor comparison below is
+// a plain row comparison. For created_at and wallet_id the (key, wallet_id)
+// indexes serve it. The balance includes the wallet's shards, so sorting or
+// filtering on it computes the balance of every wallet the other filters
+// leave and sorts them; narrow those listings by owner, status or currency.
+func (r *PgRepository) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	f := q.Filter
+	if f.OwnerID != "" {
+		where = append(where, "w.owner_id = "+arg(f.OwnerID))
+	}
+	if f.Status != "" {
+		where = append(where, "w.status = "+arg(string(f.Status)))
+	}
+	if f.Currency != "" {
+		where = append(where, "w.currency = "+arg(f.Currency))
+	}
+	if f.MinBalance != nil {
+		where = append(where, "("+balanceExpr+") >= "+arg(*f.MinBalance))
+	}
+	if f.MaxBalance != nil {
+		where = append(where, "("+balanceExpr+") <= "+arg(*f.MaxBalance))
+	}
+	if !f.CreatedFrom.IsZero() {
+		where = append(where, "w.created_at >= "+arg(f.CreatedFrom))
+	}
+	if !f.CreatedBefore.IsZero() {
+		where = append(where, "w.created_at < "+arg(f.CreatedBefore))
+	}
+
+	var key string
+	switch q.Sort {
+	case wallet.SortBalance:
+		key = "(" + balanceExpr + ")"
+	case wallet.SortWalletID:
+		key = ""
+	default:
+		key = "w.created_at"
+	}
+
+	cmp, dir := ">", "ASC"
+	if q.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if c := q.After; c != nil {
+		switch q.Sort {
+		case wallet.SortBalance:
+			where = append(where, fmt.Sprintf("(%s, w.wallet_id) %s (%s, %s)", key, cmp, arg(c.Balance), arg(c.ID)))
+		case wallet.SortWalletID:
+			where = append(where, fmt.Sprintf("w.wallet_id %s %s", cmp, arg(c.ID)))
+		default:
+			where = append(where, fmt.Sprintf("(%s, w.wallet_id) %s (%s, %s)", key, cmp, arg(c.CreatedAt), arg(c.ID)))
+		}
+	}
+
+	query := "SELECT " + walletColumns + " FROM wallets w"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if key != "" {
+		query += " ORDER BY " + key + " " + dir + ", w.wallet_id " + dir
+	} else {
+		query += " ORDER BY w.wallet_id " + dir
+	}
+	query += " LIMIT " + arg(q.Limit)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []wallet.Wallet
+	for rows.Next() {
+		wl, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wl)
+	}
+	return wallets, rows.Err()
+}
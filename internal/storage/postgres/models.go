@@ -12,10 +12,14 @@ type QueryEngine interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 }
 
 type TransactionManager interface {
 	GetQueryEngine(ctx context.Context) QueryEngine
-	RunReadUncommitted(ctx context.Context, fn func(ctxTx context.Context) error) error
+	RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error
+	RunReadOnly(ctx context.Context, fn func(ctxTx context.Context) error) error
+	RunReadOnlyDeferrable(ctx context.Context, fn func(ctxTx context.Context) error) error
 	RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error
 }
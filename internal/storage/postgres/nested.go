@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type txState struct {
+	tx      pgx.Tx
+	options pgx.TxOptions
+	hooks   *txHooks
+}
+
+func currentTx(ctx context.Context) *txState {
+	state, _ := ctx.Value(txManagerKey{}).(*txState)
+	return state
+}
+
+// InTransaction reports whether ctx already carries a transaction, so a
+// Run call made with it would join that transaction.
+func InTransaction(ctx context.Context) bool {
+	return currentTx(ctx) != nil
+}
+
+// savepoint runs fn inside the transaction already in ctx, guarded by a
+// SAVEPOINT: an error rolls back only fn's work, and the caller decides
+// whether the outer transaction goes on. Retries are left to the outermost
+// Run call, since a serialization failure dooms the whole transaction.
+func (tm *TxManager) savepoint(ctx context.Context, outer *txState, options pgx.TxOptions, fn func(ctxTx context.Context) error) error {
+	if err := canNest(outer.options, options); err != nil {
+		return err
+	}
+
+	sp, err := outer.tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback(ctx)
+
+	hooks := &txHooks{}
+	err = fn(context.WithValue(ctx, txManagerKey{}, &txState{tx: sp, options: outer.options, hooks: hooks}))
+	if err == nil {
+		err = sp.Commit(ctx)
+	}
+	if err != nil {
+		hooks.rolledBack(err)
+		return err
+	}
+
+	outer.hooks.adopt(hooks)
+	return nil
+}
+
+// canNest allows an inner transaction whose guarantees the outer one
+// already provides: the same or a weaker isolation level, and no writes
+// inside a read-only transaction.
+func canNest(outer, inner pgx.TxOptions) error {
+	if isolationRank(inner.IsoLevel) > isolationRank(outer.IsoLevel) {
+		return fmt.Errorf("cannot nest %s transaction inside %s transaction",
+			isolationName(inner.IsoLevel), isolationName(outer.IsoLevel))
+	}
+	if outer.AccessMode == pgx.ReadOnly && inner.AccessMode != pgx.ReadOnly {
+		return fmt.Errorf("cannot nest read write transaction inside read only transaction")
+	}
+	return nil
+}
+
+func isolationRank(level pgx.TxIsoLevel) int {
+	switch level {
+	case pgx.Serializable:
+		return 3
+	case pgx.RepeatableRead:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func isolationName(level pgx.TxIsoLevel) string {
+	if level == "" {
+		return string(pgx.ReadCommitted)
+	}
+	return string(level)
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// fakeTx records how a savepoint was released; only Begin, Commit and
+// Rollback are implemented.
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+	child      *fakeTx
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx.child = &fakeTx{}
+	return tx.child, nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.commitErr != nil {
+		return tx.commitErr
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if tx.committed {
+		return pgx.ErrTxClosed
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+func TestCanNest(t *testing.T) {
+	serializable := pgx.TxOptions{IsoLevel: pgx.Serializable}
+	readCommitted := pgx.TxOptions{IsoLevel: pgx.ReadCommitted}
+	readOnly := pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly}
+
+	require.NoError(t, canNest(serializable, serializable))
+	require.NoError(t, canNest(serializable, readCommitted))
+	require.NoError(t, canNest(serializable, pgx.TxOptions{}))
+	require.NoError(t, canNest(readOnly, readOnly))
+	require.NoError(t, canNest(pgx.TxOptions{}, readCommitted))
+
+	require.EqualError(t, canNest(readCommitted, serializable),
+		"cannot nest serializable transaction inside read committed transaction")
+	require.EqualError(t, canNest(pgx.TxOptions{}, pgx.TxOptions{IsoLevel: pgx.RepeatableRead}),
+		"cannot nest repeatable read transaction inside read committed transaction")
+	require.EqualError(t, canNest(readOnly, serializable),
+		"cannot nest read write transaction inside read only transaction")
+}
+
+func TestInTransaction(t *testing.T) {
+	require.False(t, InTransaction(context.Background()))
+
+	ctx := context.WithValue(context.Background(), txManagerKey{}, &txState{tx: &fakeTx{}, hooks: &txHooks{}})
+	require.True(t, InTransaction(ctx))
+}
+
+func TestSavepoint_ReleasedJoinsOuter(t *testing.T) {
+	outer := &txState{tx: &fakeTx{}, options: pgx.TxOptions{IsoLevel: pgx.Serializable}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, outer)
+
+	var inner *txState
+	err := (&TxManager{}).savepoint(ctx, outer, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(ctxTx context.Context) error {
+		inner = currentTx(ctxTx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	sp := outer.tx.(*fakeTx).child
+	require.Same(t, sp, inner.tx)
+	require.Equal(t, outer.options, inner.options, "a savepoint runs with the outer transaction's options")
+	require.True(t, sp.committed)
+	require.False(t, sp.rolledBack)
+}
+
+func TestSavepoint_ErrorRollsBackOnlyTheSavepoint(t *testing.T) {
+	outerTx := &fakeTx{}
+	outer := &txState{tx: outerTx, options: pgx.TxOptions{IsoLevel: pgx.Serializable}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, outer)
+
+	fail := errors.New("insert failed")
+	err := (&TxManager{}).savepoint(ctx, outer, pgx.TxOptions{}, func(ctxTx context.Context) error {
+		return fail
+	})
+	require.ErrorIs(t, err, fail)
+	require.True(t, outerTx.child.rolledBack)
+	require.False(t, outerTx.rolledBack)
+	require.False(t, outerTx.committed)
+}
+
+func TestSavepoint_RefusesStrongerIsolation(t *testing.T) {
+	outerTx := &fakeTx{}
+	outer := &txState{tx: outerTx, options: pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, hooks: &txHooks{}}
+	ctx := context.WithValue(context.Background(), txManagerKey{}, outer)
+
+	called := false
+	err := (&TxManager{}).savepoint(ctx, outer, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctxTx context.Context) error {
+		called = true
+		return nil
+	})
+	require.EqualError(t, err, "cannot nest serializable transaction inside read committed transaction")
+	require.False(t, called)
+	require.Nil(t, outerTx.child, "no savepoint is taken")
+}
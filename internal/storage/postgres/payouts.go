@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/payout"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const payoutColumns = `id, wallet_id, amount, fee, currency, beneficiary_name, beneficiary_iban, beneficiary_bic, reference,
+	end_to_end_id, status, file_id, reason, created_at, updated_at`
+
+func scanPayout(row pgx.Row) (payout.Payout, error) {
+	var (
+		p      payout.Payout
+		status string
+	)
+	err := row.Scan(&p.ID, &p.WalletID, &p.Amount, &p.Fee, &p.Currency, &p.Beneficiary.Name, &p.Beneficiary.IBAN,
+		&p.Beneficiary.BIC, &p.Reference, &p.EndToEndID, &status, &p.FileID, &p.Reason, &p.CreatedAt, &p.UpdatedAt)
+	p.Status = payout.Status(status)
+	return p, err
+}
+
+func (r *PgRepository) InsertPayout(ctx context.Context, p payout.Payout) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO payouts (id, wallet_id, amount, fee, currency, beneficiary_name, beneficiary_iban, beneficiary_bic,
+			reference, end_to_end_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err := tx.Exec(ctx, query, p.ID, p.WalletID, p.Amount, p.Fee, p.Currency, p.Beneficiary.Name, p.Beneficiary.IBAN,
+		p.Beneficiary.BIC, p.Reference, p.EndToEndID, string(p.Status), p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return errors.New("payout already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgRepository) GetPayout(ctx context.Context, id uuid.UUID) (payout.Payout, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + payoutColumns + " FROM payouts WHERE id = $1"
+	p, err := scanPayout(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payout.Payout{}, errors.New("payout not found")
+		}
+		return payout.Payout{}, err
+	}
+	return p, nil
+}
+
+func (r *PgRepository) ListPendingPayouts(ctx context.Context, limit int) ([]payout.Payout, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + payoutColumns + ` FROM payouts
+		WHERE status = 'PENDING'
+		ORDER BY created_at, id
+		LIMIT $1
+		FOR UPDATE`
+	return r.queryPayouts(ctx, tx, query, limit)
+}
+
+func (r *PgRepository) ListFilePayoutsForUpdate(ctx context.Context, fileId uuid.UUID) ([]payout.Payout, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + payoutColumns + " FROM payouts WHERE file_id = $1 ORDER BY created_at, id FOR UPDATE"
+	return r.queryPayouts(ctx, tx, query, fileId)
+}
+
+func (r *PgRepository) queryPayouts(ctx context.Context, tx QueryEngine, query string, args ...any) ([]payout.Payout, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []payout.Payout
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, p)
+	}
+	return payouts, rows.Err()
+}
+
+func (r *PgRepository) MarkPayoutsExported(ctx context.Context, fileId uuid.UUID, ids []uuid.UUID) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE payouts SET status = 'EXPORTED', file_id = $1, updated_at = now()
+		WHERE id = ANY($2) AND status = 'PENDING'`
+	tag, err := tx.Exec(ctx, query, fileId, ids)
+	if err != nil {
+		return err
+	}
+	if int(tag.RowsAffected()) != len(ids) {
+		return errors.New("payout is no longer pending")
+	}
+	return nil
+}
+
+func (r *PgRepository) UpdatePayoutStatus(ctx context.Context, id uuid.UUID, status payout.Status, reason string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "UPDATE payouts SET status = $2, reason = $3, updated_at = now() WHERE id = $1"
+	tag, err := tx.Exec(ctx, query, id, string(status), reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("payout not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) InsertPayoutFile(ctx context.Context, f payout.File) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO payout_files (id, message_id, payout_count, control_sum, content, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := tx.Exec(ctx, query, f.ID, f.MessageID, f.Payouts, f.ControlSum, f.Content, f.CreatedAt)
+	return err
+}
+
+func (r *PgRepository) GetPayoutFile(ctx context.Context, id uuid.UUID) (payout.File, error) {
+	return r.getPayoutFile(ctx, "id", id)
+}
+
+func (r *PgRepository) GetPayoutFileByMessageID(ctx context.Context, messageId string) (payout.File, error) {
+	return r.getPayoutFile(ctx, "message_id", messageId)
+}
+
+func (r *PgRepository) getPayoutFile(ctx context.Context, column string, value any) (payout.File, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT id, message_id, payout_count, control_sum, content, created_at FROM payout_files WHERE " + column + " = $1"
+
+	var f payout.File
+	err := tx.QueryRow(ctx, query, value).Scan(&f.ID, &f.MessageID, &f.Payouts, &f.ControlSum, &f.Content, &f.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payout.File{}, errors.New("payout file not found")
+		}
+		return payout.File{}, err
+	}
+	return f, nil
+}
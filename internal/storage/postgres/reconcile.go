@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"project/internal/reconcile"
+)
+
+func (r *PgRepository) ReconcileTotals(ctx context.Context) (reconcile.Totals, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT
+			(SELECT count(*) FROM wallets),
+			(SELECT COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0) FROM ledger_entries),
+			(SELECT COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0) FROM ledger_entries),
+			(SELECT COALESCE(SUM(balance), 0) FROM wallets) + (SELECT COALESCE(SUM(balance), 0) FROM wallet_shards)`
+
+	var t reconcile.Totals
+	err := tx.QueryRow(ctx, query).Scan(&t.Wallets, &t.Credits, &t.Debits, &t.Balance)
+	return t, err
+}
+
+// FindDiscrepancies returns up to limit wallets that do not reconcile, and
+// how many there are in total.
+func (r *PgRepository) FindDiscrepancies(ctx context.Context, limit int) ([]reconcile.Discrepancy, int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `WITH ledger AS (
+			SELECT wallet_id, SUM(amount) AS total FROM ledger_entries GROUP BY wallet_id
+		), shards AS (
+			SELECT wallet_id, SUM(balance) AS total FROM wallet_shards GROUP BY wallet_id
+		), snapshot AS (
+			SELECT DISTINCT ON (wallet_id) wallet_id, day, closing_balance
+			FROM daily_snapshots
+			ORDER BY wallet_id, day DESC
+		), ledger_at_snapshot AS (
+			SELECT s.wallet_id, SUM(l.amount) AS total
+			FROM snapshot s
+			JOIN ledger_entries l ON l.wallet_id = s.wallet_id AND l.created_at < (s.day + 1)::timestamp AT TIME ZONE 'UTC'
+			GROUP BY s.wallet_id
+		), checked AS (
+			SELECT w.wallet_id,
+				w.balance + COALESCE(sh.total, 0) AS balance,
+				COALESCE(l.total, 0) AS ledger_sum,
+				s.day, s.closing_balance,
+				COALESCE(ls.total, 0) AS ledger_at_snapshot
+			FROM wallets w
+			LEFT JOIN ledger l ON l.wallet_id = w.wallet_id
+			LEFT JOIN shards sh ON sh.wallet_id = w.wallet_id
+			LEFT JOIN snapshot s ON s.wallet_id = w.wallet_id
+			LEFT JOIN ledger_at_snapshot ls ON ls.wallet_id = w.wallet_id
+		)
+		SELECT wallet_id, balance, ledger_sum, day, COALESCE(closing_balance, 0), ledger_at_snapshot, count(*) OVER ()
+		FROM checked
+		WHERE balance <> ledger_sum OR closing_balance <> ledger_at_snapshot
+		ORDER BY wallet_id
+		LIMIT $1`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		found []reconcile.Discrepancy
+		count int
+	)
+	for rows.Next() {
+		var d reconcile.Discrepancy
+		if err := rows.Scan(&d.WalletID, &d.Balance, &d.LedgerSum, &d.SnapshotDay, &d.SnapshotClosing, &d.LedgerAtSnapshot, &count); err != nil {
+			return nil, 0, err
+		}
+		if d.Balance != d.LedgerSum {
+			d.Kinds = append(d.Kinds, reconcile.BalanceMismatch)
+		}
+		if d.SnapshotDay != nil && d.SnapshotClosing != d.LedgerAtSnapshot {
+			d.Kinds = append(d.Kinds, reconcile.SnapshotMismatch)
+		}
+		found = append(found, d)
+	}
+	return found, count, rows.Err()
+}
+
+func (r *PgRepository) InsertReconciliationRun(ctx context.Context, report reconcile.Report) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO reconciliation_runs (status, started_at, finished_at, wallets_checked, total_credits,
+			total_debits, total_balance, discrepancy_count, discrepancies, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''))
+		RETURNING id`
+
+	discrepancies := report.Discrepancies
+	if discrepancies == nil {
+		discrepancies = []reconcile.Discrepancy{}
+	}
+	payload, err := json.Marshal(discrepancies)
+	if err != nil {
+		return 0, err
+	}
+
+	t := report.Totals
+	var id int64
+	err = tx.QueryRow(ctx, query, string(report.Status), report.StartedAt, report.FinishedAt, t.Wallets, t.Credits,
+		t.Debits, t.Balance, report.DiscrepancyCount, string(payload), report.Error).Scan(&id)
+	return id, err
+}
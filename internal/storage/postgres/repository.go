@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"project/internal/outbox"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgconn"
@@ -52,7 +55,9 @@ func (r *PgRepository) GetById(ctx context.Context, walletId uuid.UUID) (int64,
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
-	query := "SELECT balance FROM wallets WHERE wallet_id = $1"
+	query := `SELECT w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)
+		FROM wallets w
+		WHERE w.wallet_id = $1`
 	row := tx.QueryRow(ctx, query, walletId)
 
 	var balance int64
@@ -80,9 +85,13 @@ func (r *PgRepository) LockBalance(ctx context.Context, walletId uuid.UUID) erro
 
 func (r *PgRepository) UpdateBalance(ctx context.Context, walletId uuid.UUID, balanceDiff int64) error {
 	tx := r.txManager.GetQueryEngine(ctx)
-	query := "UPDATE wallets SET balance = balance + $2 WHERE wallet_id = $1"
+	query := "UPDATE wallets SET balance = balance + $2, min_balance = " + minBalanceAfter("balance", "$2") +
+		", version = version + 1 WHERE wallet_id = $1"
 	tag, err := tx.Exec(ctx, query, walletId, balanceDiff)
 	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "22003" {
+			return errors.New("balance limit exceeded")
+		}
 		return err
 	}
 	if tag.RowsAffected() == 0 {
@@ -90,3 +99,65 @@ func (r *PgRepository) UpdateBalance(ctx context.Context, walletId uuid.UUID, ba
 	}
 	return nil
 }
+
+func (r *PgRepository) InsertOutboxEvent(ctx context.Context, event outbox.Event) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "INSERT INTO outbox (event_type, wallet_id, payload) VALUES ($1, $2, $3)"
+	_, err := tx.Exec(ctx, query, string(event.Type), event.WalletID, []byte(event.Payload))
+	return err
+}
+
+// ClaimPendingEvents leases up to limit due events by moving their
+// next_attempt_at past now() + lease, so other relays skip them while they
+// are published. Recording the outcome sets next_attempt_at again.
+func (r *PgRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE outbox SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, event_type, wallet_id, payload, attempts, created_at`
+
+	rows, err := tx.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []outbox.Event
+	for rows.Next() {
+		var (
+			event     outbox.Event
+			eventType string
+			payload   []byte
+		)
+		if err := rows.Scan(&event.ID, &eventType, &event.WalletID, &payload, &event.Attempts, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Type = outbox.EventType(eventType)
+		event.Payload = payload
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *PgRepository) MarkEventDelivered(ctx context.Context, id int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "UPDATE outbox SET delivered_at = now(), attempts = attempts + 1, last_error = NULL WHERE id = $1"
+	_, err := tx.Exec(ctx, query, id)
+	return err
+}
+
+func (r *PgRepository) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, cause string) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1"
+	_, err := tx.Exec(ctx, query, id, nextAttemptAt, cause)
+	return err
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+type Jitter string
+
+const (
+	JitterNone  Jitter = "none"
+	JitterFull  Jitter = "full"
+	JitterEqual Jitter = "equal"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      Jitter
+	// RetryableCodes lists the SQLSTATEs worth another attempt.
+	RetryableCodes []string
+}
+
+var DefaultRetryableCodes = []string{
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"55P03", // lock_not_available
+}
+
+var (
+	DefaultSerializableRetry = RetryPolicy{
+		MaxAttempts:    5,
+		BaseDelay:      10 * time.Millisecond,
+		MaxDelay:       time.Second,
+		Jitter:         JitterEqual,
+		RetryableCodes: DefaultRetryableCodes,
+	}
+	DefaultReadOnlyRetry = RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      5 * time.Millisecond,
+		MaxDelay:       100 * time.Millisecond,
+		Jitter:         JitterEqual,
+		RetryableCodes: DefaultRetryableCodes,
+	}
+)
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy overrides the retry policy of the next outermost
+// transaction started with ctx.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func policyFor(ctx context.Context, fallback RetryPolicy) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return fallback
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	for _, code := range p.RetryableCodes {
+		if pgErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff is the delay before retrying after the given zero-based attempt:
+// BaseDelay doubled per attempt, capped at MaxDelay, then jittered.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	switch p.Jitter {
+	case JitterFull:
+		return time.Duration(rand.Int63n(int64(delay) + 1))
+	case JitterEqual:
+		half := delay / 2
+		return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+	default:
+		return delay
+	}
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"project/internal/contention"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Jitter: JitterNone}
+
+	require.Equal(t, 10*time.Millisecond, p.backoff(0))
+	require.Equal(t, 20*time.Millisecond, p.backoff(1))
+	require.Equal(t, 40*time.Millisecond, p.backoff(2))
+	require.Equal(t, 50*time.Millisecond, p.backoff(3))
+	require.Equal(t, 50*time.Millisecond, p.backoff(60))
+
+	p.Jitter = JitterEqual
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1)
+		require.True(t, d >= 10*time.Millisecond && d <= 20*time.Millisecond, d)
+	}
+
+	p.Jitter = JitterFull
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1)
+		require.True(t, d >= 0 && d <= 20*time.Millisecond, d)
+	}
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	p := DefaultSerializableRetry
+
+	require.True(t, p.retryable(&pgconn.PgError{Code: "40001"}))
+	require.True(t, p.retryable(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "55P03"})))
+	require.False(t, p.retryable(&pgconn.PgError{Code: "23505"}))
+	require.False(t, p.retryable(errors.New("wallet not found")))
+}
+
+func TestRetry_WrapsLastCause(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 3, RetryableCodes: DefaultRetryableCodes}
+	cause := &pgconn.PgError{Code: "40001"}
+
+	calls, committed, rolledBack := 0, 0, 0
+	err := tm.retry(context.Background(), policy, func(hooks *txHooks) error {
+		calls++
+		hooks.onCommit = append(hooks.onCommit, func() { committed++ })
+		hooks.onRollback = append(hooks.onRollback, func(error) { rolledBack++ })
+		return cause
+	})
+
+	require.Equal(t, 3, calls)
+	require.ErrorIs(t, err, cause)
+	require.EqualError(t, err, "transaction failed after 3 attempts: "+cause.Error())
+	require.Equal(t, 0, committed)
+	require.Equal(t, 1, rolledBack)
+}
+
+func TestRetry_RunsOnceWithoutAttempts(t *testing.T) {
+	tm := &TxManager{}
+
+	for _, policy := range []RetryPolicy{{}, {MaxAttempts: -1}} {
+		calls := 0
+		err := tm.retry(context.Background(), policy, func(hooks *txHooks) error {
+			calls++
+			return errors.New("wallet not found")
+		})
+		require.Equal(t, 1, calls)
+		require.EqualError(t, err, "wallet not found")
+	}
+}
+
+func TestRetry_KeepsNonRetryableErrorAfterRetry(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 3, RetryableCodes: DefaultRetryableCodes}
+
+	calls := 0
+	err := tm.retry(context.Background(), policy, func(hooks *txHooks) error {
+		calls++
+		if calls == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return errors.New("not enough balance: 5 < 10")
+	})
+
+	require.Equal(t, 2, calls)
+	require.EqualError(t, err, "not enough balance: 5 < 10")
+}
+
+func TestRetry_StopsOnContextCancel(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, RetryableCodes: DefaultRetryableCodes}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- tm.retry(ctx, policy, func(hooks *txHooks) error {
+			calls++
+			return &pgconn.PgError{Code: "40P01"}
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		require.Equal(t, 1, calls)
+	case <-time.After(time.Second):
+		t.Fatal("retry ignored context cancellation")
+	}
+}
+
+func TestRetry_HooksOnlyFromFinalAttempt(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 3, RetryableCodes: DefaultRetryableCodes}
+
+	calls, committed, rolledBack := 0, 0, 0
+	err := tm.retry(context.Background(), policy, func(hooks *txHooks) error {
+		calls++
+		hooks.onCommit = append(hooks.onCommit, func() { committed++ })
+		hooks.onRollback = append(hooks.onRollback, func(error) { rolledBack++ })
+		if calls < 2 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, committed)
+	require.Equal(t, 0, rolledBack)
+}
+
+func TestRetry_LockTimeoutBecomesBusy(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 2, RetryableCodes: DefaultRetryableCodes}
+
+	err := tm.retry(context.Background(), policy, func(hooks *txHooks) error {
+		return &pgconn.PgError{Code: "55P03"}
+	})
+
+	var busy *contention.BusyError
+	require.ErrorAs(t, err, &busy)
+	require.Equal(t, time.Second, busy.RetryAfter)
+}
+
+func TestRetry_DeadlineKeepsBusyCause(t *testing.T) {
+	tm := &TxManager{}
+	policy := RetryPolicy{MaxAttempts: 5, RetryableCodes: DefaultRetryableCodes}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := tm.retry(ctx, policy, func(hooks *txHooks) error {
+		calls++
+		if calls == 1 {
+			return &pgconn.PgError{Code: "55P03"}
+		}
+		cancel()
+		return context.Canceled
+	})
+
+	var busy *contention.BusyError
+	require.ErrorAs(t, err, &busy)
+	require.Equal(t, 2, calls)
+}
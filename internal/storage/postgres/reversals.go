@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/ledger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// minBalanceAfter is the floor a wallet's balance is checked against after
+// it changes by diff. Credits are always accepted, even if the wallet stays
+// negative after an overdraft; debits may not take it below zero, or below
+// where it already was.
+func minBalanceAfter(balance, diff string) string {
+	return fmt.Sprintf("LEAST(0, %s + GREATEST(%s, 0))", balance, diff)
+}
+
+// OverdrawBalance debits the wallet even if that leaves it negative.
+func (r *PgRepository) OverdrawBalance(ctx context.Context, walletId uuid.UUID, amount int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE wallets SET balance = balance - $2, min_balance = LEAST(min_balance, balance - $2), version = version + 1
+		WHERE wallet_id = $1`
+	tag, err := tx.Exec(ctx, query, walletId, amount)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("wallet not found")
+	}
+	return nil
+}
+
+// GetLedgerEntryForUpdate locks the entry, so that concurrent reversals of
+// it are applied one after the other.
+func (r *PgRepository) GetLedgerEntryForUpdate(ctx context.Context, id int64) (ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + ledgerColumns + " FROM ledger_entries WHERE id = $1 FOR UPDATE"
+	entry, err := scanLedgerEntry(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ledger.Entry{}, errors.New("operation not found")
+		}
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
+
+// ReversedAmount is how much of the entry earlier reversals have undone.
+func (r *PgRepository) ReversedAmount(ctx context.Context, id int64) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	var reversed int64
+	err := tx.QueryRow(ctx, "SELECT COALESCE(SUM(ABS(amount)), 0) FROM ledger_entries WHERE reversal_of = $1", id).Scan(&reversed)
+	return reversed, err
+}
+
+// InsertReversalEntry must run after the balance update, like
+// InsertLedgerEntry; amount is signed.
+func (r *PgRepository) InsertReversalEntry(ctx context.Context, original ledger.Entry, amount int64) (ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after, reversal_of)
+		SELECT w.wallet_id, $2, $3,
+			w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0),
+			$4
+		FROM wallets w
+		WHERE w.wallet_id = $1
+		RETURNING ` + ledgerColumns
+
+	entry, err := scanLedgerEntry(tx.QueryRow(ctx, query, original.WalletID, string(ledger.Reversal), amount, original.ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ledger.Entry{}, errors.New("wallet not found")
+		}
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func (r *PgRepository) GetShardCount(ctx context.Context, walletId uuid.UUID) (int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	var shards int
+	if err := tx.QueryRow(ctx, "SELECT shard_count FROM wallets WHERE wallet_id = $1", walletId).Scan(&shards); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New("wallet not found")
+		}
+		return 0, err
+	}
+	return shards, nil
+}
+
+// DepositToShard credits a single shard row without touching the wallets
+// row, so concurrent deposits to a sharded wallet only contend per shard. It
+// reports false when the shard does not exist (the wallet was resized).
+func (r *PgRepository) DepositToShard(ctx context.Context, walletId uuid.UUID, shard int, amount int64) (bool, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE wallet_shards s SET balance = s.balance + $3, version = s.version + 1
+		FROM wallets w
+		WHERE s.wallet_id = $1 AND s.shard_no = $2 AND w.wallet_id = s.wallet_id AND w.status = 'ACTIVE'`
+	tag, err := tx.Exec(ctx, query, walletId, shard, amount)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "22003" {
+			return false, errors.New("balance limit exceeded")
+		}
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnsureMainBalance makes sure the wallets row alone covers need, sweeping
+// the shards into it if it does not. The wallet row must already be locked.
+func (r *PgRepository) EnsureMainBalance(ctx context.Context, walletId uuid.UUID, need int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var main int64
+	if err := tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE wallet_id = $1", walletId).Scan(&main); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("wallet not found")
+		}
+		return err
+	}
+	if main >= need {
+		return nil
+	}
+
+	_, err := r.CompactShards(ctx, walletId)
+	return err
+}
+
+// CompactShards folds every shard balance back into the wallets row. The
+// total balance is unchanged, so no ledger entry is written. The wallets row
+// is locked before the shards, in the same order withdrawals use.
+func (r *PgRepository) CompactShards(ctx context.Context, walletId uuid.UUID) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	if err := r.LockBalance(ctx, walletId); err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	query := `SELECT COALESCE(SUM(balance), 0) FROM (
+			SELECT balance FROM wallet_shards WHERE wallet_id = $1 ORDER BY shard_no FOR UPDATE
+		) s`
+	if err := tx.QueryRow(ctx, query, walletId).Scan(&moved); err != nil {
+		return 0, err
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE wallet_shards SET balance = 0 WHERE wallet_id = $1 AND balance <> 0", walletId); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE wallets SET balance = balance + $2 WHERE wallet_id = $1", walletId, moved); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func (r *PgRepository) ResizeShards(ctx context.Context, walletId uuid.UUID, shards int) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	if _, err := r.CompactShards(ctx, walletId); err != nil {
+		return err
+	}
+	// The wallet's version is its own plus its shards', so the versions of
+	// dropped shards move to the wallet row to keep the sum from going back.
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET version = version + COALESCE(
+			(SELECT SUM(version) FROM wallet_shards WHERE wallet_id = $1 AND shard_no >= $2), 0)
+		WHERE wallet_id = $1`, walletId, shards); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM wallet_shards WHERE wallet_id = $1 AND shard_no >= $2", walletId, shards); err != nil {
+		return err
+	}
+	query := `INSERT INTO wallet_shards (wallet_id, shard_no)
+		SELECT $1, generate_series(0, $2::int - 1)
+		ON CONFLICT (wallet_id, shard_no) DO NOTHING`
+	if _, err := tx.Exec(ctx, query, walletId, shards); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, "UPDATE wallets SET shard_count = $2 WHERE wallet_id = $1", walletId, shards)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("wallet not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) ListShardedWallets(ctx context.Context, afterId uuid.UUID, limit int) ([]uuid.UUID, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT DISTINCT wallet_id FROM wallet_shards
+		WHERE wallet_id > $1 AND balance <> 0
+		ORDER BY wallet_id
+		LIMIT $2`
+
+	rows, err := tx.Query(ctx, query, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// openingBalanceExpr is the balance of wallet w at the start of the UTC
+// day $2, which must be a midnight: the previous day's closing snapshot if
+// there is one, otherwise the latest checkpoint before $2 plus the entries
+// after it.
+const openingBalanceExpr = `COALESCE(
+		(SELECT d.closing_balance FROM daily_snapshots d
+			WHERE d.wallet_id = w.wallet_id AND d.day = ` + utcDay2 + ` - 1),
+		(SELECT COALESCE(c.balance, 0) + COALESCE((SELECT SUM(l.amount) FROM ledger_entries l
+				WHERE l.wallet_id = w.wallet_id AND l.created_at > COALESCE(c.at, '-infinity') AND l.created_at < $2), 0)
+			FROM (SELECT 1) one
+			LEFT JOIN LATERAL (SELECT at, balance FROM balance_checkpoints
+				WHERE wallet_id = w.wallet_id AND at < $2
+				ORDER BY at DESC LIMIT 1) c ON true))`
+
+const utcDay2 = "($2::timestamptz AT TIME ZONE 'UTC')::date"
+
+func (r *PgRepository) GetOpeningBalance(ctx context.Context, walletId uuid.UUID, at time.Time) (int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + openingBalanceExpr + " FROM wallets w WHERE w.wallet_id = $1"
+
+	var balance int64
+	if err := tx.QueryRow(ctx, query, walletId, at).Scan(&balance); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New("wallet not found")
+		}
+		return 0, err
+	}
+	return balance, nil
+}
+
+// ListEntriesBetween returns entries created in [from, to) in time order.
+func (r *PgRepository) ListEntriesBetween(ctx context.Context, walletId uuid.UUID, from, to time.Time, limit int) ([]ledger.Entry, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + ledgerColumns + ` FROM ledger_entries
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id
+		LIMIT $4`
+
+	rows, err := tx.Query(ctx, query, walletId, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ledger.Entry
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *PgRepository) LatestSnapshotDay(ctx context.Context) (time.Time, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var day *time.Time
+	if err := tx.QueryRow(ctx, "SELECT MAX(day) FROM daily_snapshots").Scan(&day); err != nil {
+		return time.Time{}, err
+	}
+	if day == nil {
+		return time.Time{}, nil
+	}
+	return *day, nil
+}
+
+// InsertDailySnapshots snapshots day for up to limit wallets after afterId
+// that existed by the end of it, and returns the last wallet it looked at.
+func (r *PgRepository) InsertDailySnapshots(ctx context.Context, afterId uuid.UUID, day time.Time, limit int) (uuid.UUID, int, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	end := day.AddDate(0, 0, 1)
+
+	var last uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(wallet_id), '00000000-0000-0000-0000-000000000000')
+		FROM (SELECT wallet_id FROM wallets WHERE wallet_id > $1 AND created_at < $3 ORDER BY wallet_id LIMIT $2) page`,
+		afterId, limit, end).Scan(&last); err != nil {
+		return uuid.Nil, 0, err
+	}
+	if last == uuid.Nil {
+		return uuid.Nil, 0, nil
+	}
+
+	query := `INSERT INTO daily_snapshots (wallet_id, day, opening_balance, total_credits, total_debits, closing_balance, entry_count)
+		SELECT s.wallet_id, ` + utcDay2 + `, s.opening, s.credits, s.debits,
+			s.opening + s.credits - s.debits, s.entries
+		FROM (
+			SELECT w.wallet_id, ` + openingBalanceExpr + ` AS opening,
+				COALESCE(SUM(l.amount) FILTER (WHERE l.amount > 0), 0) AS credits,
+				COALESCE(-SUM(l.amount) FILTER (WHERE l.amount < 0), 0) AS debits,
+				count(l.id) AS entries
+			FROM wallets w
+			LEFT JOIN ledger_entries l ON l.wallet_id = w.wallet_id AND l.created_at >= $2 AND l.created_at < $3
+			WHERE w.wallet_id > $1 AND w.wallet_id <= $4 AND w.created_at < $3
+			GROUP BY w.wallet_id
+		) s
+		ON CONFLICT DO NOTHING`
+
+	tag, err := tx.Exec(ctx, query, afterId, day, end, last)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+	return last, int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// LockActive is LockBalance for the operations a FROZEN or CLOSED wallet
+// must refuse.
+func (r *PgRepository) LockActive(ctx context.Context, walletId uuid.UUID) error {
+	status, err := r.lockStatus(ctx, walletId)
+	if err != nil {
+		return err
+	}
+	if status != wallet.Active {
+		return errors.New("wallet is " + string(status))
+	}
+	return nil
+}
+
+// LockOpen is LockBalance for the operations only a CLOSED wallet refuses:
+// money coming back to a FROZEN wallet stays there until it is unfrozen.
+func (r *PgRepository) LockOpen(ctx context.Context, walletId uuid.UUID) error {
+	status, err := r.lockStatus(ctx, walletId)
+	if err != nil {
+		return err
+	}
+	if status == wallet.Closed {
+		return errors.New("wallet is " + string(status))
+	}
+	return nil
+}
+
+func (r *PgRepository) lockStatus(ctx context.Context, walletId uuid.UUID) (wallet.Status, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT status FROM wallets WHERE wallet_id = $1 FOR UPDATE"
+	var status string
+	if err := tx.QueryRow(ctx, query, walletId).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("wallet not found")
+		}
+		return "", err
+	}
+	return wallet.Status(status), nil
+}
+
+func (r *PgRepository) UpdateWalletStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	tag, err := tx.Exec(ctx, "UPDATE wallets SET status = $2 WHERE wallet_id = $1", walletId, string(status))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("wallet not found")
+	}
+	return nil
+}
+
+// HasOpenPayouts reports whether any of the wallet's payouts may still be
+// rejected by the bank and refunded.
+func (r *PgRepository) HasOpenPayouts(ctx context.Context, walletId uuid.UUID) (bool, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT EXISTS (SELECT 1 FROM payouts
+		WHERE wallet_id = $1 AND status IN ('PENDING', 'EXPORTED', 'ACCEPTED'))`
+	var open bool
+	err := tx.QueryRow(ctx, query, walletId).Scan(&open)
+	return open, err
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/contention"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Timeouts are applied with SET LOCAL at the start of every transaction. A
+// zero field keeps the default; a negative one disables that timeout.
+type Timeouts struct {
+	Lock              time.Duration
+	Statement         time.Duration
+	IdleInTransaction time.Duration
+}
+
+type timeoutsKey struct{}
+
+// WithTimeouts overrides the transaction timeouts for an operation.
+func WithTimeouts(ctx context.Context, t Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, t)
+}
+
+// HasOverrides reports whether ctx carries a retry policy or timeouts of its
+// own, which only a transaction started with ctx honours.
+func HasOverrides(ctx context.Context) bool {
+	_, retry := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+	_, timeouts := ctx.Value(timeoutsKey{}).(Timeouts)
+	return retry || timeouts
+}
+
+func (tm *TxManager) timeoutsFor(ctx context.Context) Timeouts {
+	t := tm.timeouts
+	if override, ok := ctx.Value(timeoutsKey{}).(Timeouts); ok {
+		if override.Lock != 0 {
+			t.Lock = override.Lock
+		}
+		if override.Statement != 0 {
+			t.Statement = override.Statement
+		}
+		if override.IdleInTransaction != 0 {
+			t.IdleInTransaction = override.IdleInTransaction
+		}
+	}
+	return t
+}
+
+func (tm *TxManager) setTimeouts(ctx context.Context, tx pgx.Tx) error {
+	query, args := timeoutsQuery(tm.timeoutsFor(ctx))
+	if query == "" {
+		return nil
+	}
+	_, err := tx.Exec(ctx, query, args...)
+	return err
+}
+
+// timeoutsQuery sets only the timeouts t gives, so the server default
+// stays in force for the zero ones.
+func timeoutsQuery(t Timeouts) (string, []any) {
+	var (
+		sets []string
+		args []any
+	)
+	for _, s := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"lock_timeout", t.Lock},
+		{"statement_timeout", t.Statement},
+		{"idle_in_transaction_session_timeout", t.IdleInTransaction},
+	} {
+		if s.d == 0 {
+			continue
+		}
+		args = append(args, millis(s.d))
+		sets = append(sets, fmt.Sprintf("set_config('%s', $%d, true)", s.name, len(args)))
+	}
+	if len(sets) == 0 {
+		return "", nil
+	}
+	return "SELECT " + strings.Join(sets, ", "), args
+}
+
+func millis(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// busyRetryAfter is what clients are told to wait after a lock timeout.
+const busyRetryAfter = time.Second
+
+// classify turns a lock timeout, whether retries ran out or were not
+// allowed, into a contention.BusyError.
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+		return &contention.BusyError{RetryAfter: busyRetryAfter, Cause: err}
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTimeoutsQuery(t *testing.T) {
+	query, args := timeoutsQuery(Timeouts{})
+	require.Empty(t, query)
+	require.Empty(t, args)
+
+	query, args = timeoutsQuery(Timeouts{Statement: 5 * time.Second})
+	require.Equal(t, "SELECT set_config('statement_timeout', $1, true)", query)
+	require.Equal(t, []any{"5000"}, args)
+
+	query, args = timeoutsQuery(Timeouts{Lock: time.Second, IdleInTransaction: -1})
+	require.Equal(t, "SELECT set_config('lock_timeout', $1, true), set_config('idle_in_transaction_session_timeout', $2, true)", query)
+	require.Equal(t, []any{"1000", "0"}, args)
+}
+
+func TestTimeoutsFor_Override(t *testing.T) {
+	tm := NewTxManager(nil).WithTimeouts(Timeouts{Lock: time.Second, Statement: 5 * time.Second, IdleInTransaction: 10 * time.Second})
+
+	ctx := WithTimeouts(context.Background(), Timeouts{IdleInTransaction: -1})
+	require.Equal(t, Timeouts{Lock: time.Second, Statement: 5 * time.Second, IdleInTransaction: -1}, tm.timeoutsFor(ctx))
+	require.Equal(t, 10*time.Second, tm.timeoutsFor(context.Background()).IdleInTransaction)
+}
+
+func TestHasOverrides(t *testing.T) {
+	require.False(t, HasOverrides(context.Background()))
+	require.True(t, HasOverrides(WithTimeouts(context.Background(), Timeouts{Lock: time.Second})))
+	require.True(t, HasOverrides(WithRetryPolicy(context.Background(), DefaultSerializableRetry)))
+}
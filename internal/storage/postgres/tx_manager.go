@@ -2,12 +2,10 @@ package postgres
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"math/rand"
+	"project/internal/lsn"
 	"time"
 
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -16,10 +14,42 @@ type txManagerKey struct{}
 
 type TxManager struct {
 	pool *pgxpool.Pool
+
+	replica        *pgxpool.Pool
+	replicaMaxWait time.Duration
+
+	serializableRetry RetryPolicy
+	readOnlyRetry     RetryPolicy
+
+	timeouts Timeouts
 }
 
 func NewTxManager(pool *pgxpool.Pool) *TxManager {
-	return &TxManager{pool: pool}
+	return &TxManager{
+		pool:              pool,
+		serializableRetry: DefaultSerializableRetry,
+		readOnlyRetry:     DefaultReadOnlyRetry,
+	}
+}
+
+// WithReplica routes RunReadOnly to a streaming replica. Reads carrying a
+// required LSN wait up to maxWait for the replica to replay it and otherwise
+// go to the primary.
+func (tm *TxManager) WithReplica(replica *pgxpool.Pool, maxWait time.Duration) *TxManager {
+	tm.replica = replica
+	tm.replicaMaxWait = maxWait
+	return tm
+}
+
+func (tm *TxManager) WithRetryPolicies(serializable, readOnly RetryPolicy) *TxManager {
+	tm.serializableRetry = serializable
+	tm.readOnlyRetry = readOnly
+	return tm
+}
+
+func (tm *TxManager) WithTimeouts(t Timeouts) *TxManager {
+	tm.timeouts = t
+	return tm
 }
 
 func (tm *TxManager) RunSerializable(ctx context.Context, fn func(ctxTx context.Context) error) error {
@@ -27,25 +57,111 @@ func (tm *TxManager) RunSerializable(ctx context.Context, fn func(ctxTx context.
 		IsoLevel:   pgx.Serializable,
 		AccessMode: pgx.ReadWrite,
 	}
-	return tm.beginWithRetry(ctx, options, fn)
+	if outer := currentTx(ctx); outer != nil {
+		return tm.savepoint(ctx, outer, options, fn)
+	}
+	return tm.retry(ctx, policyFor(ctx, tm.serializableRetry), func(hooks *txHooks) error {
+		return tm.beginFunc(ctx, options, hooks, fn)
+	})
 }
 
-func (tm *TxManager) RunReadUncommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
+func (tm *TxManager) RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
 	options := pgx.TxOptions{
-		IsoLevel:   pgx.ReadUncommitted,
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	}
+	if outer := currentTx(ctx); outer != nil {
+		return tm.savepoint(ctx, outer, options, fn)
+	}
+	return tm.once(func(hooks *txHooks) error {
+		return tm.beginFunc(ctx, options, hooks, fn)
+	})
+}
+
+// RunReadOnly gives fn a consistent REPEATABLE READ snapshot for all of its
+// queries. It is served by the replica when one is configured.
+func (tm *TxManager) RunReadOnly(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	options := pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
 		AccessMode: pgx.ReadOnly,
 	}
-	return tm.beginFunc(ctx, options, fn)
+	if outer := currentTx(ctx); outer != nil {
+		return tm.savepoint(ctx, outer, options, fn)
+	}
+	return tm.retry(ctx, policyFor(ctx, tm.readOnlyRetry), func(hooks *txHooks) error {
+		if tm.replica == nil || !tm.replicaCaughtUp(ctx, lsn.Required(ctx)) {
+			return tm.beginFunc(ctx, options, hooks, fn)
+		}
+		return tm.beginOn(ctx, tm.replica, options, hooks, fn)
+	})
+}
+
+// RunReadOnlyDeferrable waits for a snapshot that cannot conflict with any
+// concurrent serializable write, for long reports. Standbys do not support
+// it, so it always runs on the primary.
+func (tm *TxManager) RunReadOnlyDeferrable(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	options := pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: pgx.Deferrable,
+	}
+	if outer := currentTx(ctx); outer != nil {
+		return tm.savepoint(ctx, outer, options, fn)
+	}
+	return tm.retry(ctx, policyFor(ctx, tm.readOnlyRetry), func(hooks *txHooks) error {
+		return tm.beginFunc(ctx, options, hooks, fn)
+	})
+}
+
+func (tm *TxManager) replicaCaughtUp(ctx context.Context, required string) bool {
+	if required == "" {
+		return true
+	}
+
+	const pollInterval = 5 * time.Millisecond
+	query := "SELECT COALESCE(pg_last_wal_replay_lsn(), pg_current_wal_lsn()) >= $1::pg_lsn"
+	deadline := time.Now().Add(tm.replicaMaxWait)
+	for {
+		var ok bool
+		if err := tm.replica.QueryRow(ctx, query, required).Scan(&ok); err != nil {
+			return false
+		}
+		if ok {
+			return true
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (tm *TxManager) beginFunc(ctx context.Context, txOptions pgx.TxOptions, hooks *txHooks, fn func(ctxTx context.Context) error) error {
+	if err := tm.beginOn(ctx, tm.pool, txOptions, hooks, fn); err != nil {
+		return err
+	}
+	if txOptions.AccessMode != pgx.ReadOnly {
+		tm.trackLSN(ctx)
+	}
+	return nil
 }
 
-func (tm *TxManager) beginFunc(ctx context.Context, txOptions pgx.TxOptions, fn func(ctxTx context.Context) error) error {
-	tx, err := tm.pool.BeginTx(ctx, txOptions)
+func (tm *TxManager) beginOn(ctx context.Context, pool *pgxpool.Pool, txOptions pgx.TxOptions, hooks *txHooks, fn func(ctxTx context.Context) error) error {
+	tx, err := pool.BeginTx(ctx, txOptions)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	ctx = context.WithValue(ctx, txManagerKey{}, tx)
+	if err := tm.setTimeouts(ctx, tx); err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, txManagerKey{}, &txState{tx: tx, options: txOptions, hooks: hooks})
 	if err := fn(ctx); err != nil {
 		return err
 	}
@@ -53,32 +169,79 @@ func (tm *TxManager) beginFunc(ctx context.Context, txOptions pgx.TxOptions, fn
 	return tx.Commit(ctx)
 }
 
-func (tm *TxManager) beginWithRetry(ctx context.Context, txOptions pgx.TxOptions, fn func(ctxTx context.Context) error) error {
-	const maxRetries = 5
-	baseDelay := 10 * time.Millisecond
+// trackLSN hands the primary's WAL position after a committed write to the
+// request, so the client can ask for read-your-writes on the replica.
+func (tm *TxManager) trackLSN(ctx context.Context) {
+	tracker := lsn.TrackerFrom(ctx)
+	if tm.replica == nil || tracker == nil {
+		return
+	}
+	var pos string
+	if err := tm.pool.QueryRow(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&pos); err == nil {
+		tracker.Set(pos)
+	}
+}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		err := tm.beginFunc(ctx, txOptions, fn)
+// retry runs each attempt with fresh hooks, so hooks registered by an
+// attempt that is retried are dropped and only the final outcome fires. fn
+// always runs at least once, whatever the policy says. Only a retryable
+// error that outlasted every attempt is wrapped; any other error is
+// returned as it is, for callers that match on its text.
+func (tm *TxManager) retry(ctx context.Context, policy RetryPolicy, run func(hooks *txHooks) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err, retried error
+	attempts := 0
+	for attempts < maxAttempts {
+		hooks := &txHooks{}
+		err = run(hooks)
+		attempts++
 		if err == nil {
+			hooks.committed()
 			return nil
 		}
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "40001" || pgErr.Code == "40P01" {
-				jitter := time.Duration(rand.Intn(10)) * time.Millisecond
-				time.Sleep(time.Duration(1<<attempt)*baseDelay + jitter)
-				continue
-			}
+		if ctx.Err() != nil && retried != nil {
+			// The deadline cut a retry short; what kept failing is the
+			// more useful cause.
+			err = retried
 		}
-		return err
+		if !policy.retryable(err) || attempts == maxAttempts || ctx.Err() != nil {
+			hooks.rolledBack(err)
+			break
+		}
+		retried = err
+
+		timer := time.NewTimer(policy.backoff(attempts - 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			hooks.rolledBack(err)
+			return fmt.Errorf("transaction retry aborted after %d attempts: %w", attempts, classify(err))
+		case <-timer.C:
+		}
+	}
+	if attempts > 1 && policy.retryable(err) {
+		return fmt.Errorf("transaction failed after %d attempts: %w", attempts, classify(err))
+	}
+	return classify(err)
+}
+
+func (tm *TxManager) once(run func(hooks *txHooks) error) error {
+	hooks := &txHooks{}
+	if err := run(hooks); err != nil {
+		hooks.rolledBack(err)
+		return classify(err)
 	}
-	return fmt.Errorf("transaction failed after retries")
+	hooks.committed()
+	return nil
 }
 
 func (tm *TxManager) GetQueryEngine(ctx context.Context) QueryEngine {
-	tx, ok := ctx.Value(txManagerKey{}).(QueryEngine)
-	if ok && tx != nil {
-		return tx
+	if state := currentTx(ctx); state != nil {
+		return state.tx
 	}
 	return tm.pool
 }
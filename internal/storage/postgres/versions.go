@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// versionExpr is a wallet's version: its own counter plus its shards', so
+// deposits to shards change it without touching the wallets row.
+const versionExpr = "w.version + COALESCE((SELECT SUM(s.version) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)"
+
+func (r *PgRepository) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0),
+			` + versionExpr + `
+		FROM wallets w
+		WHERE w.wallet_id = $1`
+
+	var balance, version int64
+	if err := tx.QueryRow(ctx, query, walletId).Scan(&balance, &version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, errors.New("wallet not found")
+		}
+		return 0, 0, err
+	}
+	return balance, version, nil
+}
+
+// UpdateBalanceIfVersion is the optimistic alternative to LockBalance plus
+// UpdateBalance: the row is only changed while its version is still the
+// expected one.
+func (r *PgRepository) UpdateBalanceIfVersion(ctx context.Context, walletId uuid.UUID, balanceDiff int64, version int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE wallets w SET balance = w.balance + $2, min_balance = ` + minBalanceAfter("w.balance", "$2") + `,
+			version = w.version + 1
+		WHERE w.wallet_id = $1 AND w.status = 'ACTIVE' AND ` + versionExpr + ` = $3`
+
+	tag, err := tx.Exec(ctx, query, walletId, balanceDiff, version)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23514" {
+			return errors.New("not enough balance")
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "22003" {
+			return errors.New("balance limit exceeded")
+		}
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var status string
+	if err := tx.QueryRow(ctx, "SELECT status FROM wallets WHERE wallet_id = $1", walletId).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("wallet not found")
+		}
+		return err
+	}
+	if wallet.Status(status) != wallet.Active {
+		return errors.New("wallet is " + status)
+	}
+	return errors.New("version mismatch")
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const balanceExpr = "w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)"
+
+const walletColumns = `w.wallet_id, COALESCE(w.owner_id, ''), COALESCE(w.display_name, ''), COALESCE(w.external_ref, ''),
+	w.metadata, w.status, w.wallet_type, w.interest_rate_bps, w.currency, ` + balanceExpr + `, ` + versionExpr + `, w.created_at`
+
+func scanWallet(row pgx.Row) (wallet.Wallet, error) {
+	var (
+		wl       wallet.Wallet
+		metadata []byte
+		status   string
+		kind     string
+	)
+	err := row.Scan(&wl.ID, &wl.OwnerID, &wl.DisplayName, &wl.ExternalRef, &metadata, &status, &kind,
+		&wl.InterestRateBps, &wl.Currency, &wl.Balance, &wl.Version, &wl.CreatedAt)
+	wl.Type = wallet.Type(kind)
+	wl.Metadata = json.RawMessage(metadata)
+	wl.Status = wallet.Status(status)
+	return wl, err
+}
+
+// jsonParam passes nil for absent JSON so COALESCE can fall back.
+func jsonParam(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func profileError(err error) error {
+	pgErr, ok := err.(*pgconn.PgError)
+	if !ok {
+		return err
+	}
+	switch {
+	case pgErr.ConstraintName == "wallets_owner_external_ref_key":
+		return errors.New("external reference already exists")
+	case pgErr.ConstraintName == "wallets_external_ref_owner_check":
+		return errors.New("externalRef requires ownerId")
+	case pgErr.Code == "23505":
+		return errors.New("wallet already exists")
+	}
+	return err
+}
+
+func (r *PgRepository) InsertWalletWithProfile(ctx context.Context, wl wallet.Wallet) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO wallets (wallet_id, owner_id, display_name, external_ref, metadata, currency,
+			wallet_type, interest_rate_bps)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), COALESCE($5::jsonb, '{}'), $6, $7, $8)`
+
+	_, err := tx.Exec(ctx, query, wl.ID, wl.OwnerID, wl.DisplayName, wl.ExternalRef, jsonParam(wl.Metadata), wl.Currency,
+		string(wl.Type), wl.InterestRateBps)
+	if err != nil {
+		return profileError(err)
+	}
+	return nil
+}
+
+func (r *PgRepository) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + walletColumns + " FROM wallets w WHERE w.wallet_id = $1"
+
+	wl, err := scanWallet(tx.QueryRow(ctx, query, walletId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return wallet.Wallet{}, errors.New("wallet not found")
+		}
+		return wallet.Wallet{}, err
+	}
+	return wl, nil
+}
+
+func (r *PgRepository) GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + walletColumns + " FROM wallets w WHERE w.owner_id = $1 AND w.external_ref = $2"
+
+	wl, err := scanWallet(tx.QueryRow(ctx, query, ownerId, externalRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return wallet.Wallet{}, errors.New("wallet not found")
+		}
+		return wallet.Wallet{}, err
+	}
+	return wl, nil
+}
+
+func (r *PgRepository) ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + walletColumns + ` FROM wallets w
+		WHERE w.owner_id = $1 AND w.wallet_id > $2
+		ORDER BY w.wallet_id
+		LIMIT $3`
+
+	rows, err := tx.Query(ctx, query, ownerId, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []wallet.Wallet
+	for rows.Next() {
+		wl, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wl)
+	}
+	return wallets, rows.Err()
+}
+
+func (r *PgRepository) UpdateWalletProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE wallets SET
+			display_name = CASE WHEN $2 THEN NULLIF($3, '') ELSE display_name END,
+			external_ref = CASE WHEN $4 THEN NULLIF($5, '') ELSE external_ref END,
+			metadata = COALESCE($6::jsonb, metadata)
+		WHERE wallet_id = $1`
+
+	var displayName, externalRef string
+	if upd.DisplayName != nil {
+		displayName = *upd.DisplayName
+	}
+	if upd.ExternalRef != nil {
+		externalRef = *upd.ExternalRef
+	}
+
+	tag, err := tx.Exec(ctx, query, walletId, upd.DisplayName != nil, displayName,
+		upd.ExternalRef != nil, externalRef, jsonParam(upd.Metadata))
+	if err != nil {
+		return profileError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("wallet not found")
+	}
+	return nil
+}
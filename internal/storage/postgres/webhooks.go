@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"project/internal/webhook"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+const subscriptionColumns = "id, url, event_types, wallet_id, secret, created_at"
+
+func scanSubscription(row pgx.Row) (webhook.Subscription, error) {
+	var sub webhook.Subscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.EventTypes, &sub.WalletID, &sub.Secret, &sub.CreatedAt)
+	return sub, err
+}
+
+func (r *PgRepository) InsertSubscription(ctx context.Context, sub webhook.Subscription) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "INSERT INTO webhook_subscriptions (id, url, event_types, wallet_id, secret) VALUES ($1, $2, $3, $4, $5)"
+	_, err := tx.Exec(ctx, query, sub.ID, sub.URL, sub.EventTypes, sub.WalletID, sub.Secret)
+	return err
+}
+
+func (r *PgRepository) GetSubscription(ctx context.Context, id uuid.UUID) (webhook.Subscription, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions WHERE id = $1"
+	sub, err := scanSubscription(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return webhook.Subscription{}, errors.New("subscription not found")
+		}
+		return webhook.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (r *PgRepository) ListSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions ORDER BY created_at"
+	return r.querySubscriptions(ctx, tx, query)
+}
+
+func (r *PgRepository) MatchingSubscriptions(ctx context.Context, eventType string, walletId uuid.UUID) ([]webhook.Subscription, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := "SELECT " + subscriptionColumns + ` FROM webhook_subscriptions
+		WHERE $1 = ANY(event_types) AND (wallet_id IS NULL OR wallet_id = $2)`
+	return r.querySubscriptions(ctx, tx, query, eventType, walletId)
+}
+
+func (r *PgRepository) querySubscriptions(ctx context.Context, tx QueryEngine, query string, args ...interface{}) ([]webhook.Subscription, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []webhook.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *PgRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	tag, err := tx.Exec(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("subscription not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) InsertDelivery(ctx context.Context, subscriptionId uuid.UUID, eventId int64, eventType string, body []byte) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, body)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+	_, err := tx.Exec(ctx, query, subscriptionId, eventId, eventType, body)
+	return err
+}
+
+// ClaimDueDeliveries leases up to limit deliveries that are due, or whose
+// previous lease ran out, by moving them to 'sending' until now() + lease.
+func (r *PgRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE webhook_deliveries d
+		SET status = 'sending', locked_until = now() + $2 * interval '1 millisecond'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = 'pending' AND next_attempt_at <= now())
+				OR (status = 'sending' AND locked_until <= now())
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.body, d.status, d.attempts,
+			d.last_status_code, d.last_error, d.next_attempt_at, d.delivered_at, d.created_at, s.url, s.secret`
+
+	rows, err := tx.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []webhook.Delivery
+	for rows.Next() {
+		var d webhook.Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Body, &d.Status, &d.Attempts,
+			&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *PgRepository) MarkDeliverySucceeded(ctx context.Context, id int64, statusCode int) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = now(),
+			locked_until = NULL
+		WHERE id = $1`
+	_, err := tx.Exec(ctx, query, id, statusCode)
+	return err
+}
+
+func (r *PgRepository) MarkDeliveryFailed(ctx context.Context, id int64, statusCode *int, cause string, nextAttemptAt time.Time, status webhook.DeliveryStatus) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE webhook_deliveries
+		SET status = $5, attempts = attempts + 1, last_status_code = $2, last_error = $3, next_attempt_at = $4,
+			locked_until = NULL
+		WHERE id = $1`
+	_, err := tx.Exec(ctx, query, id, statusCode, cause, nextAttemptAt, string(status))
+	return err
+}
+
+func (r *PgRepository) ListDeliveries(ctx context.Context, subscriptionId uuid.UUID, limit int) ([]webhook.Delivery, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `SELECT id, subscription_id, event_id, event_type, status, attempts,
+			last_status_code, last_error, next_attempt_at, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	rows, err := tx.Query(ctx, query, subscriptionId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []webhook.Delivery
+	for rows.Next() {
+		var d webhook.Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PgRepository) RedeliverDelivery(ctx context.Context, subscriptionId uuid.UUID, id int64) error {
+	tx := r.txManager.GetQueryEngine(ctx)
+	query := `UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = NULL, locked_until = NULL
+		WHERE id = $1 AND subscription_id = $2`
+	tag, err := tx.Exec(ctx, query, id, subscriptionId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("delivery not found")
+	}
+	return nil
+}
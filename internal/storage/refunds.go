@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"project/internal/ledger"
+	"project/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+// Refund credits amount back to the wallet it left through an operation
+// named by externalRef, as a REFUND entry carrying that reference. The
+// money is coming back rather than arriving, so the balance cap does not
+// apply, and the reference keeps it from being refunded twice. For the same
+// reason a FROZEN wallet takes it; a CLOSED one cannot have a payout in
+// flight. A fee charged with the operation is handed back too, by refundFee.
+func (f *StorageFacade) Refund(ctx context.Context, walletId uuid.UUID, amount int64, fee ledger.FeeCharge, externalRef string) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.LockOpen(ctxTx, walletId); err != nil {
+			return err
+		}
+		if err := f.pgRepository.UpdateBalance(ctxTx, walletId, amount); err != nil {
+			return err
+		}
+		entry, err := f.pgRepository.InsertRefEntry(ctxTx, walletId, ledger.Refund, amount, externalRef)
+		if err != nil {
+			return err
+		}
+		if err := f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(entry)); err != nil {
+			return err
+		}
+		return f.refundFee(ctxTx, walletId, fee)
+	})
+}
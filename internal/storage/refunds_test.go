@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/storage/mocks"
+)
+
+func TestRefund_SkipsBalanceCap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().LockOpen(gomock.Any(), id).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(700)).Return(nil),
+		repo.EXPECT().InsertRefEntry(gomock.Any(), id, ledger.Refund, int64(700), "payout:1").
+			Return(ledger.Entry{ID: 3, WalletID: id, Amount: 700, Balance: 750}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo, WithMaxBalance(100))
+	require.NoError(t, f.Refund(context.Background(), id, 700, ledger.FeeCharge{}, "payout:1"))
+}
+
+func TestRefund_HandsBackFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, revenue := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	// The fee is returned after the refund, debiting the revenue wallet
+	// (shards folded back first) and crediting the wallet as FEE entries.
+	gomock.InOrder(
+		repo.EXPECT().LockOpen(gomock.Any(), id).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(700)).Return(nil),
+		repo.EXPECT().InsertRefEntry(gomock.Any(), id, ledger.Refund, int64(700), "payout:1").Return(ledger.Entry{ID: 3}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().LockBalance(gomock.Any(), revenue).Return(nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), revenue, int64(30)).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), revenue).Return(int64(30), nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), revenue, int64(-30)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), revenue, ledger.Fee, int64(-30)).Return(ledger.Entry{ID: 4}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(30)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Fee, int64(30)).Return(ledger.Entry{ID: 5}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+	require.NoError(t, f.Refund(context.Background(), id, 700, ledger.FeeCharge{Amount: 30, Revenue: revenue}, "payout:1"))
+}
+
+func TestRefund_RevenueShortOfFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, revenue := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().LockOpen(gomock.Any(), id).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(700)).Return(nil)
+	repo.EXPECT().InsertRefEntry(gomock.Any(), id, ledger.Refund, int64(700), "payout:1").Return(ledger.Entry{ID: 3}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().LockBalance(gomock.Any(), revenue).Return(nil)
+	repo.EXPECT().EnsureMainBalance(gomock.Any(), revenue, int64(30)).Return(nil)
+	repo.EXPECT().GetById(gomock.Any(), revenue).Return(int64(10), nil)
+
+	f := NewStorageFacade(tm, repo)
+	err := f.Refund(context.Background(), id, 700, ledger.FeeCharge{Amount: 30, Revenue: revenue}, "payout:1")
+	require.EqualError(t, err, "revenue wallet: not enough balance: 10 < 30")
+}
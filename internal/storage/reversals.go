@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"strings"
+)
+
+// Reverse undoes amount of a deposit or withdrawal, or whatever is left of it
+// when amount is 0, with an entry linked to the original. Reversing a
+// deposit takes the money back out of the wallet, which must still hold it
+// unless force lets the balance go negative. Other entries come in pairs or
+// belong to a payout, and reversing one alone would create or destroy money,
+// so they are refused. A reversal is a correction, so it goes through on a
+// FROZEN wallet but not on a CLOSED one.
+func (f *StorageFacade) Reverse(ctx context.Context, entryId int64, amount int64, force bool) (ledger.Entry, error) {
+	var reversal ledger.Entry
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		original, err := f.pgRepository.GetLedgerEntryForUpdate(ctxTx, entryId)
+		if err != nil {
+			return err
+		}
+		switch {
+		case original.OperationType == ledger.Reversal:
+			return errors.New("a reversal cannot be reversed")
+		case original.OperationType != ledger.Deposit && original.OperationType != ledger.Withdraw:
+			return fmt.Errorf("%s entries cannot be reversed", original.OperationType)
+		case strings.HasPrefix(original.ExternalRef, ledger.PayoutRef):
+			return errors.New("payout withdrawals cannot be reversed")
+		}
+
+		reversed, err := f.pgRepository.ReversedAmount(ctxTx, entryId)
+		if err != nil {
+			return err
+		}
+		remaining := original.Amount - reversed
+		if original.Amount < 0 {
+			remaining = -original.Amount - reversed
+		}
+		if remaining <= 0 {
+			return errors.New("operation already reversed")
+		}
+
+		undo := amount
+		if undo == 0 {
+			undo = remaining
+		}
+		if undo > remaining {
+			return fmt.Errorf("reversal exceeds remaining amount: %d > %d", undo, remaining)
+		}
+
+		if err := f.pgRepository.LockOpen(ctxTx, original.WalletID); err != nil {
+			return err
+		}
+
+		if original.Amount < 0 {
+			if err := f.pgRepository.UpdateBalance(ctxTx, original.WalletID, undo); err != nil {
+				return err
+			}
+			reversal, err = f.pgRepository.InsertReversalEntry(ctxTx, original, undo)
+			if err != nil {
+				return err
+			}
+			return f.publish(ctxTx, outbox.WalletCredited, original.WalletID, balanceChange(reversal))
+		}
+
+		balance, err := f.pgRepository.GetById(ctxTx, original.WalletID)
+		if err != nil {
+			return err
+		}
+		if balance < undo && !force {
+			return fmt.Errorf("not enough balance: %d < %d", balance, undo)
+		}
+		if err := f.pgRepository.EnsureMainBalance(ctxTx, original.WalletID, undo); err != nil {
+			return err
+		}
+		if balance < undo {
+			err = f.pgRepository.OverdrawBalance(ctxTx, original.WalletID, undo)
+		} else {
+			err = f.pgRepository.UpdateBalance(ctxTx, original.WalletID, -undo)
+		}
+		if err != nil {
+			return err
+		}
+		reversal, err = f.pgRepository.InsertReversalEntry(ctxTx, original, -undo)
+		if err != nil {
+			return err
+		}
+		return f.publish(ctxTx, outbox.WalletDebited, original.WalletID, balanceChange(reversal))
+	})
+	if err != nil {
+		return ledger.Entry{}, err
+	}
+	return reversal, nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+)
+
+func TestReverse_Withdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	original := ledger.Entry{ID: 7, WalletID: id, OperationType: ledger.Withdraw, Amount: -100}
+	reversalOf := int64(7)
+	gomock.InOrder(
+		repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(7)).Return(original, nil),
+		repo.EXPECT().ReversedAmount(gomock.Any(), int64(7)).Return(int64(30), nil),
+		repo.EXPECT().LockOpen(gomock.Any(), id).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(70)).Return(nil),
+		repo.EXPECT().InsertReversalEntry(gomock.Any(), original, int64(70)).
+			Return(ledger.Entry{ID: 9, WalletID: id, OperationType: ledger.Reversal, Amount: 70, Balance: 170, ReversalOf: &reversalOf}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCredited, event.Type)
+			require.JSONEq(t, `{"amount":70,"balance":170,"ledgerEntryId":9}`, string(event.Payload))
+			return nil
+		}),
+	)
+
+	entry, err := NewStorageFacade(tm, repo).Reverse(context.Background(), 7, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), entry.ID)
+	require.Equal(t, &reversalOf, entry.ReversalOf)
+}
+
+func TestReverse_DepositNeedsFunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	original := ledger.Entry{ID: 5, WalletID: id, OperationType: ledger.Deposit, Amount: 100}
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(5)).Return(original, nil).Times(2)
+	repo.EXPECT().ReversedAmount(gomock.Any(), int64(5)).Return(int64(0), nil).Times(2)
+	repo.EXPECT().LockOpen(gomock.Any(), id).Return(nil).Times(2)
+	repo.EXPECT().GetById(gomock.Any(), id).Return(int64(40), nil).Times(2)
+
+	f := NewStorageFacade(tm, repo)
+	_, err := f.Reverse(context.Background(), 5, 60, false)
+	require.EqualError(t, err, "not enough balance: 40 < 60")
+
+	gomock.InOrder(
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), id, int64(60)).Return(nil),
+		repo.EXPECT().OverdrawBalance(gomock.Any(), id, int64(60)).Return(nil),
+		repo.EXPECT().InsertReversalEntry(gomock.Any(), original, int64(-60)).
+			Return(ledger.Entry{ID: 6, WalletID: id, OperationType: ledger.Reversal, Amount: -60, Balance: -20}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletDebited, event.Type)
+			return nil
+		}),
+	)
+
+	entry, err := f.Reverse(context.Background(), 5, 60, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(-20), entry.Balance)
+}
+
+func TestReverse_Rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+	f := NewStorageFacade(tm, repo)
+
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(1)).
+		Return(ledger.Entry{ID: 1, WalletID: id, OperationType: ledger.Reversal, Amount: -5}, nil)
+	_, err := f.Reverse(context.Background(), 1, 0, false)
+	require.EqualError(t, err, "a reversal cannot be reversed")
+
+	deposit := ledger.Entry{ID: 2, WalletID: id, OperationType: ledger.Deposit, Amount: 100}
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(2)).Return(deposit, nil).Times(2)
+	repo.EXPECT().ReversedAmount(gomock.Any(), int64(2)).Return(int64(100), nil)
+	_, err = f.Reverse(context.Background(), 2, 0, false)
+	require.EqualError(t, err, "operation already reversed")
+
+	repo.EXPECT().ReversedAmount(gomock.Any(), int64(2)).Return(int64(60), nil)
+	_, err = f.Reverse(context.Background(), 2, 50, true)
+	require.EqualError(t, err, "reversal exceeds remaining amount: 50 > 40")
+
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(3)).Return(ledger.Entry{}, errAny("operation not found"))
+	_, err = f.Reverse(context.Background(), 3, 0, false)
+	require.EqualError(t, err, "operation not found")
+
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(4)).
+		Return(ledger.Entry{ID: 4, WalletID: id, OperationType: ledger.Fee, Amount: -10}, nil)
+	_, err = f.Reverse(context.Background(), 4, 0, false)
+	require.EqualError(t, err, "FEE entries cannot be reversed")
+
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(5)).
+		Return(ledger.Entry{ID: 5, WalletID: id, OperationType: ledger.Interest, Amount: 40}, nil)
+	_, err = f.Reverse(context.Background(), 5, 0, false)
+	require.EqualError(t, err, "INTEREST entries cannot be reversed")
+
+	repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(6)).
+		Return(ledger.Entry{ID: 6, WalletID: id, OperationType: ledger.Withdraw, Amount: -500, ExternalRef: ledger.PayoutRef + "x:withdrawal"}, nil)
+	_, err = f.Reverse(context.Background(), 6, 0, false)
+	require.EqualError(t, err, "payout withdrawals cannot be reversed")
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/postgres"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// depositSharded credits one randomly chosen shard of a hot wallet. It runs at
+// READ COMMITTED and never locks the wallets row, so concurrent deposits only
+// serialize on the shard they pick. The entry goes to that shard's ledger
+// lane; its balance_after, and so the balance cap, only count deposits on
+// other shards once they have committed.
+func (f *StorageFacade) depositSharded(ctx context.Context, walletId uuid.UUID, shards int, amount int64) error {
+	return f.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+
+		shard := rand.Intn(shards)
+		ok, err := f.pgRepository.DepositToShard(ctxTx, walletId, shard, amount)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_, err := f.deposit(ctxTx, walletId, amount)
+			return err
+		}
+
+		entry, err := f.pgRepository.InsertShardEntry(ctxTx, walletId, shard, ledger.Deposit, amount)
+		if err != nil {
+			return err
+		}
+
+		if err := f.checkBalance(entry); err != nil {
+			return err
+		}
+
+		return f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(entry))
+	})
+}
+
+func (f *StorageFacade) SetShardCount(ctx context.Context, walletId uuid.UUID, shards int) error {
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.LockBalance(ctxTx, walletId); err != nil {
+			return err
+		}
+
+		return f.pgRepository.ResizeShards(ctxTx, walletId, shards)
+	})
+	if err == nil && f.shardCounts != nil {
+		f.shardCounts.forget(walletId)
+	}
+	return err
+}
+
+// maxCachedShardCounts bounds the shard count cache; it starts over once
+// full.
+const maxCachedShardCounts = 100000
+
+// WithShardCountCache keeps each wallet's shard count for ttl, so a deposit
+// does not read it first on every call. A stale count only picks the other
+// deposit path: a wallet that gained shards is credited on its wallets row,
+// and a shard that no longer exists makes depositSharded fall back to a
+// plain deposit.
+func WithShardCountCache(ttl time.Duration) Option {
+	return func(f *StorageFacade) {
+		if ttl <= 0 {
+			return
+		}
+		f.shardCounts = &shardCounts{ttl: ttl, entries: make(map[uuid.UUID]shardCount)}
+	}
+}
+
+type shardCount struct {
+	shards  int
+	expires time.Time
+}
+
+type shardCounts struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]shardCount
+}
+
+func (c *shardCounts) get(walletId uuid.UUID, now time.Time) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[walletId]
+	if !ok || now.After(e.expires) {
+		return 0, false
+	}
+	return e.shards, true
+}
+
+func (c *shardCounts) put(walletId uuid.UUID, shards int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedShardCounts {
+		c.entries = make(map[uuid.UUID]shardCount)
+	}
+	c.entries[walletId] = shardCount{shards: shards, expires: now.Add(c.ttl)}
+}
+
+func (c *shardCounts) forget(walletId uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, walletId)
+}
+
+func (f *StorageFacade) shardCount(ctx context.Context, walletId uuid.UUID) (int, error) {
+	if f.shardCounts == nil {
+		return f.pgRepository.GetShardCount(ctx, walletId)
+	}
+	now := time.Now()
+	if shards, ok := f.shardCounts.get(walletId, now); ok {
+		return shards, nil
+	}
+	shards, err := f.pgRepository.GetShardCount(ctx, walletId)
+	if err != nil {
+		return 0, err
+	}
+	f.shardCounts.put(walletId, shards, now)
+	return shards, nil
+}
+
+// ShardCompactor periodically folds shard balances back into the wallets
+// row, keeping the main bucket funded for withdrawals.
+type ShardCompactor struct {
+	txManager    postgres.TransactionManager
+	pgRepository WalletRepo
+	interval     time.Duration
+	batchSize    int
+}
+
+func NewShardCompactor(txManager postgres.TransactionManager, pgRepository WalletRepo, interval time.Duration, batchSize int) *ShardCompactor {
+	return &ShardCompactor{
+		txManager:    txManager,
+		pgRepository: pgRepository,
+		interval:     interval,
+		batchSize:    batchSize,
+	}
+}
+
+func (c *ShardCompactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.CompactAll(ctx); err != nil {
+				log.Printf("shard compactor: %v", err)
+			}
+		}
+	}
+}
+
+func (c *ShardCompactor) CompactAll(ctx context.Context) (int, error) {
+	compacted := 0
+	after := uuid.Nil
+	for {
+		ids, err := c.pgRepository.ListShardedWallets(ctx, after, c.batchSize)
+		if err != nil {
+			return compacted, err
+		}
+
+		for _, id := range ids {
+			if err := c.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+				_, err := c.pgRepository.CompactShards(ctxTx, id)
+				return err
+			}); err != nil {
+				return compacted, err
+			}
+			compacted++
+			after = id
+		}
+
+		if len(ids) < c.batchSize {
+			return compacted, nil
+		}
+	}
+}
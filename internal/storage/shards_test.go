@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+)
+
+func passThroughReadCommitted(tm *mocks.MockTransactionManager) {
+	tm.
+		EXPECT().
+		RunReadCommitted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+}
+
+func TestDeposit_ShardedSkipsWalletLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+
+	picked := -1
+	gomock.InOrder(
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(4, nil),
+		repo.EXPECT().DepositToShard(gomock.Any(), id, gomock.Any(), int64(75)).
+			DoAndReturn(func(ctx context.Context, walletId uuid.UUID, shard int, amount int64) (bool, error) {
+				require.True(t, shard >= 0 && shard < 4)
+				picked = shard
+				return true, nil
+			}),
+		repo.EXPECT().InsertShardEntry(gomock.Any(), id, gomock.Any(), ledger.Deposit, int64(75)).
+			DoAndReturn(func(ctx context.Context, walletId uuid.UUID, shard int, opType ledger.OperationType, amount int64) (ledger.Entry, error) {
+				require.Equal(t, picked, shard)
+				return ledger.Entry{ID: 3, WalletID: id, Amount: 75, Balance: 175, Shard: &shard}, nil
+			}),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCredited, event.Type)
+			require.JSONEq(t, `{"amount":75,"balance":175,"ledgerEntryId":3}`, string(event.Payload))
+			return nil
+		}),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.Deposit(context.Background(), id, 75))
+}
+
+func TestDeposit_ShardMissingFallsBackToMainRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(2, nil),
+		repo.EXPECT().DepositToShard(gomock.Any(), id, gomock.Any(), int64(75)).Return(false, nil),
+		repo.EXPECT().LockActive(gomock.Any(), id).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), id, int64(75)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), id, ledger.Deposit, int64(75)).Return(ledger.Entry{ID: 4}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.Deposit(context.Background(), id, 75))
+}
+
+func TestSetShardCount_LocksWalletFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil),
+		repo.EXPECT().ResizeShards(gomock.Any(), id, 8).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.SetShardCount(context.Background(), id, 8))
+}
+
+func TestShardCompactor_PagesThroughWallets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().ListShardedWallets(gomock.Any(), uuid.Nil, 2).Return([]uuid.UUID{a, b}, nil),
+		repo.EXPECT().CompactShards(gomock.Any(), a).Return(int64(10), nil),
+		repo.EXPECT().CompactShards(gomock.Any(), b).Return(int64(20), nil),
+		repo.EXPECT().ListShardedWallets(gomock.Any(), b, 2).Return([]uuid.UUID{c}, nil),
+		repo.EXPECT().CompactShards(gomock.Any(), c).Return(int64(30), nil),
+	)
+
+	compactor := NewShardCompactor(tm, repo, 0, 2)
+
+	n, err := compactor.CompactAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+}
+
+func TestDeposit_ShardCountCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+	passThroughTx(tm)
+
+	repo.EXPECT().DepositToShard(gomock.Any(), id, gomock.Any(), int64(5)).Return(true, nil).Times(3)
+	repo.EXPECT().InsertShardEntry(gomock.Any(), id, gomock.Any(), ledger.Deposit, int64(5)).Return(ledger.Entry{ID: 1}, nil).Times(3)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	gomock.InOrder(
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(4, nil),
+		repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil),
+		repo.EXPECT().ResizeShards(gomock.Any(), id, 2).Return(nil),
+		repo.EXPECT().GetShardCount(gomock.Any(), id).Return(2, nil),
+	)
+
+	f := NewStorageFacade(tm, repo, WithShardCountCache(time.Minute))
+
+	require.NoError(t, f.Deposit(context.Background(), id, 5))
+	require.NoError(t, f.Deposit(context.Background(), id, 5))
+	require.NoError(t, f.SetShardCount(context.Background(), id, 2))
+	require.NoError(t, f.Deposit(context.Background(), id, 5))
+}
+
+func TestDeposit_ShardedRunsInParallel(t *testing.T) {
+	tx := &benchTx{latency: 5 * time.Millisecond}
+	repo := &benchRepo{tx: tx, shards: 16}
+	f := NewStorageFacade(tx, repo)
+	id := uuid.New()
+
+	const deposits = 64
+	var wg sync.WaitGroup
+	for i := 0; i < deposits; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, f.Deposit(context.Background(), id, 1))
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(deposits), repo.balance.Load())
+	// Only a per-shard lock is held, so deposits on different shards commit
+	// together rather than one at a time.
+	require.Greater(t, tx.maxCommitting.Load(), int64(1))
+}
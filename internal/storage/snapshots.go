@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"project/internal/statement"
+	"project/internal/storage/postgres"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetStatement reads the opening balance and entries in one read-only
+// transaction, so the totals always add up to the closing balance.
+func (f *StorageFacade) GetStatement(ctx context.Context, walletId uuid.UUID, from, to time.Time, maxEntries int) (statement.Statement, error) {
+	st := statement.Statement{WalletID: walletId, From: statement.Date(from), To: statement.Date(to)}
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		opening, err := f.pgRepository.GetOpeningBalance(ctxTx, walletId, st.Start())
+		if err != nil {
+			return err
+		}
+
+		entries, err := f.pgRepository.ListEntriesBetween(ctxTx, walletId, st.Start(), st.End(), maxEntries+1)
+		if err != nil {
+			return err
+		}
+		if len(entries) > maxEntries {
+			return errors.New("statement has too many entries, narrow the date range")
+		}
+
+		st.Opening = opening
+		st.Entries = entries
+		return nil
+	})
+	if err != nil {
+		return statement.Statement{}, err
+	}
+
+	st.Total()
+	return st, nil
+}
+
+// DailySnapshotter writes one snapshot per wallet for every UTC day that has
+// closed at least lag ago, catching up on days missed while it was down.
+type DailySnapshotter struct {
+	txManager    postgres.TransactionManager
+	pgRepository WalletRepo
+	interval     time.Duration
+	lag          time.Duration
+	batchSize    int
+}
+
+func NewDailySnapshotter(txManager postgres.TransactionManager, pgRepository WalletRepo, interval, lag time.Duration, batchSize int) *DailySnapshotter {
+	return &DailySnapshotter{
+		txManager:    txManager,
+		pgRepository: pgRepository,
+		interval:     interval,
+		lag:          lag,
+		batchSize:    batchSize,
+	}
+}
+
+func (s *DailySnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.CatchUp(ctx, now); err != nil {
+				log.Printf("daily snapshotter: %v", err)
+			}
+		}
+	}
+}
+
+// lastClosedDay is the latest day whose end is at least lag in the past.
+func (s *DailySnapshotter) lastClosedDay(now time.Time) time.Time {
+	return now.Add(-s.lag).UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+}
+
+func (s *DailySnapshotter) CatchUp(ctx context.Context, now time.Time) error {
+	last := s.lastClosedDay(now)
+
+	latest, err := s.pgRepository.LatestSnapshotDay(ctx)
+	if err != nil {
+		return err
+	}
+	// The latest day is redone in case a previous run stopped halfway
+	// through it; existing snapshots are left alone.
+	day := latest
+	if latest.IsZero() {
+		day = last
+	}
+
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if _, err := s.SnapshotDay(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DailySnapshotter) SnapshotDay(ctx context.Context, day time.Time) (int, error) {
+	written := 0
+	after := uuid.Nil
+	for {
+		var (
+			last uuid.UUID
+			n    int
+		)
+		if err := s.txManager.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+			var err error
+			last, n, err = s.pgRepository.InsertDailySnapshots(ctxTx, after, day, s.batchSize)
+			return err
+		}); err != nil {
+			return written, err
+		}
+		written += n
+
+		if last == uuid.Nil {
+			return written, nil
+		}
+		after = last
+	}
+}
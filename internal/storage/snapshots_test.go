@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/storage/mocks"
+)
+
+func passThroughReadOnly(tm *mocks.MockTransactionManager) {
+	tm.
+		EXPECT().
+		RunReadOnly(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctxTx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+}
+
+func TestGetStatement_TotalsEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadOnly(tm)
+
+	repo.EXPECT().GetOpeningBalance(gomock.Any(), id, from).Return(int64(50), nil)
+	repo.EXPECT().ListEntriesBetween(gomock.Any(), id, from, to.AddDate(0, 0, 1), 11).
+		Return([]ledger.Entry{{Amount: 30}, {Amount: -20}}, nil)
+
+	st, err := NewStorageFacade(tm, repo).GetStatement(context.Background(), id, from, to, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), st.Opening)
+	require.Equal(t, int64(30), st.Credits)
+	require.Equal(t, int64(20), st.Debits)
+	require.Equal(t, int64(60), st.Closing)
+}
+
+func TestGetStatement_TooManyEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadOnly(tm)
+
+	repo.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	repo.EXPECT().ListEntriesBetween(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), 3).
+		Return(make([]ledger.Entry, 3), nil)
+
+	_, err := NewStorageFacade(tm, repo).GetStatement(context.Background(), uuid.New(), time.Now(), time.Now(), 2)
+	require.EqualError(t, err, "statement has too many entries, narrow the date range")
+}
+
+func TestSnapshotter_CatchUpRedoesLatestAndFillsGaps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughReadCommitted(tm)
+
+	day := func(d int) time.Time { return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2025, 10, 4, 0, 20, 0, 0, time.UTC)
+
+	repo.EXPECT().LatestSnapshotDay(gomock.Any()).Return(day(1), nil)
+	gomock.InOrder(
+		repo.EXPECT().InsertDailySnapshots(gomock.Any(), uuid.Nil, day(1), 100).Return(uuid.Nil, 0, nil),
+		repo.EXPECT().InsertDailySnapshots(gomock.Any(), uuid.Nil, day(2), 100).Return(uuid.Nil, 0, nil),
+		repo.EXPECT().InsertDailySnapshots(gomock.Any(), uuid.Nil, day(3), 100).Return(uuid.Nil, 0, nil),
+	)
+
+	s := NewDailySnapshotter(tm, repo, time.Hour, 10*time.Minute, 100)
+	require.NoError(t, s.CatchUp(context.Background(), now))
+}
+
+func TestSnapshotter_WaitsForLag(t *testing.T) {
+	s := NewDailySnapshotter(nil, nil, time.Hour, 10*time.Minute, 100)
+
+	require.Equal(t, time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC), s.lastClosedDay(time.Date(2025, 10, 4, 0, 5, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC), s.lastClosedDay(time.Date(2025, 10, 4, 0, 15, 0, 0, time.UTC)))
+}
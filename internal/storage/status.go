@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+)
+
+// SetStatus moves a wallet between ACTIVE and FROZEN, or closes it for
+// good. A FROZEN wallet refuses deposits, withdrawals, transfers and
+// interest, but still takes reversals and payout refunds. A CLOSED wallet
+// takes nothing, so only an empty wallet with no payout still in flight can
+// be closed.
+func (f *StorageFacade) SetStatus(ctx context.Context, walletId uuid.UUID, status wallet.Status) (wallet.Wallet, error) {
+	var wl wallet.Wallet
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.LockBalance(ctxTx, walletId); err != nil {
+			return err
+		}
+
+		var err error
+		wl, err = f.pgRepository.GetWallet(ctxTx, walletId)
+		if err != nil {
+			return err
+		}
+		if wl.Status == status {
+			return nil
+		}
+		if wl.Status == wallet.Closed {
+			return errors.New("wallet is CLOSED")
+		}
+		if status == wallet.Closed {
+			if wl.Balance != 0 {
+				return errors.New("wallet balance must be zero to close")
+			}
+			open, err := f.pgRepository.HasOpenPayouts(ctxTx, walletId)
+			if err != nil {
+				return err
+			}
+			if open {
+				return errors.New("wallet has payouts in flight")
+			}
+		}
+
+		if err := f.pgRepository.UpdateWalletStatus(ctxTx, walletId, status); err != nil {
+			return err
+		}
+		wl.Status = status
+		return nil
+	})
+	return wl, err
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/interest"
+	"project/internal/ledger"
+	"project/internal/storage/mocks"
+	"project/internal/wallet"
+)
+
+func TestSetStatus_Transitions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+	f := NewStorageFacade(tm, repo)
+
+	repo.EXPECT().LockBalance(gomock.Any(), id).Return(nil).Times(4)
+
+	repo.EXPECT().GetWallet(gomock.Any(), id).Return(wallet.Wallet{ID: id, Status: wallet.Active, Balance: 10}, nil)
+	repo.EXPECT().UpdateWalletStatus(gomock.Any(), id, wallet.Frozen).Return(nil)
+	wl, err := f.SetStatus(context.Background(), id, wallet.Frozen)
+	require.NoError(t, err)
+	require.Equal(t, wallet.Frozen, wl.Status)
+
+	repo.EXPECT().GetWallet(gomock.Any(), id).Return(wallet.Wallet{ID: id, Status: wallet.Frozen, Balance: 10}, nil)
+	_, err = f.SetStatus(context.Background(), id, wallet.Closed)
+	require.EqualError(t, err, "wallet balance must be zero to close")
+
+	repo.EXPECT().GetWallet(gomock.Any(), id).Return(wallet.Wallet{ID: id, Status: wallet.Frozen}, nil)
+	repo.EXPECT().HasOpenPayouts(gomock.Any(), id).Return(true, nil)
+	_, err = f.SetStatus(context.Background(), id, wallet.Closed)
+	require.EqualError(t, err, "wallet has payouts in flight")
+
+	repo.EXPECT().GetWallet(gomock.Any(), id).Return(wallet.Wallet{ID: id, Status: wallet.Closed}, nil)
+	_, err = f.SetStatus(context.Background(), id, wallet.Active)
+	require.EqualError(t, err, "wallet is CLOSED")
+}
+
+func TestDeposit_FrozenWalletRefused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().GetShardCount(gomock.Any(), id).Return(0, nil)
+	repo.EXPECT().LockActive(gomock.Any(), id).Return(errAny("wallet is FROZEN"))
+
+	require.EqualError(t, NewStorageFacade(tm, repo).Deposit(context.Background(), id, 10), "wallet is FROZEN")
+}
+
+func TestRefund_FrozenWalletTakesIt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	frozen, closed := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+	f := NewStorageFacade(tm, repo)
+
+	// LockOpen lets a FROZEN wallet through and refuses a CLOSED one.
+	repo.EXPECT().LockOpen(gomock.Any(), frozen).Return(nil)
+	repo.EXPECT().UpdateBalance(gomock.Any(), frozen, int64(70)).Return(nil)
+	repo.EXPECT().InsertRefEntry(gomock.Any(), frozen, ledger.Refund, int64(70), "payout:1").Return(ledger.Entry{ID: 2}, nil)
+	repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	require.NoError(t, f.Refund(context.Background(), frozen, 70, ledger.FeeCharge{}, "payout:1"))
+
+	repo.EXPECT().LockOpen(gomock.Any(), closed).Return(errAny("wallet is CLOSED"))
+	require.EqualError(t, f.Refund(context.Background(), closed, 70, ledger.FeeCharge{}, "payout:2"), "wallet is CLOSED")
+}
+
+func TestReverse_ClosedWalletRefused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	original := ledger.Entry{ID: 7, WalletID: id, OperationType: ledger.Withdraw, Amount: -100}
+	gomock.InOrder(
+		repo.EXPECT().GetLedgerEntryForUpdate(gomock.Any(), int64(7)).Return(original, nil),
+		repo.EXPECT().ReversedAmount(gomock.Any(), int64(7)).Return(int64(0), nil),
+		repo.EXPECT().LockOpen(gomock.Any(), id).Return(errAny("wallet is CLOSED")),
+	)
+
+	_, err := NewStorageFacade(tm, repo).Reverse(context.Background(), 7, 0, false)
+	require.EqualError(t, err, "wallet is CLOSED")
+}
+
+func TestInterestJob_SkipsInactiveWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	treasury, frozen := uuid.New(), uuid.New()
+	j, repo := interestJob(ctrl, treasury)
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(month, interest.LastDay(month), nil)
+	gomock.InOrder(
+		repo.EXPECT().ListInterestDue(gomock.Any(), month, uuid.Nil, 2).Return([]interest.Due{{WalletID: frozen, Amount: 40}}, nil),
+		repo.EXPECT().LockActive(gomock.Any(), treasury).Return(nil),
+		repo.EXPECT().GetById(gomock.Any(), treasury).Return(int64(1000), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), treasury, int64(40)).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), treasury, int64(-40)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), treasury, ledger.Interest, int64(-40)).Return(ledger.Entry{ID: 1}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), frozen).Return(errAny("wallet is FROZEN")),
+		repo.EXPECT().ListInterestDue(gomock.Any(), month, frozen, 2).Return(nil, nil),
+		repo.EXPECT().InsertInterestRun(gomock.Any(), interest.PostingJob, month, 0).Return(nil),
+	)
+
+	posted, err := j.PostMonth(context.Background(), month)
+	require.NoError(t, err)
+	require.Equal(t, 0, posted)
+}
+
+func TestInterestJob_FrozenTreasuryStops(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	treasury := uuid.New()
+	j, repo := interestJob(ctrl, treasury)
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().InterestRunRange(gomock.Any(), interest.AccrualJob).Return(month, interest.LastDay(month), nil)
+	repo.EXPECT().ListInterestDue(gomock.Any(), month, uuid.Nil, 2).Return([]interest.Due{{WalletID: uuid.New(), Amount: 40}}, nil)
+	repo.EXPECT().LockActive(gomock.Any(), treasury).Return(errAny("wallet is FROZEN"))
+
+	_, err := j.PostMonth(context.Background(), month)
+	require.EqualError(t, err, "treasury: wallet is FROZEN")
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/ledger"
+	"project/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+// Transfer moves amount from one wallet to another as a pair of TRANSFER
+// entries, and charges fee to the sender in the same transaction. Both
+// wallets are locked in a stable order, so opposite transfers between the
+// same two wallets cannot deadlock.
+func (f *StorageFacade) Transfer(ctx context.Context, from, to uuid.UUID, amount int64, fee ledger.FeeCharge) error {
+	if from == to {
+		return errors.New("cannot transfer to the same wallet")
+	}
+
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		first, second := from, to
+		if bytes.Compare(first[:], second[:]) > 0 {
+			first, second = second, first
+		}
+		if err := f.pgRepository.LockActive(ctxTx, first); err != nil {
+			return err
+		}
+		if err := f.pgRepository.LockActive(ctxTx, second); err != nil {
+			return err
+		}
+
+		sender, err := f.pgRepository.GetWallet(ctxTx, from)
+		if err != nil {
+			return err
+		}
+		receiver, err := f.pgRepository.GetWallet(ctxTx, to)
+		if err != nil {
+			return err
+		}
+		if sender.Currency != receiver.Currency {
+			return fmt.Errorf("currency mismatch: %s != %s", sender.Currency, receiver.Currency)
+		}
+
+		balance, err := f.pgRepository.GetById(ctxTx, from)
+		if err != nil {
+			return err
+		}
+		if balance < amount+fee.Amount {
+			return fmt.Errorf("not enough balance: %d < %d", balance, amount+fee.Amount)
+		}
+		if err := f.pgRepository.EnsureMainBalance(ctxTx, from, amount+fee.Amount); err != nil {
+			return err
+		}
+
+		if err := f.pgRepository.UpdateBalance(ctxTx, from, -amount); err != nil {
+			return err
+		}
+		debit, err := f.pgRepository.InsertLedgerEntry(ctxTx, from, ledger.Transfer, -amount)
+		if err != nil {
+			return err
+		}
+		if err := f.publish(ctxTx, outbox.WalletDebited, from, balanceChange(debit)); err != nil {
+			return err
+		}
+
+		if err := f.pgRepository.UpdateBalance(ctxTx, to, amount); err != nil {
+			return err
+		}
+		credit, err := f.pgRepository.InsertLedgerEntry(ctxTx, to, ledger.Transfer, amount)
+		if err != nil {
+			return err
+		}
+		if err := f.checkBalance(credit); err != nil {
+			return err
+		}
+		if err := f.publish(ctxTx, outbox.WalletCredited, to, balanceChange(credit)); err != nil {
+			return err
+		}
+
+		return f.chargeFee(ctxTx, from, fee)
+	})
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/ledger"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+	"project/internal/wallet"
+)
+
+func TestTransfer_MovesFundsAndChargesFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from, to, revenue := uuid.New(), uuid.New(), uuid.New()
+	first, second := from, to
+	if bytes.Compare(first[:], second[:]) > 0 {
+		first, second = second, first
+	}
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().LockActive(gomock.Any(), first).Return(nil),
+		repo.EXPECT().LockActive(gomock.Any(), second).Return(nil),
+		repo.EXPECT().GetWallet(gomock.Any(), from).Return(wallet.Wallet{ID: from, Currency: "EUR"}, nil),
+		repo.EXPECT().GetWallet(gomock.Any(), to).Return(wallet.Wallet{ID: to, Currency: "EUR"}, nil),
+		repo.EXPECT().GetById(gomock.Any(), from).Return(int64(100), nil),
+		repo.EXPECT().EnsureMainBalance(gomock.Any(), from, int64(65)).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), from, int64(-60)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), from, ledger.Transfer, int64(-60)).
+			Return(ledger.Entry{ID: 1, WalletID: from, Amount: -60, Balance: 40}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletDebited, event.Type)
+			require.Equal(t, from, event.WalletID)
+			return nil
+		}),
+		repo.EXPECT().UpdateBalance(gomock.Any(), to, int64(60)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), to, ledger.Transfer, int64(60)).
+			Return(ledger.Entry{ID: 2, WalletID: to, Amount: 60, Balance: 60}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCredited, event.Type)
+			require.Equal(t, to, event.WalletID)
+			return nil
+		}),
+		repo.EXPECT().GetById(gomock.Any(), from).Return(int64(40), nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), from, int64(-5)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), from, ledger.Fee, int64(-5)).
+			Return(ledger.Entry{ID: 3, WalletID: from, Amount: -5, Balance: 35}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+		repo.EXPECT().GetShardCount(gomock.Any(), revenue).Return(0, nil),
+		repo.EXPECT().LockBalance(gomock.Any(), revenue).Return(nil),
+		repo.EXPECT().UpdateBalance(gomock.Any(), revenue, int64(5)).Return(nil),
+		repo.EXPECT().InsertLedgerEntry(gomock.Any(), revenue, ledger.Fee, int64(5)).
+			Return(ledger.Entry{ID: 4, WalletID: revenue, Amount: 5, Balance: 5}, nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	f := NewStorageFacade(tm, repo)
+
+	require.NoError(t, f.Transfer(context.Background(), from, to, 60, ledger.FeeCharge{Amount: 5, Revenue: revenue}))
+}
+
+func TestTransfer_Refusals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from, to := uuid.New(), uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+	f := NewStorageFacade(tm, repo)
+
+	require.EqualError(t, f.Transfer(context.Background(), from, from, 10, ledger.FeeCharge{}), "cannot transfer to the same wallet")
+
+	repo.EXPECT().LockActive(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+	repo.EXPECT().GetWallet(gomock.Any(), from).Return(wallet.Wallet{ID: from, Currency: "EUR"}, nil).Times(2)
+	repo.EXPECT().GetWallet(gomock.Any(), to).Return(wallet.Wallet{ID: to, Currency: "USD"}, nil)
+	require.EqualError(t, f.Transfer(context.Background(), from, to, 10, ledger.FeeCharge{}), "currency mismatch: EUR != USD")
+
+	repo.EXPECT().GetWallet(gomock.Any(), to).Return(wallet.Wallet{ID: to, Currency: "EUR"}, nil)
+	repo.EXPECT().GetById(gomock.Any(), from).Return(int64(10), nil)
+	require.EqualError(t, f.Transfer(context.Background(), from, to, 10, ledger.FeeCharge{Amount: 1, Revenue: uuid.New()}), "not enough balance: 10 < 11")
+}
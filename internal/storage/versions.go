@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"project/internal/ledger"
+	"project/internal/outbox"
+
+	"github.com/google/uuid"
+)
+
+func (f *StorageFacade) GetBalanceVersion(ctx context.Context, walletId uuid.UUID) (int64, int64, error) {
+	var balance, version int64
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		balance, version, err = f.pgRepository.GetBalanceVersion(ctxTx, walletId)
+		return err
+	})
+	return balance, version, err
+}
+
+func (f *StorageFacade) DepositIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.UpdateBalanceIfVersion(ctxTx, walletId, amount, version); err != nil {
+			return err
+		}
+
+		entry, err := f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Deposit, amount)
+		if err != nil {
+			return err
+		}
+
+		if err := f.checkBalance(entry); err != nil {
+			return err
+		}
+
+		return f.publish(ctxTx, outbox.WalletCredited, walletId, balanceChange(entry))
+	})
+}
+
+func (f *StorageFacade) WithdrawIfVersion(ctx context.Context, walletId uuid.UUID, amount int64, version int64, fee ledger.FeeCharge) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.EnsureMainBalance(ctxTx, walletId, amount+fee.Amount); err != nil {
+			return err
+		}
+
+		if err := f.pgRepository.UpdateBalanceIfVersion(ctxTx, walletId, -amount, version); err != nil {
+			return err
+		}
+
+		entry, err := f.pgRepository.InsertLedgerEntry(ctxTx, walletId, ledger.Withdraw, -amount)
+		if err != nil {
+			return err
+		}
+
+		if err := f.publish(ctxTx, outbox.WalletDebited, walletId, balanceChange(entry)); err != nil {
+			return err
+		}
+
+		return f.chargeFee(ctxTx, walletId, fee)
+	})
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"project/internal/outbox"
+	"project/internal/wallet"
+
+	"github.com/google/uuid"
+)
+
+func (f *StorageFacade) CreateWithProfile(ctx context.Context, wl wallet.Wallet) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.InsertWalletWithProfile(ctxTx, wl); err != nil {
+			return err
+		}
+
+		return f.publish(ctxTx, outbox.WalletCreated, wl.ID, struct{}{})
+	})
+}
+
+func (f *StorageFacade) GetWallet(ctx context.Context, walletId uuid.UUID) (wallet.Wallet, error) {
+	var wl wallet.Wallet
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		wl, err = f.pgRepository.GetWallet(ctxTx, walletId)
+		return err
+	})
+	return wl, err
+}
+
+func (f *StorageFacade) GetWalletByExternalRef(ctx context.Context, ownerId, externalRef string) (wallet.Wallet, error) {
+	var wl wallet.Wallet
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		wl, err = f.pgRepository.GetWalletByExternalRef(ctxTx, ownerId, externalRef)
+		return err
+	})
+	return wl, err
+}
+
+func (f *StorageFacade) ListWalletsByOwner(ctx context.Context, ownerId string, afterId uuid.UUID, limit int) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		wallets, err = f.pgRepository.ListWalletsByOwner(ctxTx, ownerId, afterId, limit)
+		return err
+	})
+	return wallets, err
+}
+
+func (f *StorageFacade) UpdateProfile(ctx context.Context, walletId uuid.UUID, upd wallet.ProfileUpdate) (wallet.Wallet, error) {
+	var wl wallet.Wallet
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+
+		if err := f.pgRepository.UpdateWalletProfile(ctxTx, walletId, upd); err != nil {
+			return err
+		}
+
+		var err error
+		wl, err = f.pgRepository.GetWallet(ctxTx, walletId)
+		return err
+	})
+	return wl, err
+}
+
+func (f *StorageFacade) ListWallets(ctx context.Context, q wallet.Query) ([]wallet.Wallet, error) {
+	var wallets []wallet.Wallet
+	err := f.txManager.RunReadOnly(ctx, func(ctxTx context.Context) error {
+		var err error
+		wallets, err = f.pgRepository.ListWallets(ctxTx, q)
+		return err
+	})
+	return wallets, err
+}
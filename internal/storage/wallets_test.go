@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"project/internal/outbox"
+	"project/internal/storage/mocks"
+	"project/internal/wallet"
+)
+
+func TestCreateWithProfile_PublishesCreated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	wl := wallet.Wallet{ID: uuid.New(), OwnerID: "cust-1", ExternalRef: "acc/42"}
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().InsertWalletWithProfile(gomock.Any(), wl).Return(nil),
+		repo.EXPECT().InsertOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event outbox.Event) error {
+			require.Equal(t, outbox.WalletCreated, event.Type)
+			require.Equal(t, wl.ID, event.WalletID)
+			return nil
+		}),
+	)
+
+	require.NoError(t, NewStorageFacade(tm, repo).CreateWithProfile(context.Background(), wl))
+}
+
+func TestUpdateProfile_ReturnsUpdatedWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	name := "Savings"
+	upd := wallet.ProfileUpdate{DisplayName: &name}
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	gomock.InOrder(
+		repo.EXPECT().UpdateWalletProfile(gomock.Any(), id, upd).Return(nil),
+		repo.EXPECT().GetWallet(gomock.Any(), id).Return(wallet.Wallet{ID: id, DisplayName: name, Version: 2}, nil),
+	)
+
+	wl, err := NewStorageFacade(tm, repo).UpdateProfile(context.Background(), id, upd)
+	require.NoError(t, err)
+	require.Equal(t, name, wl.DisplayName)
+}
+
+func TestUpdateProfile_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	tm := mocks.NewMockTransactionManager(ctrl)
+	repo := mocks.NewMockWalletRepo(ctrl)
+	passThroughTx(tm)
+
+	repo.EXPECT().UpdateWalletProfile(gomock.Any(), id, gomock.Any()).Return(errAny("wallet not found"))
+
+	_, err := NewStorageFacade(tm, repo).UpdateProfile(context.Background(), id, wallet.ProfileUpdate{Metadata: []byte(`{}`)})
+	require.EqualError(t, err, "wallet not found")
+}
@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"project/internal/ledger"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ch chan ledger.Entry
+}
+
+// Broker fans ledger entries out to in-process subscribers of a wallet. A
+// subscriber that falls behind is dropped (its channel is closed) rather than
+// blocking the publisher; clients are expected to reconnect with
+// Last-Event-ID and replay the gap from the ledger.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[*subscriber]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[uuid.UUID]map[*subscriber]struct{})}
+}
+
+func (b *Broker) Subscribe(walletId uuid.UUID) (<-chan ledger.Entry, func()) {
+	sub := &subscriber{ch: make(chan ledger.Entry, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subs[walletId] == nil {
+		b.subs[walletId] = make(map[*subscriber]struct{})
+	}
+	b.subs[walletId][sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.ch, func() { b.remove(walletId, sub) }
+}
+
+func (b *Broker) Publish(entry ledger.Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[entry.WalletID] {
+		select {
+		case sub.ch <- entry:
+		default:
+			b.removeLocked(entry.WalletID, sub)
+		}
+	}
+}
+
+func (b *Broker) remove(walletId uuid.UUID, sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(walletId, sub)
+}
+
+func (b *Broker) removeLocked(walletId uuid.UUID, sub *subscriber) {
+	subs, ok := b.subs[walletId]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(b.subs, walletId)
+	}
+}
@@ -0,0 +1,55 @@
+package stream
+
+import (
+	"github.com/stretchr/testify/require"
+	"project/internal/ledger"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBroker_DeliversOnlyToWalletSubscribers(t *testing.T) {
+	b := NewBroker()
+	a, other := uuid.New(), uuid.New()
+
+	chA, cancelA := b.Subscribe(a)
+	defer cancelA()
+	chOther, cancelOther := b.Subscribe(other)
+	defer cancelOther()
+
+	b.Publish(ledger.Entry{ID: 1, WalletID: a})
+
+	require.Equal(t, int64(1), (<-chA).ID)
+	require.Empty(t, chOther)
+}
+
+func TestBroker_UnsubscribeClosesChannel(t *testing.T) {
+	b := NewBroker()
+	id := uuid.New()
+
+	ch, cancel := b.Subscribe(id)
+	cancel()
+	cancel()
+
+	_, ok := <-ch
+	require.False(t, ok)
+	require.Empty(t, b.subs)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	id := uuid.New()
+
+	ch, cancel := b.Subscribe(id)
+	defer cancel()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(ledger.Entry{ID: int64(i + 1), WalletID: id})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	require.Equal(t, subscriberBuffer, received)
+}
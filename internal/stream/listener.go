@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"project/internal/ledger"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const (
+	Channel        = "wallet_ledger"
+	reconnectDelay = time.Second
+)
+
+// Listen holds a dedicated connection that LISTENs on the ledger channel and
+// forwards every committed entry to the broker. Postgres only delivers
+// NOTIFY on commit, so every replica sees exactly the committed changes.
+func Listen(ctx context.Context, pool *pgxpool.Pool, broker *Broker) {
+	for {
+		if err := listen(ctx, pool, broker); err != nil && ctx.Err() == nil {
+			log.Printf("ledger listener: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, broker *Broker) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		conn.Exec(context.Background(), "UNLISTEN *")
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var entry ledger.Entry
+		if err := json.Unmarshal([]byte(notification.Payload), &entry); err != nil {
+			log.Printf("ledger listener: bad payload: %v", err)
+			continue
+		}
+		broker.Publish(entry)
+	}
+}
@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SortField string
+
+const (
+	SortCreatedAt SortField = "createdAt"
+	SortBalance   SortField = "balance"
+	SortWalletID  SortField = "walletId"
+)
+
+// Filter narrows a wallet listing; zero values match everything. Both
+// balance bounds and CreatedFrom are inclusive, CreatedBefore is exclusive.
+type Filter struct {
+	OwnerID       string
+	Status        Status
+	Currency      string
+	MinBalance    *int64
+	MaxBalance    *int64
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+}
+
+// Cursor is the position after the last wallet of a page. Only the field
+// matching the sort order is used besides ID.
+type Cursor struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	Balance   int64     `json:"balance,omitempty"`
+}
+
+type Query struct {
+	Filter Filter
+	Sort   SortField
+	Desc   bool
+	After  *Cursor
+	Limit  int
+}
+
+func CursorAfter(wl Wallet) Cursor {
+	return Cursor{ID: wl.ID, CreatedAt: wl.CreatedAt, Balance: wl.Balance}
+}
+
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.ID == uuid.Nil {
+		return Cursor{}, errors.New("invalid cursor parameter")
+	}
+	return c, nil
+}
@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	Active Status = "ACTIVE"
+	Frozen Status = "FROZEN"
+	Closed Status = "CLOSED"
+)
+
+const DefaultCurrency = "EUR"
+
+type Type string
+
+const (
+	Standard Type = "STANDARD"
+	// Savings wallets earn interest at their InterestRateBps a year.
+	Savings Type = "SAVINGS"
+)
+
+// Wallet is a wallet with its profile. ExternalRef is the owner's own
+// identifier for it and is unique per owner. InterestRateBps is the annual
+// rate of a Savings wallet, in basis points.
+type Wallet struct {
+	ID              uuid.UUID       `json:"walletId"`
+	OwnerID         string          `json:"ownerId,omitempty"`
+	DisplayName     string          `json:"displayName,omitempty"`
+	ExternalRef     string          `json:"externalRef,omitempty"`
+	Metadata        json.RawMessage `json:"metadata"`
+	Status          Status          `json:"status"`
+	Type            Type            `json:"type"`
+	InterestRateBps int64           `json:"interestRateBps,omitempty"`
+	Currency        string          `json:"currency"`
+	Balance         int64           `json:"balance"`
+	Version         int64           `json:"version"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// ProfileUpdate changes only the fields that are set. An empty string
+// clears DisplayName or ExternalRef; Metadata replaces the whole object.
+type ProfileUpdate struct {
+	DisplayName *string
+	ExternalRef *string
+	Metadata    json.RawMessage
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"project/internal/outbox"
+	"strconv"
+	"time"
+)
+
+// leaseSlack is added to the time a claimed batch may take to send.
+const leaseSlack = 30 * time.Second
+
+type Dispatcher struct {
+	tx          TxRunner
+	store       Store
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	lease       time.Duration
+	now         func() time.Time
+}
+
+// NewDispatcher sends at most batchSize deliveries per claim, one after the
+// other, so the claim's lease covers batchSize client timeouts. A client
+// without a timeout gets a lease of leaseSlack per delivery.
+func NewDispatcher(tx TxRunner, store Store, client *http.Client, interval time.Duration, batchSize, maxAttempts int) *Dispatcher {
+	perSend := client.Timeout
+	if perSend <= 0 {
+		perSend = leaseSlack
+	}
+	return &Dispatcher{
+		tx:          tx,
+		store:       store,
+		client:      client,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		lease:       time.Duration(batchSize)*perSend + leaseSlack,
+		now:         time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.ProcessBatch(ctx); err != nil {
+				log.Printf("webhook dispatcher: %v", err)
+			}
+		}
+	}
+}
+
+// ProcessBatch claims due deliveries in a short transaction, sends them
+// with no transaction open, and records each result in a transaction of its
+// own. A delivery whose result is never recorded is claimed again when its
+// lease runs out, so receivers may see it twice.
+func (d *Dispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	var deliveries []Delivery
+	err := d.tx.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+		var err error
+		deliveries, err = d.store.ClaimDueDeliveries(ctxTx, d.batchSize, d.lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		statusCode, sendErr := d.send(ctx, delivery)
+		err := d.tx.RunReadCommitted(ctx, func(ctxTx context.Context) error {
+			if sendErr == nil {
+				return d.store.MarkDeliverySucceeded(ctxTx, delivery.ID, statusCode)
+			}
+
+			var code *int
+			if statusCode != 0 {
+				code = &statusCode
+			}
+			attempts := delivery.Attempts + 1
+			status := StatusPending
+			if attempts >= d.maxAttempts {
+				status = StatusDead
+			}
+			next := d.now().Add(outbox.Backoff(attempts))
+			return d.store.MarkDeliveryFailed(ctxTx, delivery.ID, code, sendErr.Error(), next, status)
+		})
+		if err != nil {
+			return delivered, err
+		}
+		if sendErr == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"project/internal/outbox"
+)
+
+// Fanout is an outbox.EventPublisher that turns every relayed event into one
+// pending delivery per matching subscription.
+type Fanout struct {
+	store Store
+}
+
+func NewFanout(store Store) *Fanout {
+	return &Fanout{store: store}
+}
+
+func (f *Fanout) Publish(ctx context.Context, event outbox.Event) error {
+	subs, err := f.store.MatchingSubscriptions(ctx, string(event.Type), event.WalletID)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if err := f.store.InsertDelivery(ctx, sub.ID, event.ID, string(event.Type), body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderDeliveryID = "X-Webhook-Id"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+
+	signaturePrefix = "v1="
+)
+
+// Sign computes the HMAC-SHA256 of "<timestamp>.<body>" so that a captured
+// request cannot be replayed with a different timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age < 0 {
+		age = -age
+	}
+	if tolerance > 0 && age > tolerance {
+		return errors.New("webhook timestamp outside tolerance")
+	}
+
+	if !strings.HasPrefix(signatureHeader, signaturePrefix) {
+		return errors.New("invalid webhook signature")
+	}
+	if !hmac.Equal([]byte(signatureHeader), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
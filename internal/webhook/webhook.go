@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	StatusPending DeliveryStatus = "pending"
+	// StatusSending marks a delivery a dispatcher has claimed. The claim is a
+	// lease: a dispatcher that dies mid-send leaves it to be claimed again
+	// once the lease runs out.
+	StatusSending   DeliveryStatus = "sending"
+	StatusDelivered DeliveryStatus = "delivered"
+	StatusDead      DeliveryStatus = "dead"
+)
+
+type Subscription struct {
+	ID         uuid.UUID  `json:"id"`
+	URL        string     `json:"url"`
+	EventTypes []string   `json:"eventTypes"`
+	WalletID   *uuid.UUID `json:"walletId,omitempty"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type Delivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscriptionId"`
+	EventID        int64           `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Body           json.RawMessage `json:"-"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+type Store interface {
+	InsertSubscription(ctx context.Context, sub Subscription) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	MatchingSubscriptions(ctx context.Context, eventType string, walletId uuid.UUID) ([]Subscription, error)
+
+	InsertDelivery(ctx context.Context, subscriptionId uuid.UUID, eventId int64, eventType string, body []byte) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	MarkDeliverySucceeded(ctx context.Context, id int64, statusCode int) error
+	MarkDeliveryFailed(ctx context.Context, id int64, statusCode *int, cause string, nextAttemptAt time.Time, status DeliveryStatus) error
+	ListDeliveries(ctx context.Context, subscriptionId uuid.UUID, limit int) ([]Delivery, error)
+	RedeliverDelivery(ctx context.Context, subscriptionId uuid.UUID, id int64) error
+}
+
+type TxRunner interface {
+	RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error
+}
@@ -0,0 +1,288 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/outbox"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memStore struct {
+	mu         sync.Mutex
+	subs       []Subscription
+	deliveries []*Delivery
+	nextID     int64
+}
+
+func (m *memStore) InsertSubscription(ctx context.Context, sub Subscription) error {
+	m.subs = append(m.subs, sub)
+	return nil
+}
+
+func (m *memStore) GetSubscription(ctx context.Context, id uuid.UUID) (Subscription, error) {
+	for _, s := range m.subs {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return Subscription{}, errAny("subscription not found")
+}
+
+func (m *memStore) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return m.subs, nil
+}
+
+func (m *memStore) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *memStore) MatchingSubscriptions(ctx context.Context, eventType string, walletId uuid.UUID) ([]Subscription, error) {
+	var out []Subscription
+	for _, s := range m.subs {
+		if s.WalletID != nil && *s.WalletID != walletId {
+			continue
+		}
+		for _, t := range s.EventTypes {
+			if t == eventType {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) InsertDelivery(ctx context.Context, subscriptionId uuid.UUID, eventId int64, eventType string, body []byte) error {
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionId && d.EventID == eventId {
+			return nil
+		}
+	}
+	sub, _ := m.GetSubscription(ctx, subscriptionId)
+	m.nextID++
+	m.deliveries = append(m.deliveries, &Delivery{
+		ID:             m.nextID,
+		SubscriptionID: subscriptionId,
+		EventID:        eventId,
+		EventType:      eventType,
+		Body:           body,
+		Status:         StatusPending,
+		URL:            sub.URL,
+		Secret:         sub.Secret,
+	})
+	return nil
+}
+
+func (m *memStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	var out []Delivery
+	for _, d := range m.deliveries {
+		if d.Status == StatusPending && len(out) < limit {
+			d.Status = StatusSending
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) find(id int64) *Delivery {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (m *memStore) MarkDeliverySucceeded(ctx context.Context, id int64, statusCode int) error {
+	d := m.find(id)
+	d.Status = StatusDelivered
+	d.Attempts++
+	d.LastStatusCode = &statusCode
+	return nil
+}
+
+func (m *memStore) MarkDeliveryFailed(ctx context.Context, id int64, statusCode *int, cause string, nextAttemptAt time.Time, status DeliveryStatus) error {
+	d := m.find(id)
+	d.Status = status
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = &cause
+	d.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (m *memStore) ListDeliveries(ctx context.Context, subscriptionId uuid.UUID, limit int) ([]Delivery, error) {
+	return nil, nil
+}
+
+func (m *memStore) RedeliverDelivery(ctx context.Context, subscriptionId uuid.UUID, id int64) error {
+	d := m.find(id)
+	if d == nil || d.SubscriptionID != subscriptionId {
+		return errAny("delivery not found")
+	}
+	d.Status = StatusPending
+	d.Attempts = 0
+	return nil
+}
+
+type passTx struct{}
+
+func (passTx) RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	return fn(ctx)
+}
+
+// trackingTx counts transactions and whether one is open right now.
+type trackingTx struct {
+	open  atomic.Bool
+	count atomic.Int32
+}
+
+func (tx *trackingTx) RunReadCommitted(ctx context.Context, fn func(ctxTx context.Context) error) error {
+	tx.count.Add(1)
+	tx.open.Store(true)
+	defer tx.open.Store(false)
+	return fn(ctx)
+}
+
+type errAny string
+
+func (e errAny) Error() string { return string(e) }
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Unix(1_700_000_000, 0)
+	sig := Sign("s3cret", now.Unix(), body)
+
+	require.NoError(t, Verify("s3cret", "1700000000", sig, body, 5*time.Minute, now))
+	require.EqualError(t, Verify("other", "1700000000", sig, body, 5*time.Minute, now), "invalid webhook signature")
+	require.EqualError(t, Verify("s3cret", "1700000000", sig, []byte(`{"id":2}`), 5*time.Minute, now), "invalid webhook signature")
+	require.EqualError(t, Verify("s3cret", "1700000000", sig, body, 5*time.Minute, now.Add(time.Hour)), "webhook timestamp outside tolerance")
+	require.EqualError(t, Verify("s3cret", "nope", sig, body, 5*time.Minute, now), "invalid webhook timestamp")
+}
+
+func TestFanout_CreatesOneDeliveryPerMatchingSubscription(t *testing.T) {
+	walletId := uuid.New()
+	other := uuid.New()
+	store := &memStore{subs: []Subscription{
+		{ID: uuid.New(), EventTypes: []string{"WalletCredited"}},
+		{ID: uuid.New(), EventTypes: []string{"WalletCredited"}, WalletID: &walletId},
+		{ID: uuid.New(), EventTypes: []string{"WalletCredited"}, WalletID: &other},
+		{ID: uuid.New(), EventTypes: []string{"WalletDebited"}},
+	}}
+	fanout := NewFanout(store)
+	event := outbox.Event{ID: 10, Type: outbox.WalletCredited, WalletID: walletId, Payload: json.RawMessage(`{"amount":5}`)}
+
+	require.NoError(t, fanout.Publish(context.Background(), event))
+	require.NoError(t, fanout.Publish(context.Background(), event))
+
+	require.Len(t, store.deliveries, 2)
+	require.Equal(t, store.subs[0].ID, store.deliveries[0].SubscriptionID)
+	require.Equal(t, store.subs[1].ID, store.deliveries[1].SubscriptionID)
+}
+
+func TestDispatcher_DeliversSignedRequest(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   [][]byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sub := Subscription{ID: uuid.New(), URL: srv.URL, EventTypes: []string{"WalletDebited"}, Secret: "topsecret"}
+	store := &memStore{subs: []Subscription{sub}}
+	require.NoError(t, NewFanout(store).Publish(context.Background(), outbox.Event{ID: 3, Type: outbox.WalletDebited, WalletID: uuid.New()}))
+
+	d := NewDispatcher(passTx{}, store, srv.Client(), time.Second, 10, 3)
+
+	n, err := d.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	require.Len(t, received, 1)
+	req := received[0]
+	require.Equal(t, "WalletDebited", req.Header.Get(HeaderEvent))
+	require.Equal(t, "1", req.Header.Get(HeaderDeliveryID))
+	require.NoError(t, Verify("topsecret", req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderSignature), bodies[0], time.Minute, time.Now()))
+	require.Equal(t, StatusDelivered, store.deliveries[0].Status)
+}
+
+func TestDispatcher_DeadLettersAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	sub := Subscription{ID: uuid.New(), URL: srv.URL, EventTypes: []string{"WalletCreated"}, Secret: "x"}
+	store := &memStore{subs: []Subscription{sub}}
+	require.NoError(t, NewFanout(store).Publish(context.Background(), outbox.Event{ID: 1, Type: outbox.WalletCreated, WalletID: uuid.New()}))
+
+	d := NewDispatcher(passTx{}, store, srv.Client(), time.Second, 10, 3)
+
+	before := time.Now()
+	_, err := d.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	delivery := store.deliveries[0]
+	require.Equal(t, StatusPending, delivery.Status)
+	require.Equal(t, 500, *delivery.LastStatusCode)
+	require.WithinDuration(t, before.Add(outbox.Backoff(1)), delivery.NextAttemptAt, time.Second)
+
+	for i := 0; i < 5; i++ {
+		_, err := d.ProcessBatch(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, StatusDead, delivery.Status)
+	require.Equal(t, 3, delivery.Attempts)
+}
+
+func TestDispatcher_SendsOutsideTransactions(t *testing.T) {
+	tx := &trackingTx{}
+	var sentInTx []bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sentInTx = append(sentInTx, tx.open.Load())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sub := Subscription{ID: uuid.New(), URL: srv.URL, EventTypes: []string{"WalletCreated"}, Secret: "x"}
+	store := &memStore{subs: []Subscription{sub}}
+	for id := int64(1); id <= 2; id++ {
+		require.NoError(t, NewFanout(store).Publish(context.Background(), outbox.Event{ID: id, Type: outbox.WalletCreated, WalletID: uuid.New()}))
+	}
+
+	d := NewDispatcher(tx, store, srv.Client(), time.Second, 10, 3)
+
+	n, err := d.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []bool{false, false}, sentInTx)
+	// One claim, then one per recorded result.
+	require.Equal(t, int32(3), tx.count.Load())
+	for _, delivery := range store.deliveries {
+		require.Equal(t, StatusDelivered, delivery.Status)
+	}
+}
+
+func TestNewDispatcher_LeaseCoversBatch(t *testing.T) {
+	d := NewDispatcher(passTx{}, &memStore{}, &http.Client{Timeout: 2 * time.Second}, time.Second, 10, 3)
+	require.Equal(t, 20*time.Second+leaseSlack, d.lease)
+
+	d = NewDispatcher(passTx{}, &memStore{}, &http.Client{}, time.Second, 2, 3)
+	require.Equal(t, 3*leaseSlack, d.lease)
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE outbox (
+                       id BIGSERIAL PRIMARY KEY,
+                       event_type TEXT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       payload JSONB NOT NULL,
+                       attempts INT NOT NULL DEFAULT 0,
+                       last_error TEXT,
+                       next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       delivered_at TIMESTAMPTZ,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
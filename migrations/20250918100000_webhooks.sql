@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE webhook_subscriptions (
+                       id UUID PRIMARY KEY,
+                       url TEXT NOT NULL,
+                       event_types TEXT[] NOT NULL,
+                       wallet_id UUID,
+                       secret TEXT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+                       id BIGSERIAL PRIMARY KEY,
+                       subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+                       event_id BIGINT NOT NULL,
+                       event_type TEXT NOT NULL,
+                       body JSONB NOT NULL,
+                       status TEXT NOT NULL DEFAULT 'pending',
+                       attempts INT NOT NULL DEFAULT 0,
+                       last_status_code INT,
+                       last_error TEXT,
+                       next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       delivered_at TIMESTAMPTZ,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
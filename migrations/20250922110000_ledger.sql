@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE ledger_entries (
+                       id BIGSERIAL PRIMARY KEY,
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       balance_after BIGINT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ledger_entries_wallet_idx ON ledger_entries (wallet_id, id);
+
+-- +goose StatementBegin
+CREATE FUNCTION notify_ledger_entry() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_ledger', json_build_object(
+            'id', NEW.id,
+            'walletId', NEW.wallet_id,
+            'operationType', NEW.operation_type,
+            'amount', NEW.amount,
+            'balance', NEW.balance_after,
+            'createdAt', NEW.created_at
+        )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_entries_notify
+    AFTER INSERT ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION notify_ledger_entry();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS ledger_entries_notify ON ledger_entries;
+DROP FUNCTION IF EXISTS notify_ledger_entry();
+DROP TABLE IF EXISTS ledger_entries;
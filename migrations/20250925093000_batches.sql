@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE batches (
+                       id UUID PRIMARY KEY,
+                       mode TEXT NOT NULL,
+                       status TEXT NOT NULL,
+                       item_count INT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE batch_items (
+                       batch_id UUID NOT NULL REFERENCES batches (id) ON DELETE CASCADE,
+                       item_index INT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       status TEXT NOT NULL,
+                       error TEXT,
+                       ledger_entry_id BIGINT REFERENCES ledger_entries (id),
+                       PRIMARY KEY (batch_id, item_index)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batches;
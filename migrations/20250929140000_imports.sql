@@ -0,0 +1,35 @@
+-- +goose Up
+ALTER TABLE ledger_entries ADD COLUMN external_ref TEXT;
+
+CREATE UNIQUE INDEX ledger_entries_external_ref_idx ON ledger_entries (external_ref) WHERE external_ref IS NOT NULL;
+
+CREATE TABLE import_jobs (
+                       id UUID PRIMARY KEY,
+                       file_hash TEXT NOT NULL UNIQUE,
+                       status TEXT NOT NULL,
+                       total_rows INT NOT NULL,
+                       processed_rows INT NOT NULL DEFAULT 0,
+                       skipped_rows INT NOT NULL DEFAULT 0,
+                       error TEXT,
+                       content BYTEA NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX import_jobs_runnable_idx ON import_jobs (created_at) WHERE status IN ('PENDING', 'RUNNING');
+
+CREATE UNLOGGED TABLE import_staging (
+                       job_id UUID NOT NULL,
+                       row_no INT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       external_ref TEXT NOT NULL,
+                       PRIMARY KEY (job_id, row_no)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_staging;
+DROP TABLE IF EXISTS import_jobs;
+DROP INDEX IF EXISTS ledger_entries_external_ref_idx;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS external_ref;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN shard_count INT NOT NULL DEFAULT 0 CHECK (shard_count >= 0);
+
+CREATE TABLE wallet_shards (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       shard_no INT NOT NULL,
+                       balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+                       PRIMARY KEY (wallet_id, shard_no)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS wallet_shards;
+ALTER TABLE wallets DROP COLUMN IF EXISTS shard_count;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wallet_shards ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE wallet_shards DROP COLUMN IF EXISTS version;
+ALTER TABLE wallets DROP COLUMN IF EXISTS version;
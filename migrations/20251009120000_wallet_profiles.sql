@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE wallets
+    ADD COLUMN owner_id TEXT,
+    ADD COLUMN display_name TEXT,
+    ADD COLUMN external_ref TEXT,
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT wallets_external_ref_owner_check CHECK (external_ref IS NULL OR owner_id IS NOT NULL);
+
+CREATE INDEX wallets_owner_idx ON wallets (owner_id, wallet_id) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX wallets_owner_external_ref_key ON wallets (owner_id, external_ref) WHERE external_ref IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_owner_external_ref_key;
+DROP INDEX IF EXISTS wallets_owner_idx;
+ALTER TABLE wallets
+    DROP CONSTRAINT IF EXISTS wallets_external_ref_owner_check,
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS external_ref,
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE wallets
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+CREATE INDEX wallets_created_idx ON wallets (created_at, wallet_id);
+CREATE INDEX wallets_status_created_idx ON wallets (status, created_at, wallet_id);
+CREATE INDEX wallets_currency_created_idx ON wallets (currency, created_at, wallet_id);
+CREATE INDEX wallets_owner_created_idx ON wallets (owner_id, created_at, wallet_id) WHERE owner_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_owner_created_idx;
+DROP INDEX IF EXISTS wallets_currency_created_idx;
+DROP INDEX IF EXISTS wallets_status_created_idx;
+DROP INDEX IF EXISTS wallets_created_idx;
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS status;
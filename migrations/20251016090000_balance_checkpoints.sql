@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE INDEX ledger_entries_wallet_created_idx ON ledger_entries (wallet_id, created_at);
+
+CREATE TABLE balance_checkpoints (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       at TIMESTAMPTZ NOT NULL,
+                       balance BIGINT NOT NULL,
+                       PRIMARY KEY (wallet_id, at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS balance_checkpoints;
+DROP INDEX IF EXISTS ledger_entries_wallet_created_idx;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE daily_snapshots (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       day DATE NOT NULL,
+                       opening_balance BIGINT NOT NULL,
+                       total_credits BIGINT NOT NULL,
+                       total_debits BIGINT NOT NULL,
+                       closing_balance BIGINT NOT NULL,
+                       entry_count INT NOT NULL,
+                       PRIMARY KEY (wallet_id, day)
+);
+
+CREATE INDEX daily_snapshots_day_idx ON daily_snapshots (day);
+
+-- +goose Down
+DROP TABLE IF EXISTS daily_snapshots;
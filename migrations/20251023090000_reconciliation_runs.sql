@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE reconciliation_runs (
+                       id BIGSERIAL PRIMARY KEY,
+                       status TEXT NOT NULL,
+                       started_at TIMESTAMPTZ NOT NULL,
+                       finished_at TIMESTAMPTZ NOT NULL,
+                       wallets_checked INT NOT NULL DEFAULT 0,
+                       total_credits BIGINT NOT NULL DEFAULT 0,
+                       total_debits BIGINT NOT NULL DEFAULT 0,
+                       total_balance BIGINT NOT NULL DEFAULT 0,
+                       discrepancy_count INT NOT NULL DEFAULT 0,
+                       discrepancies JSONB NOT NULL DEFAULT '[]',
+                       error TEXT
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS reconciliation_runs;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE bank_statements (
+                       id UUID PRIMARY KEY,
+                       format TEXT NOT NULL,
+                       file_hash TEXT NOT NULL UNIQUE,
+                       line_count INT NOT NULL,
+                       imported_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE bank_statement_lines (
+                       id BIGSERIAL PRIMARY KEY,
+                       statement_id UUID NOT NULL REFERENCES bank_statements (id),
+                       entry_ref TEXT NOT NULL UNIQUE,
+                       account TEXT NOT NULL,
+                       booking_date DATE NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       currency CHAR(3) NOT NULL,
+                       credit BOOLEAN NOT NULL,
+                       reference TEXT NOT NULL DEFAULT '',
+                       counterparty TEXT NOT NULL DEFAULT '',
+                       status TEXT NOT NULL CHECK (status IN ('MATCHED', 'EXCEPTION', 'RESOLVED', 'IGNORED')),
+                       wallet_id UUID REFERENCES wallets (wallet_id),
+                       reason TEXT NOT NULL DEFAULT '',
+                       note TEXT NOT NULL DEFAULT '',
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX bank_statement_lines_exceptions_idx ON bank_statement_lines (id) WHERE status = 'EXCEPTION';
+
+-- +goose Down
+DROP TABLE IF EXISTS bank_statement_lines;
+DROP TABLE IF EXISTS bank_statements;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE payout_files (
+                       id UUID PRIMARY KEY,
+                       message_id TEXT NOT NULL UNIQUE,
+                       payout_count INT NOT NULL,
+                       control_sum BIGINT NOT NULL,
+                       content BYTEA NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE payouts (
+                       id UUID PRIMARY KEY,
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       currency CHAR(3) NOT NULL,
+                       beneficiary_name TEXT NOT NULL,
+                       beneficiary_iban TEXT NOT NULL,
+                       beneficiary_bic TEXT NOT NULL DEFAULT '',
+                       reference TEXT NOT NULL DEFAULT '',
+                       end_to_end_id TEXT NOT NULL UNIQUE,
+                       status TEXT NOT NULL CHECK (status IN ('PENDING', 'EXPORTED', 'CONFIRMED', 'REJECTED')),
+                       file_id UUID REFERENCES payout_files (id),
+                       reason TEXT NOT NULL DEFAULT '',
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX payouts_pending_idx ON payouts (created_at, id) WHERE status = 'PENDING';
+CREATE INDEX payouts_file_idx ON payouts (file_id);
+CREATE INDEX payouts_wallet_idx ON payouts (wallet_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS payout_files;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE ledger_entries ADD COLUMN reversal_of BIGINT REFERENCES ledger_entries (id);
+
+CREATE INDEX ledger_entries_reversal_idx ON ledger_entries (reversal_of) WHERE reversal_of IS NOT NULL;
+
+ALTER TABLE wallets
+    ADD COLUMN min_balance BIGINT NOT NULL DEFAULT 0 CHECK (min_balance <= 0),
+    DROP CONSTRAINT wallets_balance_check,
+    ADD CONSTRAINT wallets_balance_check CHECK (balance >= min_balance);
+
+-- +goose Down
+ALTER TABLE wallets
+    DROP CONSTRAINT IF EXISTS wallets_balance_check,
+    DROP COLUMN IF EXISTS min_balance,
+    ADD CONSTRAINT wallets_balance_check CHECK (balance >= 0);
+
+DROP INDEX IF EXISTS ledger_entries_reversal_idx;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS reversal_of;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE payouts ADD COLUMN fee BIGINT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+-- +goose Down
+ALTER TABLE payouts DROP COLUMN IF EXISTS fee;
@@ -0,0 +1,47 @@
+-- +goose Up
+ALTER TABLE wallets
+    ADD COLUMN wallet_type TEXT NOT NULL DEFAULT 'STANDARD' CHECK (wallet_type IN ('STANDARD', 'SAVINGS')),
+    ADD COLUMN interest_rate_bps INT NOT NULL DEFAULT 0 CHECK (interest_rate_bps BETWEEN 0 AND 10000),
+    ADD CONSTRAINT wallets_interest_rate_check CHECK (wallet_type = 'SAVINGS' OR interest_rate_bps = 0);
+
+CREATE INDEX wallets_savings_idx ON wallets (wallet_id) WHERE wallet_type = 'SAVINGS';
+
+-- remainder is the fraction of a minor unit not yet accrued, in units of
+-- 1/3650000, carried into the wallet's next day.
+CREATE TABLE interest_accruals (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       day DATE NOT NULL,
+                       balance BIGINT NOT NULL,
+                       rate_bps INT NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount >= 0),
+                       remainder BIGINT NOT NULL CHECK (remainder >= 0),
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (wallet_id, day)
+);
+
+CREATE TABLE interest_postings (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       month DATE NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       ledger_entry_id BIGINT NOT NULL REFERENCES ledger_entries (id),
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (wallet_id, month)
+);
+
+CREATE TABLE interest_runs (
+                       job TEXT NOT NULL CHECK (job IN ('ACCRUAL', 'POSTING')),
+                       period DATE NOT NULL,
+                       wallets INT NOT NULL,
+                       completed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (job, period)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS interest_runs;
+DROP TABLE IF EXISTS interest_postings;
+DROP TABLE IF EXISTS interest_accruals;
+DROP INDEX IF EXISTS wallets_savings_idx;
+ALTER TABLE wallets
+    DROP CONSTRAINT IF EXISTS wallets_interest_rate_check,
+    DROP COLUMN IF EXISTS interest_rate_bps,
+    DROP COLUMN IF EXISTS wallet_type;
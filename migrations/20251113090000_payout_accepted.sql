@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE payouts DROP CONSTRAINT payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+    CHECK (status IN ('PENDING', 'EXPORTED', 'ACCEPTED', 'CONFIRMED', 'REJECTED'));
+
+-- +goose Down
+ALTER TABLE payouts DROP CONSTRAINT payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+    CHECK (status IN ('PENDING', 'EXPORTED', 'CONFIRMED', 'REJECTED'));
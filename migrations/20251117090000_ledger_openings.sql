@@ -0,0 +1,43 @@
+-- +goose Up
+-- Balances that predate the ledger get one OPENING entry per wallet, so the
+-- entries of every wallet sum to its balance again. Each is dated at the
+-- wallet's creation, before anything else the ledger holds for it, and
+-- checkpoints and daily snapshots taken without it are shifted to match.
+WITH openings AS (
+    SELECT w.wallet_id,
+           w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_shards s WHERE s.wallet_id = w.wallet_id), 0)
+               - COALESCE((SELECT SUM(l.amount) FROM ledger_entries l WHERE l.wallet_id = w.wallet_id), 0) AS amount,
+           LEAST(w.created_at, (SELECT MIN(l.created_at) FROM ledger_entries l WHERE l.wallet_id = w.wallet_id)
+               - interval '1 microsecond') AS at
+    FROM wallets w
+),
+missing AS (
+    SELECT * FROM openings WHERE amount <> 0
+),
+inserted AS (
+    INSERT INTO ledger_entries (wallet_id, operation_type, amount, balance_after, created_at)
+    SELECT wallet_id, 'OPENING', amount, amount, at FROM missing ORDER BY wallet_id
+),
+snapshots AS (
+    UPDATE daily_snapshots d
+    SET opening_balance = d.opening_balance + m.amount, closing_balance = d.closing_balance + m.amount
+    FROM missing m
+    WHERE d.wallet_id = m.wallet_id
+)
+UPDATE balance_checkpoints c
+SET balance = c.balance + m.amount
+FROM missing m
+WHERE c.wallet_id = m.wallet_id;
+
+-- +goose Down
+UPDATE daily_snapshots d
+SET opening_balance = d.opening_balance - l.amount, closing_balance = d.closing_balance - l.amount
+FROM ledger_entries l
+WHERE l.operation_type = 'OPENING' AND d.wallet_id = l.wallet_id;
+
+UPDATE balance_checkpoints c
+SET balance = c.balance - l.amount
+FROM ledger_entries l
+WHERE l.operation_type = 'OPENING' AND c.wallet_id = l.wallet_id;
+
+DELETE FROM ledger_entries WHERE operation_type = 'OPENING';
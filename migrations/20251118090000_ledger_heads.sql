@@ -0,0 +1,14 @@
+-- +goose Up
+-- One row per wallet, updated by every ledger write just before its insert.
+-- The row lock orders a wallet's entries by commit. There is no foreign key,
+-- so creating the row never waits on a locked wallets row.
+CREATE TABLE ledger_heads (
+                       wallet_id UUID PRIMARY KEY,
+                       entries BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO ledger_heads (wallet_id, entries)
+SELECT wallet_id, COUNT(*) FROM ledger_entries GROUP BY wallet_id;
+
+-- +goose Down
+DROP TABLE ledger_heads;
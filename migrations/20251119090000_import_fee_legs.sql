@@ -0,0 +1,14 @@
+-- +goose Up
+-- A row with a fee is staged as several legs; only leg 0 carries the row's
+-- external_ref.
+ALTER TABLE import_staging ADD COLUMN leg SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE import_staging ALTER COLUMN external_ref DROP NOT NULL;
+ALTER TABLE import_staging DROP CONSTRAINT import_staging_pkey;
+ALTER TABLE import_staging ADD PRIMARY KEY (job_id, row_no, leg);
+
+-- +goose Down
+DELETE FROM import_staging WHERE leg <> 0;
+ALTER TABLE import_staging DROP CONSTRAINT import_staging_pkey;
+ALTER TABLE import_staging ADD PRIMARY KEY (job_id, row_no);
+ALTER TABLE import_staging ALTER COLUMN external_ref SET NOT NULL;
+ALTER TABLE import_staging DROP COLUMN leg;
@@ -0,0 +1,55 @@
+-- +goose Up
+-- Entries written under the wallets row lock are in lane -1, sharded deposits
+-- in the lane of their shard. Entry ids follow commit order within a lane,
+-- which is all SSE resume needs, so ledger_heads no longer serializes every
+-- write to a wallet.
+ALTER TABLE ledger_entries ADD COLUMN shard_no INT NOT NULL DEFAULT -1;
+
+CREATE INDEX ledger_entries_wallet_lane_idx ON ledger_entries (wallet_id, shard_no, id);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ledger_entry() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_ledger', json_build_object(
+            'id', NEW.id,
+            'walletId', NEW.wallet_id,
+            'operationType', NEW.operation_type,
+            'amount', NEW.amount,
+            'balance', NEW.balance_after,
+            'shard', NULLIF(NEW.shard_no, -1),
+            'createdAt', NEW.created_at
+        )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TABLE ledger_heads;
+
+-- +goose Down
+CREATE TABLE ledger_heads (
+                       wallet_id UUID PRIMARY KEY,
+                       entries BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO ledger_heads (wallet_id, entries)
+SELECT wallet_id, COUNT(*) FROM ledger_entries GROUP BY wallet_id;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_ledger_entry() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_ledger', json_build_object(
+            'id', NEW.id,
+            'walletId', NEW.wallet_id,
+            'operationType', NEW.operation_type,
+            'amount', NEW.amount,
+            'balance', NEW.balance_after,
+            'createdAt', NEW.created_at
+        )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS ledger_entries_wallet_lane_idx;
+ALTER TABLE ledger_entries DROP COLUMN shard_no;
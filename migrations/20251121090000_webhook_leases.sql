@@ -0,0 +1,11 @@
+-- +goose Up
+-- A dispatcher claims deliveries by moving them to 'sending' until
+-- locked_until, then sends with no transaction open.
+ALTER TABLE webhook_deliveries ADD COLUMN locked_until TIMESTAMPTZ;
+
+CREATE INDEX webhook_deliveries_leased_idx ON webhook_deliveries (locked_until, id) WHERE status = 'sending';
+
+-- +goose Down
+UPDATE webhook_deliveries SET status = 'pending' WHERE status = 'sending';
+DROP INDEX IF EXISTS webhook_deliveries_leased_idx;
+ALTER TABLE webhook_deliveries DROP COLUMN locked_until;
@@ -3,3 +3,335 @@ CREATE TABLE wallets (
                        balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
                        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
+
+CREATE TABLE outbox (
+                       id BIGSERIAL PRIMARY KEY,
+                       event_type TEXT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       payload JSONB NOT NULL,
+                       attempts INT NOT NULL DEFAULT 0,
+                       last_error TEXT,
+                       next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       delivered_at TIMESTAMPTZ,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+
+CREATE TABLE webhook_subscriptions (
+                       id UUID PRIMARY KEY,
+                       url TEXT NOT NULL,
+                       event_types TEXT[] NOT NULL,
+                       wallet_id UUID,
+                       secret TEXT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+                       id BIGSERIAL PRIMARY KEY,
+                       subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+                       event_id BIGINT NOT NULL,
+                       event_type TEXT NOT NULL,
+                       body JSONB NOT NULL,
+                       status TEXT NOT NULL DEFAULT 'pending',
+                       attempts INT NOT NULL DEFAULT 0,
+                       last_status_code INT,
+                       last_error TEXT,
+                       next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       delivered_at TIMESTAMPTZ,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+
+CREATE TABLE ledger_entries (
+                       id BIGSERIAL PRIMARY KEY,
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       balance_after BIGINT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ledger_entries_wallet_idx ON ledger_entries (wallet_id, id);
+
+CREATE FUNCTION notify_ledger_entry() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_ledger', json_build_object(
+            'id', NEW.id,
+            'walletId', NEW.wallet_id,
+            'operationType', NEW.operation_type,
+            'amount', NEW.amount,
+            'balance', NEW.balance_after,
+            'createdAt', NEW.created_at
+        )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_notify
+    AFTER INSERT ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION notify_ledger_entry();
+
+CREATE TABLE batches (
+                       id UUID PRIMARY KEY,
+                       mode TEXT NOT NULL,
+                       status TEXT NOT NULL,
+                       item_count INT NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE batch_items (
+                       batch_id UUID NOT NULL REFERENCES batches (id) ON DELETE CASCADE,
+                       item_index INT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       status TEXT NOT NULL,
+                       error TEXT,
+                       ledger_entry_id BIGINT REFERENCES ledger_entries (id),
+                       PRIMARY KEY (batch_id, item_index)
+);
+
+ALTER TABLE ledger_entries ADD COLUMN external_ref TEXT;
+
+CREATE UNIQUE INDEX ledger_entries_external_ref_idx ON ledger_entries (external_ref) WHERE external_ref IS NOT NULL;
+
+CREATE TABLE import_jobs (
+                       id UUID PRIMARY KEY,
+                       file_hash TEXT NOT NULL UNIQUE,
+                       status TEXT NOT NULL,
+                       total_rows INT NOT NULL,
+                       processed_rows INT NOT NULL DEFAULT 0,
+                       skipped_rows INT NOT NULL DEFAULT 0,
+                       error TEXT,
+                       content BYTEA NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX import_jobs_runnable_idx ON import_jobs (created_at) WHERE status IN ('PENDING', 'RUNNING');
+
+CREATE UNLOGGED TABLE import_staging (
+                       job_id UUID NOT NULL,
+                       row_no INT NOT NULL,
+                       wallet_id UUID NOT NULL,
+                       operation_type TEXT NOT NULL,
+                       amount BIGINT NOT NULL,
+                       external_ref TEXT NOT NULL,
+                       PRIMARY KEY (job_id, row_no)
+);
+
+ALTER TABLE wallets ADD COLUMN shard_count INT NOT NULL DEFAULT 0 CHECK (shard_count >= 0);
+
+CREATE TABLE wallet_shards (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       shard_no INT NOT NULL,
+                       balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+                       PRIMARY KEY (wallet_id, shard_no)
+);
+
+ALTER TABLE wallets ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wallet_shards ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE wallets
+    ADD COLUMN owner_id TEXT,
+    ADD COLUMN display_name TEXT,
+    ADD COLUMN external_ref TEXT,
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT wallets_external_ref_owner_check CHECK (external_ref IS NULL OR owner_id IS NOT NULL);
+
+CREATE INDEX wallets_owner_idx ON wallets (owner_id, wallet_id) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX wallets_owner_external_ref_key ON wallets (owner_id, external_ref) WHERE external_ref IS NOT NULL;
+
+ALTER TABLE wallets
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+CREATE INDEX wallets_created_idx ON wallets (created_at, wallet_id);
+CREATE INDEX wallets_status_created_idx ON wallets (status, created_at, wallet_id);
+CREATE INDEX wallets_currency_created_idx ON wallets (currency, created_at, wallet_id);
+CREATE INDEX wallets_owner_created_idx ON wallets (owner_id, created_at, wallet_id) WHERE owner_id IS NOT NULL;
+
+CREATE INDEX ledger_entries_wallet_created_idx ON ledger_entries (wallet_id, created_at);
+
+CREATE TABLE balance_checkpoints (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       at TIMESTAMPTZ NOT NULL,
+                       balance BIGINT NOT NULL,
+                       PRIMARY KEY (wallet_id, at)
+);
+
+CREATE TABLE daily_snapshots (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       day DATE NOT NULL,
+                       opening_balance BIGINT NOT NULL,
+                       total_credits BIGINT NOT NULL,
+                       total_debits BIGINT NOT NULL,
+                       closing_balance BIGINT NOT NULL,
+                       entry_count INT NOT NULL,
+                       PRIMARY KEY (wallet_id, day)
+);
+
+CREATE INDEX daily_snapshots_day_idx ON daily_snapshots (day);
+
+CREATE TABLE reconciliation_runs (
+                       id BIGSERIAL PRIMARY KEY,
+                       status TEXT NOT NULL,
+                       started_at TIMESTAMPTZ NOT NULL,
+                       finished_at TIMESTAMPTZ NOT NULL,
+                       wallets_checked INT NOT NULL DEFAULT 0,
+                       total_credits BIGINT NOT NULL DEFAULT 0,
+                       total_debits BIGINT NOT NULL DEFAULT 0,
+                       total_balance BIGINT NOT NULL DEFAULT 0,
+                       discrepancy_count INT NOT NULL DEFAULT 0,
+                       discrepancies JSONB NOT NULL DEFAULT '[]',
+                       error TEXT
+);
+
+CREATE TABLE bank_statements (
+                       id UUID PRIMARY KEY,
+                       format TEXT NOT NULL,
+                       file_hash TEXT NOT NULL UNIQUE,
+                       line_count INT NOT NULL,
+                       imported_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE bank_statement_lines (
+                       id BIGSERIAL PRIMARY KEY,
+                       statement_id UUID NOT NULL REFERENCES bank_statements (id),
+                       entry_ref TEXT NOT NULL UNIQUE,
+                       account TEXT NOT NULL,
+                       booking_date DATE NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       currency CHAR(3) NOT NULL,
+                       credit BOOLEAN NOT NULL,
+                       reference TEXT NOT NULL DEFAULT '',
+                       counterparty TEXT NOT NULL DEFAULT '',
+                       status TEXT NOT NULL CHECK (status IN ('MATCHED', 'EXCEPTION', 'RESOLVED', 'IGNORED')),
+                       wallet_id UUID REFERENCES wallets (wallet_id),
+                       reason TEXT NOT NULL DEFAULT '',
+                       note TEXT NOT NULL DEFAULT '',
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX bank_statement_lines_exceptions_idx ON bank_statement_lines (id) WHERE status = 'EXCEPTION';
+
+CREATE TABLE payout_files (
+                       id UUID PRIMARY KEY,
+                       message_id TEXT NOT NULL UNIQUE,
+                       payout_count INT NOT NULL,
+                       control_sum BIGINT NOT NULL,
+                       content BYTEA NOT NULL,
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE payouts (
+                       id UUID PRIMARY KEY,
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       currency CHAR(3) NOT NULL,
+                       beneficiary_name TEXT NOT NULL,
+                       beneficiary_iban TEXT NOT NULL,
+                       beneficiary_bic TEXT NOT NULL DEFAULT '',
+                       reference TEXT NOT NULL DEFAULT '',
+                       end_to_end_id TEXT NOT NULL UNIQUE,
+                       status TEXT NOT NULL CHECK (status IN ('PENDING', 'EXPORTED', 'CONFIRMED', 'REJECTED')),
+                       file_id UUID REFERENCES payout_files (id),
+                       reason TEXT NOT NULL DEFAULT '',
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX payouts_pending_idx ON payouts (created_at, id) WHERE status = 'PENDING';
+CREATE INDEX payouts_file_idx ON payouts (file_id);
+CREATE INDEX payouts_wallet_idx ON payouts (wallet_id, created_at);
+
+ALTER TABLE ledger_entries ADD COLUMN reversal_of BIGINT REFERENCES ledger_entries (id);
+
+CREATE INDEX ledger_entries_reversal_idx ON ledger_entries (reversal_of) WHERE reversal_of IS NOT NULL;
+
+ALTER TABLE wallets
+    ADD COLUMN min_balance BIGINT NOT NULL DEFAULT 0 CHECK (min_balance <= 0),
+    DROP CONSTRAINT wallets_balance_check,
+    ADD CONSTRAINT wallets_balance_check CHECK (balance >= min_balance);
+
+ALTER TABLE payouts ADD COLUMN fee BIGINT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+ALTER TABLE wallets
+    ADD COLUMN wallet_type TEXT NOT NULL DEFAULT 'STANDARD' CHECK (wallet_type IN ('STANDARD', 'SAVINGS')),
+    ADD COLUMN interest_rate_bps INT NOT NULL DEFAULT 0 CHECK (interest_rate_bps BETWEEN 0 AND 10000),
+    ADD CONSTRAINT wallets_interest_rate_check CHECK (wallet_type = 'SAVINGS' OR interest_rate_bps = 0);
+
+CREATE INDEX wallets_savings_idx ON wallets (wallet_id) WHERE wallet_type = 'SAVINGS';
+
+-- remainder is the fraction of a minor unit not yet accrued, in units of
+-- 1/3650000, carried into the wallet's next day.
+CREATE TABLE interest_accruals (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       day DATE NOT NULL,
+                       balance BIGINT NOT NULL,
+                       rate_bps INT NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount >= 0),
+                       remainder BIGINT NOT NULL CHECK (remainder >= 0),
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (wallet_id, day)
+);
+
+CREATE TABLE interest_postings (
+                       wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+                       month DATE NOT NULL,
+                       amount BIGINT NOT NULL CHECK (amount > 0),
+                       ledger_entry_id BIGINT NOT NULL REFERENCES ledger_entries (id),
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (wallet_id, month)
+);
+
+CREATE TABLE interest_runs (
+                       job TEXT NOT NULL CHECK (job IN ('ACCRUAL', 'POSTING')),
+                       period DATE NOT NULL,
+                       wallets INT NOT NULL,
+                       completed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                       PRIMARY KEY (job, period)
+);
+
+ALTER TABLE payouts DROP CONSTRAINT payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+    CHECK (status IN ('PENDING', 'EXPORTED', 'ACCEPTED', 'CONFIRMED', 'REJECTED'));
+
+CREATE TABLE ledger_heads (
+                       wallet_id UUID PRIMARY KEY,
+                       entries BIGINT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE import_staging ADD COLUMN leg SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE import_staging ALTER COLUMN external_ref DROP NOT NULL;
+ALTER TABLE import_staging DROP CONSTRAINT import_staging_pkey;
+ALTER TABLE import_staging ADD PRIMARY KEY (job_id, row_no, leg);
+
+ALTER TABLE ledger_entries ADD COLUMN shard_no INT NOT NULL DEFAULT -1;
+
+CREATE INDEX ledger_entries_wallet_lane_idx ON ledger_entries (wallet_id, shard_no, id);
+
+CREATE OR REPLACE FUNCTION notify_ledger_entry() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_ledger', json_build_object(
+            'id', NEW.id,
+            'walletId', NEW.wallet_id,
+            'operationType', NEW.operation_type,
+            'amount', NEW.amount,
+            'balance', NEW.balance_after,
+            'shard', NULLIF(NEW.shard_no, -1),
+            'createdAt', NEW.created_at
+        )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE ledger_heads;
+
+ALTER TABLE webhook_deliveries ADD COLUMN locked_until TIMESTAMPTZ;
+
+CREATE INDEX webhook_deliveries_leased_idx ON webhook_deliveries (locked_until, id) WHERE status = 'sending';